@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/config"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/databases/postgres"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/workerpool"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+var (
+	entity    = flag.String("entity", "flats", "entity to import: houses or flats")
+	file      = flag.String("file", "", "path to CSV or JSON file")
+	format    = flag.String("format", "", "file format: csv or json, detected from file extension if omitted")
+	mode      = flag.String("mode", string(domain.ImportModeTransaction), "import mode: transaction or chunks")
+	chunkSize = flag.Int("chunk-size", dtos.DefaultImportChunkSize, "rows per transaction in chunks mode")
+	dryRun    = flag.Bool("dry-run", false, "validate file without saving anything")
+)
+
+// Main imports houses or flats from file directly into database and prints job report.
+// Config is loaded the same way as for the app, use -c flag to specify its path.
+func main() {
+	cfg := config.MustLoad()
+
+	log := logger.NewLogger("info")
+
+	inp := dtos.ImportInput{
+		Entity:    domain.ImportEntity(*entity),
+		Format:    domain.ImportFormat(*format),
+		Mode:      domain.ImportMode(*mode),
+		ChunkSize: *chunkSize,
+		DryRun:    *dryRun,
+	}
+
+	if inp.Format == "" {
+		inp.Format = domain.ImportFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), "."))
+	}
+
+	inp = inp.WithDefaults()
+	if err := inp.Validate(); err != nil {
+		fatal(err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		fatal(err)
+	}
+	defer f.Close()
+
+	pool, err := postgres.NewClient(cfg.Postgres.DSN())
+	if err != nil {
+		fatal(fmt.Errorf("failed to connect to postgres: %w", err))
+	}
+	defer pool.Close()
+
+	repo := repository.New(pool)
+	// Import is run synchronously, so background jobs pool stays idle.
+	imports := service.NewImportsService(repo.Flats, repo.Houses, workerpool.New(1, 0), cfg.Workers.ImportJobTTL, log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	job, err := imports.Run(ctx, inp, f)
+	if err != nil {
+		fatal(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(job); err != nil {
+		fatal(err)
+	}
+
+	if job.Status != domain.ImportStatusCompleted || job.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "import: "+err.Error())
+	os.Exit(1)
+}
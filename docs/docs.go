@@ -142,7 +142,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/v1.userIdResponse"
+                            "$ref": "#/definitions/v1.UserIdResponse"
                         }
                     },
                     "400": {
@@ -166,8 +166,68 @@ const docTemplate = `{
                 }
             }
         },
-        "/flat/create": {
-            "post": {
+        "/flat/:id": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get flat with summary of its house. Clients see approved flats and ones they created,\nother flats are reported not found. Moderators see flats of any status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "flat"
+                ],
+                "summary": "Get Flat By Id",
+                "operationId": "getFlatById",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "flat id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_FlatDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "patch": {
                 "security": [
                     {
                         "ClientsAuth": []
@@ -176,7 +236,7 @@ const docTemplate = `{
                         "ModeratorsAuth": []
                     }
                 ],
-                "description": "create flat with flatNumber, price, rooms and house id it belongs to",
+                "description": "edit flat attributes, omitted ones are left as is. Flat may be edited by its creator or moderators.\nEdited flat is sent back to moderation, while its previously approved version stays visible\nto clients until the edit is approved. Flat claimed by moderator can't be edited by others.",
                 "consumes": [
                     "application/json"
                 ],
@@ -186,24 +246,31 @@ const docTemplate = `{
                 "tags": [
                     "flat"
                 ],
-                "summary": "Create flat",
-                "operationId": "createFlat",
+                "summary": "Edit Flat",
+                "operationId": "editFlat",
                 "parameters": [
                     {
-                        "description": "Flat info",
+                        "type": "string",
+                        "description": "flat id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Flat attributes",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.FlatCreateInput"
+                            "$ref": "#/definitions/dtos.FlatEditInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.dataResponse-domain_Flat"
+                            "$ref": "#/definitions/v1.DataResponse-domain_Flat"
                         }
                     },
                     "400": {
@@ -212,6 +279,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {
@@ -227,41 +312,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/flat/update": {
-            "post": {
+        "/flat/:id/history": {
+            "get": {
                 "security": [
                     {
                         "ModeratorsAuth": []
                     }
                 ],
-                "description": "update flat status",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "get status changes of flat, oldest first, with users made them and decline reasons",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "flat"
                 ],
-                "summary": "Update flat",
-                "operationId": "updateFlat",
+                "summary": "Get Flat History",
+                "operationId": "getFlatHistory",
                 "parameters": [
                     {
-                        "description": "Flat info",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.FlatUpdateInput"
-                        }
+                        "type": "string",
+                        "description": "flat id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.dataResponse-domain_Flat"
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_FlatStatusChange"
                         }
                     },
                     "400": {
@@ -270,6 +350,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -285,32 +371,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/house/:id": {
+        "/flat/:id/transitions": {
             "get": {
                 "security": [
-                    {
-                        "ClientsAuth": []
-                    },
                     {
                         "ModeratorsAuth": []
                     }
                 ],
-                "description": "get all flats that are located at house",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "get statuses flat may move to from its current status",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "house"
+                    "flat"
                 ],
-                "summary": "Get House By Id",
-                "operationId": "getHouseById",
+                "summary": "Get Flat Transitions",
+                "operationId": "getFlatTransitions",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "house id",
+                        "description": "flat id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -320,7 +400,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.dataResponse-array_domain_Flat"
+                            "$ref": "#/definitions/v1.DataResponse-domain_FlatTransitions"
                         }
                     },
                     "400": {
@@ -329,6 +409,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -344,7 +430,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/house/:id/subscribe": {
+        "/flat/create": {
             "post": {
                 "security": [
                     {
@@ -354,7 +440,7 @@ const docTemplate = `{
                         "ModeratorsAuth": []
                     }
                 ],
-                "description": "subscribe user to house specifying his email in body",
+                "description": "create flat with flatNumber, price, rooms and house id it belongs to,\ncreator sees the flat before it's approved",
                 "consumes": [
                     "application/json"
                 ],
@@ -362,33 +448,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "house"
+                    "flat"
                 ],
-                "summary": "Post Subscribe To House With Id",
-                "operationId": "postSubscribeToHouse",
+                "summary": "Create flat",
+                "operationId": "createFlat",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "house id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "User email to subscribe",
+                        "description": "Flat info",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.HouseSubscribeInput"
+                            "$ref": "#/definitions/dtos.FlatCreateInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "ok",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/v1.DataResponse-domain_Flat"
                         }
                     },
                     "400": {
@@ -397,8 +476,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/v1.response"
                         }
@@ -418,14 +497,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/house/create": {
+        "/flat/update": {
             "post": {
                 "security": [
                     {
                         "ModeratorsAuth": []
                     }
                 ],
-                "description": "create house with address, year and (perhaps) developer",
+                "description": "update flat status, moderator must claim the flat first. Created flat may be approved or declined,\nother transitions are rejected, see /flat/:id/transitions. Declining requires reason code\nfrom /moderation/decline-reasons and comment, both are shown to the flat creator.",
                 "consumes": [
                     "application/json"
                 ],
@@ -433,26 +512,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "house"
+                    "flat"
                 ],
-                "summary": "Create House",
-                "operationId": "createHouse",
+                "summary": "Update flat",
+                "operationId": "updateFlat",
                 "parameters": [
                     {
-                        "description": "House info",
+                        "description": "Flat info",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.HouseCreateInput"
+                            "$ref": "#/definitions/dtos.FlatUpdateInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/v1.dataResponse-domain_House"
+                            "$ref": "#/definitions/v1.DataResponse-domain_Flat"
                         }
                     },
                     "400": {
@@ -461,6 +540,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/v1.response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {
@@ -475,50 +566,2719 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Flat": {
-            "type": "object",
-            "properties": {
-                "flatNumber": {
-                    "description": "TODO Есть условие \"номер квартиры\", но его почему-то нет в API.",
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "price": {
-                    "type": "integer"
-                },
-                "rooms": {
-                    "type": "integer"
-                },
-                "status": {
-                    "$ref": "#/definitions/domain.Status"
+        },
+        "/house/:id": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get all flats that are located at house. Clients see approved flats and their own ones in any status,\nsupports conditional requests with ETag and Last-Modified validators",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "Get House By Id",
+                "operationId": "getHouseById",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "house id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of cached response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_Flat"
+                        }
+                    },
+                    "304": {
+                        "description": "cached response is fresh"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
                 }
             }
         },
-        "domain.House": {
-            "type": "object",
+        "/house/:id/export": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "stream house flats visible to user as CSV or XLSX file",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "Export House Flats",
+                "operationId": "exportHouseFlats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "house id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated columns: id, flat_number, price, rooms, status",
+                        "name": "columns",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/house/:id/subscribe": {
+            "post": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "subscribe user to house specifying his email in body,\noptional rooms and price ranges restrict notifications to matching flats only,\ndelivery is \"immediate\" (default), \"daily\" or \"weekly\" digest of new flats",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "Subscribe To House With Id",
+                "operationId": "postSubscribeToHouse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "house id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User email and filters to subscribe",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.HouseSubscribeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "unsubscribe user with email specified in body from house, the email must be of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "Unsubscribe From House With Id",
+                "operationId": "deleteSubscribeToHouse",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "house id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User email to unsubscribe",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.HouseUnsubscribeInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/house/create": {
+            "post": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "create house with address, year and (perhaps) developer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "Create House",
+                "operationId": "createHouse",
+                "parameters": [
+                    {
+                        "description": "House info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.HouseCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_House"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/house/export": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "stream all houses as CSV or XLSX file",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "Export Houses",
+                "operationId": "exportHouses",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated columns: id, address, year, developer, created_at, updated_at",
+                        "name": "columns",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/:id": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get import job status with per-row error report",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Get Import Job",
+                "operationId": "getImportJob",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/flats": {
+            "post": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "import flats from CSV or JSON file (raw body or multipart 'file' field) in background,\nevery row is validated with the same rules as /flat/create input",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import Flats",
+                "operationId": "importFlats",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "file format, detected from content type if omitted",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "transaction",
+                            "chunks"
+                        ],
+                        "type": "string",
+                        "description": "import mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows per transaction in chunks mode",
+                        "name": "chunk_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate without saving",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/houses": {
+            "post": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "import houses from CSV or JSON file (raw body or multipart 'file' field) in background",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import Houses",
+                "operationId": "importHouses",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "file format, detected from content type if omitted",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "transaction",
+                            "chunks"
+                        ],
+                        "type": "string",
+                        "description": "import mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows per transaction in chunks mode",
+                        "name": "chunk_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate without saving",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_ImportJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/:flatId/claim": {
+            "post": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "assign flat awaiting moderation to the caller until lease expires, so that only the caller\nmay update its status. Claiming flat again extends the lease, expired claims are released.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Claim Flat",
+                "operationId": "claimFlat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "flat id",
+                        "name": "flatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_FlatClaim"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/decline-reasons": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get catalogue of reasons flats are declined with, each with checklist for the flat creator",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Get Decline Reasons",
+                "operationId": "getDeclineReasons",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "include archived reasons",
+                        "name": "archived",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_DeclineReason"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/decline-reasons/:code": {
+            "put": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "add decline reason to the catalogue or replace existing one, restoring it if archived",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Set Decline Reason",
+                "operationId": "setDeclineReason",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "reason code of lowercase letters, digits and underscores",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "reason title and checklist",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.DeclineReasonInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_DeclineReason"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "withdraw decline reason from use, flats already declined with it keep it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Archive Decline Reason",
+                "operationId": "archiveDeclineReason",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "reason code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/queue": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get flats awaiting moderation, oldest first. Flats claimed by other moderators are left out,\nones claimed by the caller carry the claim.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Get Moderation Queue",
+                "operationId": "getModerationQueue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "house id to limit queue to",
+                        "name": "house_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, 50 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_QueuedFlat"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get notifications that weren't delivered in all attempts, most recently failed first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get Dead Letters",
+                "operationId": "getDeadLetters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page size, 50 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_DeadLetter"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/dead-letters/:id/redrive": {
+            "post": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "put dead letter back to outbox to be sent again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Re-drive Dead Letter",
+                "operationId": "redriveDeadLetter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "dead letter id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_OutboxMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/templates": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get event types notifications are sent for with locales their templates are available in",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get Notification Templates",
+                "operationId": "getNotificationTemplates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_NotificationTemplate"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/templates/:event/preview": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "render event notification template in locale with sample data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Preview Notification Template",
+                "operationId": "previewNotificationTemplate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "event type",
+                        "name": "event",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "template locale, default one if empty",
+                        "name": "locale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_NotificationPreview"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/unsubscribe": {
+            "get": {
+                "description": "check signed expiring token from the link unsubscribing recipient of notification email from houses\nit's about and ask to confirm unsubscribing, no authorization is required. Subscriptions are left as is.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "Unsubscribe By Link",
+                "operationId": "unsubscribe",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unsubscribe token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "confirmation form page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid link page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "expired link page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "error page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "unsubscribe recipient of notification email from houses it's about with signed expiring token\nfrom the link, no authorization is required. Sent by the confirmation form and by mail clients\nfor List-Unsubscribe-Post header. Unsubscribing again succeeds as well.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "house"
+                ],
+                "summary": "One-Click Unsubscribe",
+                "operationId": "unsubscribeOneClick",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unsubscribe token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid link page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "410": {
+                        "description": "expired link page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "error page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/export": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get all data stored about user: account, notification settings, subscriptions, created flats\nand status history of them along with status changes user made as moderator.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Export User Data",
+                "operationId": "exportUserData",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_AccountExport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/flats": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get flats created by user in any status, newest first, with summaries of their houses.\nFlats edited since approval carry their approved version as well.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get User Flats",
+                "operationId": "getUserFlats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_FlatDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/language": {
+            "put": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "set language notifications are sent to registered user in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Set User Language",
+                "operationId": "setUserLanguage",
+                "parameters": [
+                    {
+                        "description": "language, ru or en",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UserLanguageInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notification-settings": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get contacts, quiet hours and channels notifications are sent to registered user through",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get Notification Settings",
+                "operationId": "getNotificationSettings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_NotificationSettings"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "replace contacts, quiet hours and channels notifications are sent to registered user through.\nSMS and push notifications are delayed until the end of quiet hours.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Set Notification Settings",
+                "operationId": "setNotificationSettings",
+                "parameters": [
+                    {
+                        "description": "notification settings",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.NotificationSettingsInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get notifications from user in-app inbox, most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get User Notifications",
+                "operationId": "getUserNotifications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page size, 50 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_Notification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notifications/:id/read": {
+            "post": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "mark notification from user inbox read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Read User Notification",
+                "operationId": "readUserNotification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "notification id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notifications/read": {
+            "post": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "mark all notifications from user inbox read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Read All User Notifications",
+                "operationId": "readAllUserNotifications",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get house subscriptions of registered user with their filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get User Subscriptions",
+                "operationId": "getUserSubscriptions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_Subscription"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/subscriptions/:id/delivery": {
+            "put": {
+                "security": [
+                    {
+                        "ClientsAuth": []
+                    },
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "set how user is notified about new flats of the house: \"immediate\" message per flat,\n\"daily\" or \"weekly\" digest listing each flat once. Flats already pending are sent with the pending digest.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Set Subscription Delivery",
+                "operationId": "setSubscriptionDelivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "house id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "delivery",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SubscriptionDeliveryInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get registered webhooks, secrets aren't shown",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get Webhooks",
+                "operationId": "getWebhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "register partner endpoint events are posted to, signed with HMAC-SHA256 of \"\u003ctimestamp\u003e.\u003cbody\u003e\"\nkeyed with secret, generated if not provided. Secret is shown only in this response.\nURL host must resolve to public addresses, loopback, private and link-local ones are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create Webhook",
+                "operationId": "createWebhook",
+                "parameters": [
+                    {
+                        "description": "webhook info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.WebhookCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-v1_createdWebhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/:id": {
+            "delete": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "delete webhook with its delivery logs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete Webhook",
+                "operationId": "deleteWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/:id/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "get delivery logs of webhook, most recent first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get Webhook Deliveries",
+                "operationId": "getWebhookDeliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, 50 by default and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-array_domain_WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/:id/enable": {
+            "post": {
+                "security": [
+                    {
+                        "ModeratorsAuth": []
+                    }
+                ],
+                "description": "re-enable webhook disabled after failed deliveries",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Enable Webhook",
+                "operationId": "enableWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.DataResponse-domain_Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/v1.response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AccountExport": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "flats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FlatDetails"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "$ref": "#/definitions/domain.Language"
+                },
+                "notificationSettings": {
+                    "$ref": "#/definitions/domain.NotificationSettings"
+                },
+                "statusHistory": {
+                    "description": "StatusHistory is status changes of flats created by user and ones made by user, oldest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FlatStatusRecord"
+                    }
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Subscription"
+                    }
+                },
+                "userType": {
+                    "$ref": "#/definitions/domain.UserType"
+                }
+            }
+        },
+        "domain.Channel": {
+            "type": "string",
+            "enum": [
+                "email",
+                "sms",
+                "push",
+                "in_app",
+                "webhook",
+                "user"
+            ],
+            "x-enum-varnames": [
+                "ChannelEmail",
+                "ChannelSMS",
+                "ChannelPush",
+                "ChannelInApp",
+                "ChannelWebhook",
+                "ChannelUser"
+            ]
+        },
+        "domain.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "$ref": "#/definitions/domain.Channel"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
+                "failedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "outboxID": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "recipient": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeclineReason": {
+            "type": "object",
+            "properties": {
+                "archivedAt": {
+                    "type": "string"
+                },
+                "checklist": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Delivery": {
+            "type": "string",
+            "enum": [
+                "immediate",
+                "daily",
+                "weekly"
+            ],
+            "x-enum-varnames": [
+                "DeliveryImmediate",
+                "DeliveryDaily",
+                "DeliveryWeekly"
+            ]
+        },
+        "domain.EventType": {
+            "type": "string",
+            "enum": [
+                "flat_approved",
+                "flats_digest"
+            ],
+            "x-enum-varnames": [
+                "EventFlatApproved",
+                "EventFlatsDigest"
+            ]
+        },
+        "domain.Flat": {
+            "type": "object",
+            "properties": {
+                "flatNumber": {
+                    "description": "Есть условие \"номер квартиры\", но его почему-то нет в API.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rooms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.Status"
+                }
+            }
+        },
+        "domain.FlatClaim": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "flatID": {
+                    "type": "integer"
+                },
+                "moderatorID": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FlatDecline": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "reason": {
+                    "$ref": "#/definitions/domain.DeclineReason"
+                }
+            }
+        },
+        "domain.FlatDetails": {
+            "type": "object",
+            "properties": {
+                "approved": {
+                    "description": "Approved is previously approved version of the flat, set while its later edit isn't approved.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FlatVersion"
+                        }
+                    ]
+                },
+                "decline": {
+                    "description": "Decline is set for flat declined by moderator until it's approved.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FlatDecline"
+                        }
+                    ]
+                },
+                "flatNumber": {
+                    "description": "Есть условие \"номер квартиры\", но его почему-то нет в API.",
+                    "type": "integer"
+                },
+                "house": {
+                    "$ref": "#/definitions/domain.HouseSummary"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rooms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.Status"
+                }
+            }
+        },
+        "domain.FlatStatusChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "newStatus": {
+                    "$ref": "#/definitions/domain.Status"
+                },
+                "oldStatus": {
+                    "$ref": "#/definitions/domain.Status"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FlatStatusRecord": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "flatID": {
+                    "type": "integer"
+                },
+                "newStatus": {
+                    "$ref": "#/definitions/domain.Status"
+                },
+                "oldStatus": {
+                    "$ref": "#/definitions/domain.Status"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FlatTransitions": {
+            "type": "object",
+            "properties": {
+                "flatID": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Status"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.Status"
+                }
+            }
+        },
+        "domain.FlatVersion": {
+            "type": "object",
+            "properties": {
+                "flatNumber": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rooms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.House": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "developer": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.HouseSummary": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "developer": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportEntity": {
+            "type": "string",
+            "enum": [
+                "houses",
+                "flats"
+            ],
+            "x-enum-varnames": [
+                "ImportEntityHouses",
+                "ImportEntityFlats"
+            ]
+        },
+        "domain.ImportJob": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "entity": {
+                    "$ref": "#/definitions/domain.ImportEntity"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImportRowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "mode": {
+                    "$ref": "#/definitions/domain.ImportMode"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.ImportStatus"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportMode": {
+            "type": "string",
+            "enum": [
+                "transaction",
+                "chunks"
+            ],
+            "x-enum-varnames": [
+                "ImportModeTransaction",
+                "ImportModeChunks"
+            ]
+        },
+        "domain.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportStatusPending",
+                "ImportStatusRunning",
+                "ImportStatusCompleted",
+                "ImportStatusFailed"
+            ]
+        },
+        "domain.Language": {
+            "type": "string",
+            "enum": [
+                "ru",
+                "en",
+                "ru"
+            ],
+            "x-enum-varnames": [
+                "LanguageRu",
+                "LanguageEn",
+                "DefaultLanguage"
+            ]
+        },
+        "domain.Notification": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "readAt": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NotificationPreview": {
+            "type": "object",
             "properties": {
-                "address": {
+                "eventType": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
+                "html": {
                     "type": "string"
                 },
-                "createdAt": {
+                "locale": {
                     "type": "string"
                 },
-                "developer": {
+                "subject": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NotificationSettings": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "description": "Channels user chose for event types, defaults are used for other ones.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/domain.Channel"
+                        }
+                    }
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "pushToken": {
+                    "type": "string"
+                },
+                "quietHours": {
+                    "$ref": "#/definitions/domain.QuietHours"
+                },
+                "timezone": {
+                    "description": "Timezone quiet hours are in, IANA name.",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NotificationTemplate": {
+            "type": "object",
+            "properties": {
+                "eventType": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
+                "locales": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.OutboxMessage": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "availableAt": {
+                    "description": "AvailableAt is time message is dispatched not earlier than.",
+                    "type": "string"
+                },
+                "channel": {
+                    "$ref": "#/definitions/domain.Channel"
+                },
+                "createdAt": {
                     "type": "string"
                 },
+                "eventType": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "updatedAt": {
+                "language": {
+                    "description": "Language of the recipient, empty if recipient isn't a registered user.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Language"
+                        }
+                    ]
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "recipient": {
                     "type": "string"
                 },
-                "year": {
+                "userID": {
+                    "description": "UserID of the recipient, nil if recipient isn't a registered user.",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.QueuedFlat": {
+            "type": "object",
+            "properties": {
+                "claimExpiresAt": {
+                    "type": "string"
+                },
+                "claimedBy": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "flatNumber": {
+                    "description": "Есть условие \"номер квартиры\", но его почему-то нет в API.",
+                    "type": "integer"
+                },
+                "houseID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rooms": {
                     "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.Status"
+                }
+            }
+        },
+        "domain.QuietHours": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "description": "Start and End are local times in \"15:04\" format.",
+                    "type": "string"
                 }
             }
         },
@@ -537,6 +3297,35 @@ const docTemplate = `{
                 "StatusOnModeration"
             ]
         },
+        "domain.Subscription": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "delivery": {
+                    "$ref": "#/definitions/domain.Delivery"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "houseID": {
+                    "type": "integer"
+                },
+                "maxPrice": {
+                    "type": "integer"
+                },
+                "maxRooms": {
+                    "type": "integer"
+                },
+                "minPrice": {
+                    "type": "integer"
+                },
+                "minRooms": {
+                    "type": "integer"
+                }
+            }
+        },
         "domain.UserType": {
             "type": "string",
             "enum": [
@@ -548,71 +3337,276 @@ const docTemplate = `{
                 "UserTypeModerator"
             ]
         },
-        "dtos.FlatCreateInput": {
+        "domain.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "consecutiveFailures": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "$ref": "#/definitions/domain.EventType"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "outboxID": {
+                    "type": "integer"
+                },
+                "statusCode": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "webhookID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.DeclineReasonInput": {
+            "type": "object",
+            "required": [
+                "checklist",
+                "title"
+            ],
+            "properties": {
+                "checklist": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "dtos.FlatCreateInput": {
+            "type": "object",
+            "required": [
+                "flat_number",
+                "house_id",
+                "price",
+                "rooms"
+            ],
+            "properties": {
+                "flat_number": {
+                    "description": "same there.",
+                    "type": "integer"
+                },
+                "house_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rooms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.FlatEditInput": {
+            "type": "object",
+            "properties": {
+                "flat_number": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rooms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.FlatUpdateInput": {
+            "type": "object",
+            "required": [
+                "flat_id",
+                "status"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "flat_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Reason is code of decline reason from the catalogue, Comment explains it to the flat creator.\nBoth are required when declining flat and not allowed otherwise.",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.Status"
+                }
+            }
+        },
+        "dtos.HouseCreateInput": {
+            "type": "object",
+            "required": [
+                "address",
+                "year"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "developer": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.HouseSubscribeInput": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "delivery": {
+                    "description": "Delivery is \"immediate\" by default.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Delivery"
+                        }
+                    ]
+                },
+                "email": {
+                    "type": "string"
+                },
+                "max_price": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max_rooms": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "min_price": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "min_rooms": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "dtos.HouseUnsubscribeInput": {
             "type": "object",
             "required": [
-                "flat_number",
-                "house_id",
-                "price",
-                "rooms"
+                "email"
             ],
             "properties": {
-                "flat_number": {
-                    "description": "TODO same there.",
-                    "type": "integer"
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.NotificationSettingsInput": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "description": "Channels for event types, defaults are used for omitted ones and no notifications are sent for empty ones.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/domain.Channel"
+                        }
+                    }
                 },
-                "house_id": {
-                    "type": "integer"
+                "phone": {
+                    "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "push_token": {
+                    "type": "string"
                 },
-                "rooms": {
-                    "type": "integer"
+                "quiet_hours": {
+                    "$ref": "#/definitions/dtos.QuietHoursInput"
+                },
+                "timezone": {
+                    "description": "Timezone is IANA name quiet hours are in, UTC if empty.",
+                    "type": "string"
                 }
             }
         },
-        "dtos.FlatUpdateInput": {
+        "dtos.QuietHoursInput": {
             "type": "object",
             "required": [
-                "flat_id",
-                "status"
+                "end",
+                "start"
             ],
             "properties": {
-                "flat_id": {
-                    "type": "integer"
+                "end": {
+                    "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/domain.Status"
+                "start": {
+                    "type": "string"
                 }
             }
         },
-        "dtos.HouseCreateInput": {
+        "dtos.SubscriptionDeliveryInput": {
             "type": "object",
             "required": [
-                "address",
-                "year"
+                "delivery"
             ],
             "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "developer": {
-                    "type": "string"
-                },
-                "year": {
-                    "type": "integer"
+                "delivery": {
+                    "$ref": "#/definitions/domain.Delivery"
                 }
             }
         },
-        "dtos.HouseSubscribeInput": {
+        "dtos.UserLanguageInput": {
             "type": "object",
             "required": [
-                "email"
+                "language"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "language": {
+                    "$ref": "#/definitions/domain.Language"
                 }
             }
         },
@@ -642,6 +3636,9 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "language": {
+                    "$ref": "#/definitions/domain.Language"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -650,15 +3647,53 @@ const docTemplate = `{
                 }
             }
         },
-        "v1.authTokenResponse": {
+        "dtos.WebhookCreateInput": {
             "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
             "properties": {
-                "auth_token": {
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    }
+                },
+                "secret": {
+                    "description": "Secret payloads are signed with, generated if empty.",
+                    "type": "string",
+                    "minLength": 16
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "v1.dataResponse-array_domain_Flat": {
+        "v1.DataResponse-array_domain_DeadLetter": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeadLetter"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_DeclineReason": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeclineReason"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_Flat": {
             "type": "object",
             "properties": {
                 "data": {
@@ -669,7 +3704,111 @@ const docTemplate = `{
                 }
             }
         },
-        "v1.dataResponse-domain_Flat": {
+        "v1.DataResponse-array_domain_FlatDetails": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FlatDetails"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_FlatStatusChange": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FlatStatusChange"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_Notification": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Notification"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_NotificationTemplate": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NotificationTemplate"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_QueuedFlat": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.QueuedFlat"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_Subscription": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Subscription"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_Webhook": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Webhook"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-array_domain_WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookDelivery"
+                    }
+                }
+            }
+        },
+        "v1.DataResponse-domain_AccountExport": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.AccountExport"
+                }
+            }
+        },
+        "v1.DataResponse-domain_DeclineReason": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.DeclineReason"
+                }
+            }
+        },
+        "v1.DataResponse-domain_Flat": {
             "type": "object",
             "properties": {
                 "data": {
@@ -677,7 +3816,31 @@ const docTemplate = `{
                 }
             }
         },
-        "v1.dataResponse-domain_House": {
+        "v1.DataResponse-domain_FlatClaim": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.FlatClaim"
+                }
+            }
+        },
+        "v1.DataResponse-domain_FlatDetails": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.FlatDetails"
+                }
+            }
+        },
+        "v1.DataResponse-domain_FlatTransitions": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.FlatTransitions"
+                }
+            }
+        },
+        "v1.DataResponse-domain_House": {
             "type": "object",
             "properties": {
                 "data": {
@@ -685,21 +3848,109 @@ const docTemplate = `{
                 }
             }
         },
-        "v1.response": {
+        "v1.DataResponse-domain_ImportJob": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "data": {
+                    "$ref": "#/definitions/domain.ImportJob"
+                }
+            }
+        },
+        "v1.DataResponse-domain_NotificationPreview": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.NotificationPreview"
+                }
+            }
+        },
+        "v1.DataResponse-domain_NotificationSettings": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.NotificationSettings"
+                }
+            }
+        },
+        "v1.DataResponse-domain_OutboxMessage": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.OutboxMessage"
+                }
+            }
+        },
+        "v1.DataResponse-domain_Webhook": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/domain.Webhook"
+                }
+            }
+        },
+        "v1.DataResponse-v1_createdWebhook": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/v1.createdWebhook"
                 }
             }
         },
-        "v1.userIdResponse": {
+        "v1.UserIdResponse": {
             "type": "object",
             "properties": {
                 "user_id": {
                     "type": "string"
                 }
             }
+        },
+        "v1.authTokenResponse": {
+            "type": "object",
+            "properties": {
+                "auth_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.createdWebhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "consecutiveFailures": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.response": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
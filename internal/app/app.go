@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"github.com/dzhordano/avito-bootcamp2024/internal/config"
 	"github.com/dzhordano/avito-bootcamp2024/internal/delivery/http"
@@ -12,9 +13,13 @@ import (
 	"github.com/dzhordano/avito-bootcamp2024/pkg/databases/postgres"
 	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
 	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/sender"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/templates"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/unsubscribe"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/webhooks"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/workerpool"
+	"log/slog"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 )
@@ -27,16 +32,7 @@ func Run() {
 
 	tokenManager := auth.NewJWTManager(cfg.Auth.SecretKey, cfg.Auth.TokenTTL)
 
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		cfg.Postgres.User,
-		cfg.Postgres.Password,
-		cfg.Postgres.Host,
-		cfg.Postgres.Port,
-		cfg.Postgres.Database,
-		cfg.Postgres.SSLMode,
-	)
-
-	pool, err := postgres.NewClient(dsn)
+	pool, err := postgres.NewClient(cfg.Postgres.DSN())
 	if err != nil {
 		log.Error("failed to connect to postgres: " + err.Error())
 
@@ -44,19 +40,119 @@ func Run() {
 	}
 	defer pool.Close()
 
-	notificationSender := sender.New()
+	var emailSender sender.Sender = sender.New()
+	if cfg.Notifications.Sender == "smtp" {
+		smtpSender, err := sender.NewSMTP(sender.SMTPConfig{
+			Host:     cfg.Notifications.SMTP.Host,
+			Port:     cfg.Notifications.SMTP.Port,
+			Username: cfg.Notifications.SMTP.Username,
+			Password: cfg.Notifications.SMTP.Password,
+			From:     cfg.Notifications.SMTP.From,
+			TLS:      sender.TLSMode(cfg.Notifications.SMTP.TLS),
+			Timeout:  cfg.Notifications.SMTP.Timeout,
+		})
+		if err != nil {
+			log.Error("failed to init smtp sender: " + err.Error())
+
+			return
+		}
+		defer smtpSender.Close()
+
+		emailSender = smtpSender
+	}
+
+	notificationSender := sender.NewRetrying(emailSender, sender.RetryConfig{
+		MaxAttempts: cfg.Notifications.SendRetry.MaxAttempts,
+		BaseDelay:   cfg.Notifications.SendRetry.BaseDelay,
+		MaxDelay:    cfg.Notifications.SendRetry.MaxDelay,
+	})
+
+	// SMS, push and in-app notifications have no real providers yet.
+	channelsStub := sender.NewStub(os.Stdout)
+
+	renderer, err := templates.New()
+	if err != nil {
+		log.Error("failed to parse notification templates: " + err.Error())
+
+		return
+	}
 
-	toWaitTasks := &sync.WaitGroup{}
+	webhooksClient := webhooks.NewClient(webhooks.Config{
+		Timeout:      cfg.Webhooks.Timeout,
+		AllowPrivate: cfg.Webhooks.AllowPrivate,
+	})
 
-	repo := repository.New(pool)
+	notificationsPool := workerpool.New(cfg.Workers.NotificationWorkers, cfg.Workers.NotificationQueue)
+	importsPool := workerpool.New(cfg.Workers.ImportWorkers, cfg.Workers.ImportQueue)
+	expvar.Publish("notifications_pool", expvar.Func(func() any {
+		return notificationsPool.Stats()
+	}))
+	expvar.Publish("imports_pool", expvar.Func(func() any {
+		return importsPool.Stats()
+	}))
+
+	housesCache := repository.NewHousesCache(cfg.Cache.HousesSize, cfg.Cache.HousesTTL)
+	expvar.Publish("houses_cache", expvar.Func(func() any {
+		return housesCache.Stats()
+	}))
+
+	repo := repository.New(pool).WithHousesCache(housesCache)
+	outboxConfig := service.OutboxConfig{
+		Lease: cfg.Notifications.DispatchLease,
+		Retry: service.RetryPolicy{
+			MaxAttempts: cfg.Notifications.Retry.MaxAttempts,
+			BaseDelay:   cfg.Notifications.Retry.BaseDelay,
+			MaxDelay:    cfg.Notifications.Retry.MaxDelay,
+		},
+		Webhooks: service.RetryPolicy{
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			BaseDelay:   cfg.Webhooks.BaseDelay,
+			MaxDelay:    cfg.Webhooks.MaxDelay,
+		},
+	}
 	svc := service.New(service.Deps{
 		Repos:         repo,
 		TokensManager: tokenManager,
-		Notifications: notificationSender,
-		WaitGroup:     toWaitTasks,
-		Logger:        log,
+		Notifications: service.Senders{
+			Email: notificationSender,
+			SMS:   channelsStub,
+			Push:  channelsStub,
+		},
+		Templates:           renderer,
+		Unsubscribe:         unsubscribe.NewSigner(cfg.Notifications.UnsubscribeSecret, cfg.Notifications.UnsubscribeTTL),
+		BaseURL:             cfg.Notifications.BaseURL,
+		Outbox:              outboxConfig,
+		Webhooks:            webhooksClient,
+		WebhookDisableAfter: cfg.Webhooks.DisableAfter,
+		NotificationsPool:   notificationsPool,
+		ImportsPool:         importsPool,
+		ImportJobTTL:        cfg.Workers.ImportJobTTL,
+		ModerationLease:     cfg.Moderation.ClaimLease,
+		Logger:              log,
 	})
 
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	dispatchDone := make(chan struct{})
+	go func() {
+		defer close(dispatchDone)
+
+		svc.Outbox.Run(dispatchCtx, cfg.Notifications.DispatchInterval, cfg.Notifications.DispatchBatchSize)
+	}()
+
+	digestsDone := make(chan struct{})
+	go func() {
+		defer close(digestsDone)
+
+		svc.Digests.Run(dispatchCtx, cfg.Notifications.DigestInterval)
+	}()
+
+	claimsDone := make(chan struct{})
+	go func() {
+		defer close(claimsDone)
+
+		svc.Moderation.Run(dispatchCtx, cfg.Moderation.ReleaseInterval)
+	}()
+
 	handler := http.NewHandler(svc, tokenManager)
 	srv := server.NewServer(cfg, handler.Init())
 
@@ -66,8 +162,6 @@ func Run() {
 		}
 	}()
 
-	toWaitTasks.Wait()
-
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
@@ -80,7 +174,32 @@ func Run() {
 
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Error("failed to shutdown server: " + err.Error())
+	}
+
+	// Messages of interrupted batch stay pending and are sent after restart.
+	stopDispatch()
+	<-dispatchDone
+	<-digestsDone
+	<-claimsDone
+
+	// Requests and dispatchers are stopped, so no more jobs are submitted to pools.
+	drainCtx, stopDrain := context.WithTimeout(context.Background(), cfg.Workers.DrainTimeout)
+	defer stopDrain()
+
+	drainPool(drainCtx, "notifications", notificationsPool, log)
+	drainPool(drainCtx, "imports", importsPool, log)
+}
+
+// drainPool waits for jobs of the pool to finish until ctx is done and reports jobs dropped after that.
+func drainPool(ctx context.Context, name string, pool *workerpool.Pool, log *slog.Logger) {
+	log = log.With(slog.String("pool", name))
+
+	dropped, err := pool.Shutdown(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("failed to drain worker pool: %s, %d queued jobs dropped", err.Error(), dropped))
 
 		return
 	}
+
+	log.Info("worker pool drained")
 }
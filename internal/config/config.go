@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 	"log"
@@ -14,10 +15,15 @@ var (
 )
 
 type Config struct {
-	Env      string         `yaml:"env"`
-	Postgres PostgresConfig `yaml:"postgres"`
-	HTTP     HTTPConfig     `yaml:"http"`
-	Auth     AuthConfig     `yaml:"auth"`
+	Env           string              `yaml:"env"`
+	Postgres      PostgresConfig      `yaml:"postgres"`
+	HTTP          HTTPConfig          `yaml:"http"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Cache         CacheConfig         `yaml:"cache"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Webhooks      WebhooksConfig      `yaml:"webhooks"`
+	Workers       WorkersConfig       `yaml:"workers"`
+	Moderation    ModerationConfig    `yaml:"moderation"`
 }
 
 type PostgresConfig struct {
@@ -29,6 +35,18 @@ type PostgresConfig struct {
 	SSLMode  string `env:"PGSSLMODE"`
 }
 
+// DSN returns connection string for postgres.
+func (c PostgresConfig) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		c.User,
+		c.Password,
+		c.Host,
+		c.Port,
+		c.Database,
+		c.SSLMode,
+	)
+}
+
 type HTTPConfig struct {
 	Host               string        `yaml:"host"`
 	Port               string        `yaml:"port"`
@@ -42,6 +60,89 @@ type AuthConfig struct {
 	TokenTTL  time.Duration `yaml:"token_ttl"`
 }
 
+type CacheConfig struct {
+	HousesSize int           `yaml:"houses_size" env-default:"1024"`
+	HousesTTL  time.Duration `yaml:"houses_ttl" env-default:"1m"`
+}
+
+type NotificationsConfig struct {
+	// BaseURL is public address of the service used in links sent to users.
+	BaseURL string `yaml:"base_url" env-default:"http://localhost:8080"`
+
+	DispatchInterval  time.Duration `yaml:"dispatch_interval" env-default:"1s"`
+	DispatchBatchSize int           `yaml:"dispatch_batch_size" env-default:"10"`
+	// DispatchLease is how long dispatched messages are hidden from other dispatchers,
+	// their delivery must finish within it.
+	DispatchLease time.Duration `yaml:"dispatch_lease" env-default:"1m"`
+	// DigestInterval is how often due daily and weekly digests are checked for.
+	DigestInterval time.Duration `yaml:"digest_interval" env-default:"1m"`
+	// UnsubscribeSecret signs unsubscribe links sent in emails, they expire after UnsubscribeTTL.
+	UnsubscribeSecret string        `env:"UNSUBSCRIBE_SECRET"`
+	UnsubscribeTTL    time.Duration `yaml:"unsubscribe_ttl" env-default:"720h"`
+
+	// Sender is either "stub", printing messages to stdout, or "smtp".
+	Sender string     `yaml:"sender" env-default:"stub"`
+	SMTP   SMTPConfig `yaml:"smtp"`
+	// SendRetry retries failed email within a dispatch, its attempts must fit in DispatchLease.
+	SendRetry SendRetryConfig `yaml:"send_retry"`
+	// Retry spaces delivery attempts of messages failed in a dispatch.
+	Retry RetryConfig `yaml:"retry"`
+}
+
+type SMTPConfig struct {
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port" env-default:"587"`
+	Username string        `env:"SMTP_USERNAME"`
+	Password string        `env:"SMTP_PASSWORD"`
+	From     string        `yaml:"from"`
+	TLS      string        `yaml:"tls" env-default:"starttls"`
+	Timeout  time.Duration `yaml:"timeout" env-default:"10s"`
+}
+
+type SendRetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts" env-default:"3"`
+	BaseDelay   time.Duration `yaml:"base_delay" env-default:"200ms"`
+	MaxDelay    time.Duration `yaml:"max_delay" env-default:"5s"`
+}
+
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts" env-default:"5"`
+	BaseDelay   time.Duration `yaml:"base_delay" env-default:"10s"`
+	MaxDelay    time.Duration `yaml:"max_delay" env-default:"10m"`
+}
+
+type WebhooksConfig struct {
+	// Timeout limits each delivery attempt, attempts of failed message are spaced like notification ones.
+	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"5"`
+	BaseDelay   time.Duration `yaml:"base_delay" env-default:"30s"`
+	MaxDelay    time.Duration `yaml:"max_delay" env-default:"30m"`
+	// DisableAfter is number of failed delivery attempts in a row endpoint is disabled after.
+	DisableAfter int `yaml:"disable_after" env-default:"10"`
+	// AllowPrivate lets endpoints have loopback, private and link-local addresses, for local development only.
+	AllowPrivate bool `yaml:"allow_private" env-default:"false"`
+}
+
+// WorkersConfig sizes pools background jobs run on. Job submitted to a full queue
+// either waits, as notifications do, or is rejected, as imports are.
+type WorkersConfig struct {
+	NotificationWorkers int `yaml:"notification_workers" env-default:"8"`
+	NotificationQueue   int `yaml:"notification_queue" env-default:"64"`
+	ImportWorkers       int `yaml:"import_workers" env-default:"2"`
+	ImportQueue         int `yaml:"import_queue" env-default:"8"`
+	// ImportJobTTL is how long finished import job is kept for polling.
+	ImportJobTTL time.Duration `yaml:"import_job_ttl" env-default:"24h"`
+	// DrainTimeout is how long queued and running jobs are waited for on shutdown.
+	DrainTimeout time.Duration `yaml:"drain_timeout" env-default:"10s"`
+}
+
+// ModerationConfig sets how long moderator holds claimed flat
+// and how often flats with expired claims are returned to the queue.
+type ModerationConfig struct {
+	ClaimLease      time.Duration `yaml:"claim_lease" env-default:"15m"`
+	ReleaseInterval time.Duration `yaml:"release_interval" env-default:"1m"`
+}
+
 func init() {
 	err := godotenv.Load()
 	if err != nil {
@@ -39,5 +39,8 @@ func (h *Handler) Init() *gin.Engine {
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
+	// Metrics, e.g. cache hits and misses.
+	handlerV1.InitDebugRoutes(router)
+
 	return router
 }
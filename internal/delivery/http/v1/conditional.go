@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// houseETag identifies house flats list as seen by user at the moment house was modified.
+// Lists of clients include their own flats, so user id is part of it.
+func houseETag(houseId int, userType, userId string, modified time.Time) string {
+	return fmt.Sprintf(`W/"%d-%s-%s-%x"`, houseId, userType, userId, modified.UnixNano())
+}
+
+// checkNotModified sets cache validators and reports whether client's copy is fresh,
+// in which case 304 is already written.
+// If-None-Match takes precedence over If-Modified-Since as RFC 9110 says.
+func checkNotModified(c *gin.Context, etag string, modified time.Time) bool {
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	// Lists differ between users, so shared caches must not store them.
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("Vary", "Authorization")
+
+	if inm := c.GetHeader("If-None-Match"); inm != "" {
+		if !etagMatches(inm, etag) {
+			return false
+		}
+
+		c.AbortWithStatus(http.StatusNotModified)
+
+		return true
+	}
+
+	if ims := c.GetHeader("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil || modified.Truncate(time.Second).After(since) {
+			return false
+		}
+
+		c.AbortWithStatus(http.StatusNotModified)
+
+		return true
+	}
+
+	return false
+}
+
+// etagMatches implements weak comparison of If-None-Match header against etag.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,222 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/export"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type exportColumn[T any] struct {
+	name  string
+	value func(T) any
+}
+
+var flatExportColumns = []exportColumn[domain.Flat]{
+	{"id", func(f domain.Flat) any { return f.ID }},
+	{"flat_number", func(f domain.Flat) any { return f.FlatNumber }},
+	{"price", func(f domain.Flat) any { return f.Price }},
+	{"rooms", func(f domain.Flat) any { return f.Rooms }},
+	{"status", func(f domain.Flat) any { return f.Status }},
+}
+
+var houseExportColumns = []exportColumn[domain.House]{
+	{"id", func(h domain.House) any { return h.ID }},
+	{"address", func(h domain.House) any { return h.Address }},
+	{"year", func(h domain.House) any { return h.Year }},
+	{"developer", func(h domain.House) any { return h.Developer }},
+	{"created_at", func(h domain.House) any { return h.CreatedAt }},
+	{"updated_at", func(h domain.House) any { return h.UpdatedAt }},
+}
+
+// @Summary		Export House Flats
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	stream house flats visible to user as CSV or XLSX file
+// @ID				exportHouseFlats
+// @Tags			house
+// @Produce		text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param			id		path		string	true	"house id"
+// @Param			format	query		string	false	"file format"	Enums(csv, xlsx)	default(csv)
+// @Param			columns	query		string	false	"comma separated columns: id, flat_number, price, rooms, status"
+// @Success		200		{file}		file
+// @Failure		400		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/house/:id/export [get]
+func (h *Handler) exportHouseFlats(c *gin.Context) {
+	houseId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid house id type")
+
+		return
+	}
+
+	format, columns, err := exportParams(c, flatExportColumns)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	stream := newExportStream(c, format, fmt.Sprintf("house_%d_flats", houseId), columns)
+
+	err = h.services.Houses.ExportFlats(c, houseId, stream.write)
+	if err != nil {
+		if errors.Is(err, domain.ErrHouseNotFound) {
+			messageResponse(c, http.StatusNotFound, "house not found")
+
+			return
+		}
+
+		stream.fail(err)
+
+		return
+	}
+
+	stream.close()
+}
+
+// @Summary		Export Houses
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	stream all houses as CSV or XLSX file
+// @ID				exportHouses
+// @Tags			house
+// @Produce		text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param			format	query		string	false	"file format"	Enums(csv, xlsx)	default(csv)
+// @Param			columns	query		string	false	"comma separated columns: id, address, year, developer, created_at, updated_at"
+// @Success		200		{file}		file
+// @Failure		400		{object}	response
+// @Failure		500		{object}	response
+// @Router			/house/export [get]
+func (h *Handler) exportHouses(c *gin.Context) {
+	format, columns, err := exportParams(c, houseExportColumns)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	stream := newExportStream(c, format, "houses", columns)
+
+	if err = h.services.Houses.ExportHouses(c, stream.write); err != nil {
+		stream.fail(err)
+
+		return
+	}
+
+	stream.close()
+}
+
+// exportParams parses format and columns query params. All columns are exported if none are specified.
+func exportParams[T any](c *gin.Context, all []exportColumn[T]) (export.Format, []exportColumn[T], error) {
+	format := export.Format(c.DefaultQuery("format", string(export.FormatCSV)))
+	if !format.Validate() {
+		return "", nil, errors.New("invalid export format")
+	}
+
+	param := c.Query("columns")
+	if param == "" {
+		return format, all, nil
+	}
+
+	var columns []exportColumn[T]
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+
+		i := 0
+		for i < len(all) && all[i].name != name {
+			i++
+		}
+
+		if i == len(all) {
+			return "", nil, fmt.Errorf("unknown column '%s'", name)
+		}
+
+		columns = append(columns, all[i])
+	}
+
+	return format, columns, nil
+}
+
+// exportStream writes rows to response as soon as they're read.
+// Nothing is written until the first row or close, so errors found before can still be reported with JSON.
+type exportStream[T any] struct {
+	c        *gin.Context
+	format   export.Format
+	filename string
+	columns  []exportColumn[T]
+
+	w      export.Writer
+	values []any
+}
+
+func newExportStream[T any](c *gin.Context, format export.Format, filename string, columns []exportColumn[T]) *exportStream[T] {
+	return &exportStream[T]{
+		c:        c,
+		format:   format,
+		filename: filename,
+		columns:  columns,
+		values:   make([]any, len(columns)),
+	}
+}
+
+func (s *exportStream[T]) write(item T) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	for i, column := range s.columns {
+		s.values[i] = column.value(item)
+	}
+
+	return s.w.Write(s.values)
+}
+
+func (s *exportStream[T]) start() error {
+	if s.w != nil {
+		return nil
+	}
+
+	s.c.Header("Content-Type", s.format.ContentType())
+	s.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, s.filename, s.format))
+	s.c.Status(http.StatusOK)
+
+	w, err := export.New(s.format, s.c.Writer)
+	if err != nil {
+		return err
+	}
+	s.w = w
+
+	for i, column := range s.columns {
+		s.values[i] = column.name
+	}
+
+	return s.w.Write(s.values)
+}
+
+func (s *exportStream[T]) close() {
+	if err := s.start(); err != nil {
+		s.fail(err)
+
+		return
+	}
+
+	if err := s.w.Close(); err != nil {
+		s.fail(err)
+	}
+}
+
+// fail reports error with JSON if nothing was sent yet, otherwise the file is left unfinished.
+func (s *exportStream[T]) fail(err error) {
+	_ = s.c.Error(err)
+
+	if !s.c.Writer.Written() {
+		messageResponse(s.c, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_ExportHouseFlats(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockHouses)
+
+	flats := []domain.Flat{
+		{ID: 1, FlatNumber: 1, Price: 1000, Rooms: 2, Status: domain.StatusApproved},
+		{ID: 2, FlatNumber: 2, Price: 2000, Rooms: 3, Status: domain.StatusApproved},
+	}
+
+	exportFlats := func(s *mocks_service.MockHouses) {
+		s.EXPECT().
+			ExportFlats(gomock.Any(), 1, gomock.Any()).
+			DoAndReturn(func(_ any, _ int, fn func(domain.Flat) error) error {
+				for _, flat := range flats {
+					if err := fn(flat); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+	}
+
+	tests := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedContentType string
+		expectedReqBody     string
+	}{
+		{
+			name:                "OK",
+			mockBehaviour:       exportFlats,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedReqBody:     "id,flat_number,price,rooms,status\n1,1,1000,2,approved\n2,2,2000,3,approved\n",
+		},
+		{
+			name:                "Selected columns",
+			query:               "?columns=flat_number,status",
+			mockBehaviour:       exportFlats,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedReqBody:     "flat_number,status\n1,approved\n2,approved\n",
+		},
+		{
+			name:                "Unknown column",
+			query:               "?columns=owner",
+			mockBehaviour:       func(s *mocks_service.MockHouses) {},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedReqBody:     `{"message":"unknown column 'owner'"}`,
+		},
+		{
+			name:                "Invalid format",
+			query:               "?format=pdf",
+			mockBehaviour:       func(s *mocks_service.MockHouses) {},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedReqBody:     `{"message":"invalid export format"}`,
+		},
+		{
+			name: "Not found",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().ExportFlats(gomock.Any(), 1, gomock.Any()).Return(domain.ErrHouseNotFound)
+			},
+			expectedStatusCode:  http.StatusNotFound,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedReqBody:     `{"message":"house not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			houses := mocks_service.NewMockHouses(c)
+			tt.mockBehaviour(houses)
+
+			services := &service.Services{
+				Houses: houses,
+			}
+
+			handler := NewHandler(services, nil)
+
+			r := gin.New()
+			r.GET("/api/house/:id/export", handler.exportHouseFlats)
+
+			w := httptest.NewRecorder()
+
+			req, _ := http.NewRequest("GET", "/api/house/1/export"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
 )
 
 func (h *Handler) initFlatRoutes(api *gin.RouterGroup) {
@@ -14,10 +15,14 @@ func (h *Handler) initFlatRoutes(api *gin.RouterGroup) {
 		authorized := flats.Group("/", h.isAuthorized)
 		{
 			authorized.POST("/create", h.createFlat)
+			authorized.GET("/:id", h.getFlatById)
+			authorized.PATCH("/:id", h.editFlat)
 
 			moderatorsOnly := authorized.Group("/", h.isModerator)
 			{
 				moderatorsOnly.POST("/update", h.updateFlat)
+				moderatorsOnly.GET("/:id/transitions", h.getFlatTransitions)
+				moderatorsOnly.GET("/:id/history", h.getFlatHistory)
 			}
 		}
 	}
@@ -26,7 +31,8 @@ func (h *Handler) initFlatRoutes(api *gin.RouterGroup) {
 // @Summary		Create flat
 // @Security		ClientsAuth
 // @Security		ModeratorsAuth
-// @Description	create flat with flatNumber, price, rooms and house id it belongs to
+// @Description	create flat with flatNumber, price, rooms and house id it belongs to,
+// @Description	creator sees the flat before it's approved
 // @ID				createFlat
 // @Tags			flat
 // @Accept			json
@@ -34,17 +40,25 @@ func (h *Handler) initFlatRoutes(api *gin.RouterGroup) {
 // @Param			input	body		dtos.FlatCreateInput	true	"Flat info"
 // @Success		201		{object}	DataResponse[domain.Flat]
 // @Failure		400		{object}	response
+// @Failure		401		{object}	response
 // @Failure		409		{object}	response
 // @Failure		500		{object}	response
 // @Router			/flat/create [post]
 func (h *Handler) createFlat(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
 	var inp dtos.FlatCreateInput
 	if err := c.BindJSON(&inp); err != nil {
 		messageResponse(c, http.StatusBadRequest, "invalid input body")
 		return
 	}
 
-	resp, err := h.services.Flats.Create(c.Request.Context(), inp)
+	resp, err := h.services.Flats.Create(c.Request.Context(), userId, inp)
 	if err != nil {
 
 		if errors.Is(err, domain.ErrFlatAlreadyExists) {
@@ -67,9 +81,145 @@ func (h *Handler) createFlat(c *gin.Context) {
 	c.JSON(http.StatusCreated, DataResponse[domain.Flat]{Data: resp})
 }
 
+// @Summary		Get Flat By Id
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	get flat with summary of its house. Clients see approved flats and ones they created,
+// @Description	other flats are reported not found. Moderators see flats of any status.
+// @ID				getFlatById
+// @Tags			flat
+// @Produce		json
+// @Param			id	path		string	true	"flat id"
+// @Success		200	{object}	DataResponse[domain.FlatDetails]
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/flat/:id [get]
+func (h *Handler) getFlatById(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	flatId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid flat id type")
+
+		return
+	}
+
+	resp, err := h.services.Flats.GetById(c, flatId, userId, domain.UserType(c.GetString(userTypeCtx)))
+	if err != nil {
+		if errors.Is(err, domain.ErrFlatNotFound) {
+			messageResponse(c, http.StatusNotFound, "flat not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.FlatDetails]{Data: resp})
+}
+
+// @Summary		Edit Flat
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	edit flat attributes, omitted ones are left as is. Flat may be edited by its creator or moderators.
+// @Description	Edited flat is sent back to moderation, while its previously approved version stays visible
+// @Description	to clients until the edit is approved. Flat claimed by moderator can't be edited by others.
+// @ID				editFlat
+// @Tags			flat
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string				true	"flat id"
+// @Param			input	body		dtos.FlatEditInput	true	"Flat attributes"
+// @Success		200		{object}	DataResponse[domain.Flat]
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		403		{object}	response
+// @Failure		404		{object}	response
+// @Failure		409		{object}	response
+// @Failure		500		{object}	response
+// @Router			/flat/:id [patch]
+func (h *Handler) editFlat(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	flatId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid flat id type")
+
+		return
+	}
+
+	var inp dtos.FlatEditInput
+	if err := c.BindJSON(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid input body")
+
+		return
+	}
+
+	if err := inp.Validate(); err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	resp, err := h.services.Flats.Edit(c.Request.Context(), flatId, userId, domain.UserType(c.GetString(userTypeCtx)), inp)
+	if err != nil {
+		if errors.Is(err, domain.ErrFlatNotFound) {
+			messageResponse(c, http.StatusNotFound, "flat not found")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrFlatEditForbidden) {
+			messageResponse(c, http.StatusForbidden, "flat may be edited by its creator only")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrFlatModerated) {
+			messageResponse(c, http.StatusConflict, "flat is being moderated")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			messageResponse(c, http.StatusConflict, "invalid transition")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrFlatAlreadyExists) {
+			messageResponse(c, http.StatusConflict, "flat already exists")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.Flat]{Data: resp})
+}
+
 // @Summary		Update flat
 // @Security		ModeratorsAuth
-// @Description	update flat status
+// @Description	update flat status, moderator must claim the flat first. Created flat may be approved or declined,
+// @Description	other transitions are rejected, see /flat/:id/transitions. Declining requires reason code
+// @Description	from /moderation/decline-reasons and comment, both are shown to the flat creator.
 // @ID				updateFlat
 // @Tags			flat
 // @Accept			json
@@ -77,10 +227,19 @@ func (h *Handler) createFlat(c *gin.Context) {
 // @Param			input	body		dtos.FlatUpdateInput	true	"Flat info"
 // @Success		200		{object}	DataResponse[domain.Flat]
 // @Failure		400		{object}	response
+// @Failure		401		{object}	response
 // @Failure		404		{object}	response
+// @Failure		409		{object}	response
 // @Failure		500		{object}	response
 // @Router			/flat/update [post]
 func (h *Handler) updateFlat(c *gin.Context) {
+	moderatorId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
 	var inp dtos.FlatUpdateInput
 	if err := c.BindJSON(&inp); err != nil {
 		messageResponse(c, http.StatusBadRequest, "invalid input body")
@@ -94,14 +253,32 @@ func (h *Handler) updateFlat(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.services.Flats.Update(c.Request.Context(), inp.FlatId, inp.Status)
+	resp, err := h.services.Flats.Update(c.Request.Context(), moderatorId, inp)
 	if err != nil {
+		if errors.Is(err, domain.ErrDeclineReasonNotFound) {
+			messageResponse(c, http.StatusBadRequest, "unknown decline reason")
+
+			return
+		}
+
 		if errors.Is(err, domain.ErrFlatNotFound) {
 			messageResponse(c, http.StatusNotFound, "flat not found")
 
 			return
 		}
 
+		if errors.Is(err, domain.ErrFlatNotClaimed) {
+			messageResponse(c, http.StatusConflict, "flat must be claimed by moderator first")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			messageResponse(c, http.StatusConflict, "invalid transition")
+
+			return
+		}
+
 		messageResponse(c, http.StatusInternalServerError, "internal server error")
 
 		return
@@ -109,3 +286,77 @@ func (h *Handler) updateFlat(c *gin.Context) {
 
 	c.JSON(http.StatusOK, DataResponse[domain.Flat]{Data: resp})
 }
+
+// @Summary		Get Flat Transitions
+// @Security		ModeratorsAuth
+// @Description	get statuses flat may move to from its current status
+// @ID				getFlatTransitions
+// @Tags			flat
+// @Produce		json
+// @Param			id	path		string	true	"flat id"
+// @Success		200	{object}	DataResponse[domain.FlatTransitions]
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/flat/:id/transitions [get]
+func (h *Handler) getFlatTransitions(c *gin.Context) {
+	flatId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid flat id type")
+
+		return
+	}
+
+	resp, err := h.services.Flats.Transitions(c, flatId)
+	if err != nil {
+		if errors.Is(err, domain.ErrFlatNotFound) {
+			messageResponse(c, http.StatusNotFound, "flat not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.FlatTransitions]{Data: resp})
+}
+
+// @Summary		Get Flat History
+// @Security		ModeratorsAuth
+// @Description	get status changes of flat, oldest first, with users made them and decline reasons
+// @ID				getFlatHistory
+// @Tags			flat
+// @Produce		json
+// @Param			id	path		string	true	"flat id"
+// @Success		200	{object}	DataResponse[[]domain.FlatStatusChange]
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/flat/:id/history [get]
+func (h *Handler) getFlatHistory(c *gin.Context) {
+	flatId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid flat id type")
+
+		return
+	}
+
+	resp, err := h.services.Flats.History(c, flatId)
+	if err != nil {
+		if errors.Is(err, domain.ErrFlatNotFound) {
+			messageResponse(c, http.StatusNotFound, "flat not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.FlatStatusChange]{Data: resp})
+}
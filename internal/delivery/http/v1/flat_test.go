@@ -10,15 +10,19 @@ import (
 	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func Test_FlatCreate(t *testing.T) {
 	type mockBehaviour func(s *mocks_service.MockFlats, inp dtos.FlatCreateInput)
 
+	userId := uuid.New()
+
 	tests := []struct {
 		name               string
 		inpBody            string
@@ -32,7 +36,7 @@ func Test_FlatCreate(t *testing.T) {
 			inpBody: `{"flat_number": 256, "house_id": 1, "price": 1000, "rooms": 3}`,
 			inpFlat: dtos.FlatCreateInput{FlatNumber: 256, HouseId: 1, Price: 1000, Rooms: 3},
 			mockBehaviour: func(s *mocks_service.MockFlats, inp dtos.FlatCreateInput) {
-				s.EXPECT().Create(context.Background(), inp).Return(domain.Flat{
+				s.EXPECT().Create(context.Background(), userId, inp).Return(domain.Flat{
 					ID:         1,
 					FlatNumber: 256,
 					Price:      1000,
@@ -56,7 +60,7 @@ func Test_FlatCreate(t *testing.T) {
 			inpBody: `{"flat_number": 256, "house_id": 1, "price": 1000, "rooms": 3}`,
 			inpFlat: dtos.FlatCreateInput{FlatNumber: 256, HouseId: 1, Price: 1000, Rooms: 3},
 			mockBehaviour: func(s *mocks_service.MockFlats, inp dtos.FlatCreateInput) {
-				s.EXPECT().Create(context.Background(), inp).Return(domain.Flat{}, domain.ErrFlatAlreadyExists)
+				s.EXPECT().Create(context.Background(), userId, inp).Return(domain.Flat{}, domain.ErrFlatAlreadyExists)
 			},
 			expectedStatusCode: http.StatusConflict,
 			expectedReqBody:    `{"message":"flat already exists"}`,
@@ -66,7 +70,7 @@ func Test_FlatCreate(t *testing.T) {
 			inpBody: `{"flat_number": 256, "house_id": 100, "price": 1000, "rooms": 3}`,
 			inpFlat: dtos.FlatCreateInput{FlatNumber: 256, HouseId: 100, Price: 1000, Rooms: 3},
 			mockBehaviour: func(s *mocks_service.MockFlats, inp dtos.FlatCreateInput) {
-				s.EXPECT().Create(context.Background(), inp).Return(domain.Flat{}, domain.ErrHouseNotFound)
+				s.EXPECT().Create(context.Background(), userId, inp).Return(domain.Flat{}, domain.ErrHouseNotFound)
 			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedReqBody:    `{"message":"house not found"}`,
@@ -76,7 +80,7 @@ func Test_FlatCreate(t *testing.T) {
 			inpBody: `{"flat_number": 256, "house_id": 1, "price": 1000, "rooms": 3}`,
 			inpFlat: dtos.FlatCreateInput{FlatNumber: 256, HouseId: 1, Price: 1000, Rooms: 3},
 			mockBehaviour: func(s *mocks_service.MockFlats, inp dtos.FlatCreateInput) {
-				s.EXPECT().Create(context.Background(), inp).Return(domain.Flat{}, errors.New("internal server error"))
+				s.EXPECT().Create(context.Background(), userId, inp).Return(domain.Flat{}, errors.New("internal server error"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 			expectedReqBody:    `{"message":"internal server error"}`,
@@ -96,7 +100,9 @@ func Test_FlatCreate(t *testing.T) {
 			handler := NewHandler(services, nil)
 
 			r := gin.New()
-			r.POST("/api/flat/create", handler.createFlat)
+			r.POST("/api/flat/create", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+			}, handler.createFlat)
 
 			w := httptest.NewRecorder()
 
@@ -109,3 +115,500 @@ func Test_FlatCreate(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetFlatById(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockFlats)
+
+	userId := uuid.New()
+
+	tests := []struct {
+		name               string
+		id                 string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().GetById(gomock.Any(), 1, userId, domain.UserTypeClient).Return(domain.FlatDetails{
+					Flat: domain.Flat{
+						ID:         1,
+						FlatNumber: 256,
+						Price:      1000,
+						Rooms:      3,
+						Status:     domain.StatusCreated,
+						CreatedBy:  &userId,
+					},
+					House: domain.HouseSummary{ID: 2, Address: "test address", Year: 2001, Developer: "developer"},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":{"ID":1,"FlatNumber":256,"Price":1000,"Rooms":3,"Status":"created",` +
+				`"House":{"ID":2,"Address":"test address","Year":2001,"Developer":"developer"}}}`,
+		},
+		{
+			name:               "Invalid id",
+			id:                 "one",
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid flat id type"}`,
+		},
+		{
+			name: "Not found",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().GetById(gomock.Any(), 1, userId, domain.UserTypeClient).Return(domain.FlatDetails{}, domain.ErrFlatNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"flat not found"}`,
+		},
+		{
+			name: "Internal server error",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().GetById(gomock.Any(), 1, userId, domain.UserTypeClient).Return(domain.FlatDetails{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			flats := mocks_service.NewMockFlats(c)
+			tt.mockBehaviour(flats)
+
+			handler := NewHandler(&service.Services{Flats: flats}, nil)
+
+			r := gin.New()
+			r.GET("/api/flat/:id", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+				c.Set(userTypeCtx, domain.UserTypeClient.String())
+			}, handler.getFlatById)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/flat/"+tt.id, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_EditFlat(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockFlats)
+
+	userId := uuid.New()
+	price := 2000
+
+	tests := []struct {
+		name               string
+		id                 string
+		inpBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			id:      "1",
+			inpBody: `{"price": 2000}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Edit(gomock.Any(), 1, userId, domain.UserTypeClient, dtos.FlatEditInput{Price: &price}).Return(domain.Flat{
+					ID:         1,
+					FlatNumber: 256,
+					Price:      2000,
+					Rooms:      3,
+					Status:     domain.StatusCreated,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":{"ID":1,"FlatNumber":256,"Price":2000,"Rooms":3,"Status":"created"}}`,
+		},
+		{
+			name:               "Invalid id",
+			id:                 "one",
+			inpBody:            `{"price": 2000}`,
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid flat id type"}`,
+		},
+		{
+			name:               "Invalid price",
+			id:                 "1",
+			inpBody:            `{"price": -1}`,
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid input body"}`,
+		},
+		{
+			name:               "Nothing to edit",
+			id:                 "1",
+			inpBody:            `{}`,
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"nothing to edit"}`,
+		},
+		{
+			name:    "Not found",
+			id:      "1",
+			inpBody: `{"price": 2000}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Edit(gomock.Any(), 1, userId, domain.UserTypeClient, gomock.Any()).Return(domain.Flat{}, domain.ErrFlatNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"flat not found"}`,
+		},
+		{
+			name:    "Not creator",
+			id:      "1",
+			inpBody: `{"price": 2000}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Edit(gomock.Any(), 1, userId, domain.UserTypeClient, gomock.Any()).Return(domain.Flat{}, domain.ErrFlatEditForbidden)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedReqBody:    `{"message":"flat may be edited by its creator only"}`,
+		},
+		{
+			name:    "Being moderated",
+			id:      "1",
+			inpBody: `{"price": 2000}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Edit(gomock.Any(), 1, userId, domain.UserTypeClient, gomock.Any()).Return(domain.Flat{}, domain.ErrFlatModerated)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedReqBody:    `{"message":"flat is being moderated"}`,
+		},
+		{
+			name:    "Invalid transition",
+			id:      "1",
+			inpBody: `{"price": 2000}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Edit(gomock.Any(), 1, userId, domain.UserTypeClient, gomock.Any()).Return(domain.Flat{}, domain.ErrInvalidTransition)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedReqBody:    `{"message":"invalid transition"}`,
+		},
+		{
+			name:    "Internal server error",
+			id:      "1",
+			inpBody: `{"price": 2000}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Edit(gomock.Any(), 1, userId, domain.UserTypeClient, gomock.Any()).Return(domain.Flat{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			flats := mocks_service.NewMockFlats(c)
+			tt.mockBehaviour(flats)
+
+			handler := NewHandler(&service.Services{Flats: flats}, nil)
+
+			r := gin.New()
+			r.PATCH("/api/flat/:id", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+				c.Set(userTypeCtx, domain.UserTypeClient.String())
+			}, handler.editFlat)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/api/flat/"+tt.id, bytes.NewBufferString(tt.inpBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_UpdateFlat(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockFlats)
+
+	moderatorId := uuid.New()
+
+	tests := []struct {
+		name               string
+		inpBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			inpBody: `{"flat_id": 1, "status": "approved"}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Update(gomock.Any(), moderatorId, dtos.FlatUpdateInput{FlatId: 1, Status: domain.StatusApproved}).Return(domain.Flat{
+					ID:         1,
+					FlatNumber: 256,
+					Price:      1000,
+					Rooms:      3,
+					Status:     domain.StatusApproved,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":{"ID":1,"FlatNumber":256,"Price":1000,"Rooms":3,"Status":"approved"}}`,
+		},
+		{
+			name:               "Invalid status",
+			inpBody:            `{"flat_id": 1, "status": "sold"}`,
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid status"}`,
+		},
+		{
+			name:    "Declined",
+			inpBody: `{"flat_id": 1, "status": "declined", "reason": "wrong_price", "comment": "price is per month"}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				inp := dtos.FlatUpdateInput{FlatId: 1, Status: domain.StatusDeclined, Reason: "wrong_price", Comment: "price is per month"}
+				s.EXPECT().Update(gomock.Any(), moderatorId, inp).Return(domain.Flat{
+					ID:         1,
+					FlatNumber: 256,
+					Price:      1000,
+					Rooms:      3,
+					Status:     domain.StatusDeclined,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":{"ID":1,"FlatNumber":256,"Price":1000,"Rooms":3,"Status":"declined"}}`,
+		},
+		{
+			name:               "Declined without reason",
+			inpBody:            `{"flat_id": 1, "status": "declined", "comment": "price is per month"}`,
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"reason and comment are required when declining"}`,
+		},
+		{
+			name:               "Reason when approving",
+			inpBody:            `{"flat_id": 1, "status": "approved", "reason": "wrong_price"}`,
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"reason and comment are allowed when declining only"}`,
+		},
+		{
+			name:    "Unknown decline reason",
+			inpBody: `{"flat_id": 1, "status": "declined", "reason": "unknown", "comment": "price is per month"}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Update(gomock.Any(), moderatorId, gomock.Any()).Return(domain.Flat{}, domain.ErrDeclineReasonNotFound)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"unknown decline reason"}`,
+		},
+		{
+			name:    "Not claimed",
+			inpBody: `{"flat_id": 1, "status": "approved"}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Update(gomock.Any(), moderatorId, dtos.FlatUpdateInput{FlatId: 1, Status: domain.StatusApproved}).Return(domain.Flat{}, domain.ErrFlatNotClaimed)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedReqBody:    `{"message":"flat must be claimed by moderator first"}`,
+		},
+		{
+			name:    "Invalid transition",
+			inpBody: `{"flat_id": 1, "status": "created"}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Update(gomock.Any(), moderatorId, dtos.FlatUpdateInput{FlatId: 1, Status: domain.StatusCreated}).Return(domain.Flat{}, domain.ErrInvalidTransition)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedReqBody:    `{"message":"invalid transition"}`,
+		},
+		{
+			name:    "Not found",
+			inpBody: `{"flat_id": 1, "status": "approved"}`,
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Update(gomock.Any(), moderatorId, dtos.FlatUpdateInput{FlatId: 1, Status: domain.StatusApproved}).Return(domain.Flat{}, domain.ErrFlatNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"flat not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			flats := mocks_service.NewMockFlats(c)
+			tt.mockBehaviour(flats)
+
+			handler := NewHandler(&service.Services{Flats: flats}, nil)
+
+			r := gin.New()
+			r.POST("/api/flat/update", func(c *gin.Context) {
+				c.Set(userIdCtx, moderatorId.String())
+			}, handler.updateFlat)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/flat/update", bytes.NewBufferString(tt.inpBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_GetFlatTransitions(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockFlats)
+
+	tests := []struct {
+		name               string
+		id                 string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Transitions(gomock.Any(), 1).Return(domain.FlatTransitions{
+					FlatID: 1,
+					Status: domain.StatusCreated,
+					Next:   domain.StatusCreated.NextStatuses(),
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":{"FlatID":1,"Status":"created","Next":["approved","declined","created"]}}`,
+		},
+		{
+			name:               "Invalid id",
+			id:                 "one",
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid flat id type"}`,
+		},
+		{
+			name: "Not found",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().Transitions(gomock.Any(), 1).Return(domain.FlatTransitions{}, domain.ErrFlatNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"flat not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			flats := mocks_service.NewMockFlats(c)
+			tt.mockBehaviour(flats)
+
+			handler := NewHandler(&service.Services{Flats: flats}, nil)
+
+			r := gin.New()
+			r.GET("/api/flat/:id/transitions", handler.getFlatTransitions)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/flat/"+tt.id+"/transitions", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_GetFlatHistory(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockFlats)
+
+	moderatorId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		id                 string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().History(gomock.Any(), 1).Return([]domain.FlatStatusChange{
+					{
+						NewStatus: domain.StatusCreated,
+						CreatedAt: time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC),
+					},
+					{
+						OldStatus: domain.StatusCreated,
+						NewStatus: domain.StatusDeclined,
+						Actor:     &moderatorId,
+						Reason:    "wrong_price",
+						CreatedAt: time.Date(2024, 8, 20, 12, 6, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":[{"NewStatus":"created","CreatedAt":"2024-08-20T12:00:00Z"},` +
+				`{"OldStatus":"created","NewStatus":"declined","Actor":"3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c","Reason":"wrong_price","CreatedAt":"2024-08-20T12:06:00Z"}]}`,
+		},
+		{
+			name:               "Invalid id",
+			id:                 "one",
+			mockBehaviour:      func(s *mocks_service.MockFlats) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid flat id type"}`,
+		},
+		{
+			name: "Not found",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().History(gomock.Any(), 1).Return(nil, domain.ErrFlatNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"flat not found"}`,
+		},
+		{
+			name: "Internal server error",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockFlats) {
+				s.EXPECT().History(gomock.Any(), 1).Return(nil, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			flats := mocks_service.NewMockFlats(c)
+			tt.mockBehaviour(flats)
+
+			handler := NewHandler(&service.Services{Flats: flats}, nil)
+
+			r := gin.New()
+			r.GET("/api/flat/:id/history", handler.getFlatHistory)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/flat/"+tt.id+"/history", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
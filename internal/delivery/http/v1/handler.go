@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"expvar"
 	_ "github.com/dzhordano/avito-bootcamp2024/docs"
 	"github.com/dzhordano/avito-bootcamp2024/internal/service"
 	"github.com/dzhordano/avito-bootcamp2024/pkg/auth"
@@ -25,5 +26,19 @@ func (h *Handler) Init(api *gin.RouterGroup) {
 		h.initAuthRoutes(v1)
 		h.initHouseRoutes(v1)
 		h.initFlatRoutes(v1)
+		h.initModerationRoutes(v1)
+		h.initImportRoutes(v1)
+		h.initUserRoutes(v1)
+		h.initNotificationsRoutes(v1)
+		h.initWebhooksRoutes(v1)
+		h.initUnsubscribeRoutes(v1)
+	}
+}
+
+// InitDebugRoutes mounts debug endpoints, e.g. metrics, available to moderators only.
+func (h *Handler) InitDebugRoutes(router gin.IRouter) {
+	debug := router.Group("/debug", h.isModerator)
+	{
+		debug.GET("/vars", gin.WrapH(expvar.Handler()))
 	}
 }
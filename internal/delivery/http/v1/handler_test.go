@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_DebugVars(t *testing.T) {
+	tokensManager := auth.NewJWTManager("secret", time.Hour)
+
+	token := func(userType domain.UserType) string {
+		token, err := tokensManager.GenerateJWT(uuid.NewString(), userType.String())
+		assert.NoError(t, err)
+
+		return token
+	}
+
+	tests := []struct {
+		name               string
+		token              string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Moderator",
+			token:              token(domain.UserTypeModerator),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Client",
+			token:              token(domain.UserTypeClient),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "No token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(nil, tokensManager)
+
+			r := gin.New()
+			handler.InitDebugRoutes(r)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/debug/vars", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -14,8 +14,11 @@ func (h *Handler) initHouseRoutes(api *gin.RouterGroup) {
 	{
 		authorized := house.Group("/", h.isAuthorized)
 		{
+			authorized.GET("/export", h.exportHouses)
 			authorized.GET("/:id", h.getHouseById)
+			authorized.GET("/:id/export", h.exportHouseFlats)
 			authorized.POST("/:id/subscribe", h.postSubscribeToHouse)
+			authorized.DELETE("/:id/subscribe", h.deleteSubscribeToHouse)
 
 			moderatorsOnly := authorized.Group("/", h.isModerator)
 			{
@@ -28,14 +31,18 @@ func (h *Handler) initHouseRoutes(api *gin.RouterGroup) {
 // @Summary		Get House By Id
 // @Security		ClientsAuth
 // @Security		ModeratorsAuth
-// @Description	get all flats that are located at house
+// @Description	get all flats that are located at house. Clients see approved flats and their own ones in any status,
+// @Description	supports conditional requests with ETag and Last-Modified validators
 // @ID				getHouseById
 // @Tags			house
 // @Accept			json
 // @Produce		json
-// @Param			id	path		string	true	"house id"
-// @Success		200	{object}	DataResponse[[]domain.Flat]
-// @Failure		400	{object}	response
+// @Param			id					path		string	true	"house id"
+// @Param			If-None-Match		header		string	false	"ETag of cached response"
+// @Param			If-Modified-Since	header		string	false	"Last-Modified of cached response"
+// @Success		200					{object}	DataResponse[[]domain.Flat]
+// @Success		304					"cached response is fresh"
+// @Failure		400					{object}	response
 // @Failure		404	{object}	response
 // @Failure		500	{object}	response
 // @Router			/house/:id [get]
@@ -54,6 +61,24 @@ func (h *Handler) getHouseById(c *gin.Context) {
 		return
 	}
 
+	// Freshness is checked without loading flats.
+	modified, err := h.services.Houses.LastModified(c, houseIdInt)
+	if err != nil {
+		if errors.Is(err, domain.ErrHouseNotFound) {
+			messageResponse(c, http.StatusNotFound, "house not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	if checkNotModified(c, houseETag(houseIdInt, c.GetString(userTypeCtx), c.GetString(userIdCtx), modified), modified) {
+		return
+	}
+
 	resp, err := h.services.Houses.GetById(c, houseIdInt)
 	if err != nil {
 
@@ -74,13 +99,15 @@ func (h *Handler) getHouseById(c *gin.Context) {
 // @Summary		Subscribe To House With Id
 // @Security		ClientsAuth
 // @Security		ModeratorsAuth
-// @Description	subscribe user to house specifying his email in body
+// @Description	subscribe user to house specifying his email in body,
+// @Description	optional rooms and price ranges restrict notifications to matching flats only,
+// @Description	delivery is "immediate" (default), "daily" or "weekly" digest of new flats
 // @ID				postSubscribeToHouse
 // @Tags			house
 // @Accept			json
 // @Produce		json
 // @Param			id		path		string						true	"house id"
-// @Param			input	body		dtos.HouseSubscribeInput	true	"User email to subscribe"
+// @Param			input	body		dtos.HouseSubscribeInput	true	"User email and filters to subscribe"
 // @Success		200		{string}	string						"ok"
 // @Failure		400		{object}	response
 // @Failure		404		{object}	response
@@ -102,18 +129,24 @@ func (h *Handler) postSubscribeToHouse(c *gin.Context) {
 		return
 	}
 
-	var email dtos.HouseSubscribeInput
-	if err := c.BindJSON(&email); err != nil {
+	var inp dtos.HouseSubscribeInput
+	if err := c.BindJSON(&inp); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, "invalid input body")
 
 		return
 	}
 
-	err = h.services.Houses.Subscribe(c, houseIdInt, email.Email)
+	if err := inp.Validate(); err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	err = h.services.Houses.Subscribe(c, houseIdInt, inp)
 	if err != nil {
 
-		if errors.Is(err, domain.ErrHouseNotFound) {
-			messageResponse(c, http.StatusNotFound, "house not found")
+		if errors.Is(err, domain.ErrUserOrHouseNotFound) {
+			messageResponse(c, http.StatusNotFound, "user or house not found")
 
 			return
 		}
@@ -132,6 +165,67 @@ func (h *Handler) postSubscribeToHouse(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+// @Summary		Unsubscribe From House With Id
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	unsubscribe user with email specified in body from house, the email must be of the user
+// @ID				deleteSubscribeToHouse
+// @Tags			house
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string						true	"house id"
+// @Param			input	body		dtos.HouseUnsubscribeInput	true	"User email to unsubscribe"
+// @Success		200		{string}	string						"ok"
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		403		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/house/:id/subscribe [delete]
+func (h *Handler) deleteSubscribeToHouse(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	houseId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid house id type")
+
+		return
+	}
+
+	var inp dtos.HouseUnsubscribeInput
+	if err := c.BindJSON(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid input body")
+
+		return
+	}
+
+	err = h.services.Houses.Unsubscribe(c, houseId, userId, inp.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrForeignSubscription) {
+			messageResponse(c, http.StatusForbidden, "subscription belongs to another user")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			messageResponse(c, http.StatusNotFound, "subscription not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
 // @Summary		Create House
 // @Security		ModeratorsAuth
 // @Description	create house with address, year and (perhaps) developer
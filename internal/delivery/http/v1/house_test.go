@@ -7,18 +7,25 @@ import (
 	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_GetHouseById(t *testing.T) {
 	type mockBehaviour func(s *mocks_service.MockHouses, id int)
 
+	modified := time.Date(2024, 8, 20, 12, 0, 0, 500, time.UTC)
+	etag := houseETag(1, "", "", modified)
+
 	tests := []struct {
 		name               string
 		id                 int
+		headers            map[string]string
 		mockBehaviour      mockBehaviour
 		expectedStatusCode int
 		expectedReqBody    string
@@ -27,6 +34,10 @@ func Test_GetHouseById(t *testing.T) {
 			name: "OK",
 			id:   1,
 			mockBehaviour: func(s *mocks_service.MockHouses, id int) {
+				s.
+					EXPECT().
+					LastModified(gomock.Any(), id).
+					Return(modified, nil)
 				s.
 					EXPECT().
 					GetById(gomock.Any(), id).
@@ -44,13 +55,57 @@ func Test_GetHouseById(t *testing.T) {
 			expectedReqBody:    `{"data":[{"ID":1,"FlatNumber":256,"Price":1000,"Rooms":3,"Status":"created"}]}`,
 		},
 		{
-			name: "Not found",
+			name:    "Not modified by etag",
+			id:      1,
+			headers: map[string]string{"If-None-Match": `W/"other", ` + etag},
+			mockBehaviour: func(s *mocks_service.MockHouses, id int) {
+				s.
+					EXPECT().
+					LastModified(gomock.Any(), id).
+					Return(modified, nil)
+			},
+			expectedStatusCode: http.StatusNotModified,
+		},
+		{
+			name: "Modified by etag",
 			id:   1,
+			headers: map[string]string{
+				"If-None-Match":     `W/"other"`,
+				"If-Modified-Since": modified.Format(http.TimeFormat),
+			},
 			mockBehaviour: func(s *mocks_service.MockHouses, id int) {
+				s.
+					EXPECT().
+					LastModified(gomock.Any(), id).
+					Return(modified, nil)
 				s.
 					EXPECT().
 					GetById(gomock.Any(), id).
-					Return(nil, domain.ErrHouseNotFound)
+					Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":null}`,
+		},
+		{
+			name:    "Not modified since",
+			id:      1,
+			headers: map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)},
+			mockBehaviour: func(s *mocks_service.MockHouses, id int) {
+				s.
+					EXPECT().
+					LastModified(gomock.Any(), id).
+					Return(modified, nil)
+			},
+			expectedStatusCode: http.StatusNotModified,
+		},
+		{
+			name: "Not found",
+			id:   1,
+			mockBehaviour: func(s *mocks_service.MockHouses, id int) {
+				s.
+					EXPECT().
+					LastModified(gomock.Any(), id).
+					Return(time.Time{}, domain.ErrHouseNotFound)
 			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedReqBody:    `{"message":"house not found"}`,
@@ -58,6 +113,10 @@ func Test_GetHouseById(t *testing.T) {
 		{
 			name: "Internal server error",
 			mockBehaviour: func(s *mocks_service.MockHouses, id int) {
+				s.
+					EXPECT().
+					LastModified(gomock.Any(), gomock.Any()).
+					Return(modified, nil)
 				s.
 					EXPECT().
 					GetById(gomock.Any(), gomock.Any()).
@@ -88,6 +147,111 @@ func Test_GetHouseById(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			req, _ := http.NewRequest("GET", "/api/house/1", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_DeleteSubscribeToHouse(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockHouses, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		id                 string
+		userId             string
+		inpBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			id:      "1",
+			userId:  userId.String(),
+			inpBody: `{"email":"test@mail.ru"}`,
+			mockBehaviour: func(s *mocks_service.MockHouses, userId uuid.UUID) {
+				s.EXPECT().Unsubscribe(gomock.Any(), 1, userId, "test@mail.ru").Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    ``,
+		},
+		{
+			name:               "No identity",
+			id:                 "1",
+			userId:             "",
+			inpBody:            `{"email":"test@mail.ru"}`,
+			mockBehaviour:      func(s *mocks_service.MockHouses, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedReqBody:    `{"message":"user identity required"}`,
+		},
+		{
+			name:               "Invalid id",
+			id:                 "one",
+			userId:             userId.String(),
+			inpBody:            `{"email":"test@mail.ru"}`,
+			mockBehaviour:      func(s *mocks_service.MockHouses, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid house id type"}`,
+		},
+		{
+			name:               "Empty email",
+			id:                 "1",
+			userId:             userId.String(),
+			inpBody:            `{}`,
+			mockBehaviour:      func(s *mocks_service.MockHouses, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid input body"}`,
+		},
+		{
+			name:    "Email of another user",
+			id:      "1",
+			userId:  userId.String(),
+			inpBody: `{"email":"someone@mail.ru"}`,
+			mockBehaviour: func(s *mocks_service.MockHouses, userId uuid.UUID) {
+				s.EXPECT().Unsubscribe(gomock.Any(), 1, userId, "someone@mail.ru").Return(domain.ErrForeignSubscription)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedReqBody:    `{"message":"subscription belongs to another user"}`,
+		},
+		{
+			name:    "Not subscribed",
+			id:      "1",
+			userId:  userId.String(),
+			inpBody: `{"email":"test@mail.ru"}`,
+			mockBehaviour: func(s *mocks_service.MockHouses, userId uuid.UUID) {
+				s.EXPECT().Unsubscribe(gomock.Any(), 1, userId, "test@mail.ru").Return(domain.ErrSubscriptionNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"subscription not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			houses := mocks_service.NewMockHouses(c)
+			tt.mockBehaviour(houses, userId)
+
+			handler := NewHandler(&service.Services{Houses: houses}, nil)
+
+			r := gin.New()
+			r.DELETE("/api/house/:id/subscribe", func(c *gin.Context) {
+				c.Set(userIdCtx, tt.userId)
+			}, handler.deleteSubscribeToHouse)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/house/"+tt.id+"/subscribe", strings.NewReader(tt.inpBody))
 
 			r.ServeHTTP(w, req)
 
@@ -96,3 +260,115 @@ func Test_GetHouseById(t *testing.T) {
 		})
 	}
 }
+
+func Test_Unsubscribe(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockHouses)
+
+	tests := []struct {
+		name               string
+		method             string
+		token              string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedContains   string
+	}{
+		{
+			name:   "Confirm",
+			method: "GET",
+			token:  "valid",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().VerifyUnsubscribe(gomock.Any(), "valid").
+					Return(domain.Unsubscription{Email: "test@mail.ru", HouseIDs: []int{1, 2}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedContains: "<p>Unsubscribe test@mail.ru from notifications about houses 1, 2?</p>\n" +
+				`<form method="post" action="?token=valid"><button type="submit">Unsubscribe</button></form>`,
+		},
+		{
+			name:   "Confirmed",
+			method: "POST",
+			token:  "valid",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().UnsubscribeByToken(gomock.Any(), "valid").
+					Return(domain.Unsubscription{Email: "test@mail.ru", HouseIDs: []int{1, 2}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedContains:   "<p>test@mail.ru is unsubscribed from notifications about houses 1, 2.</p>",
+		},
+		{
+			name:   "One-click",
+			method: "POST",
+			token:  "valid",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().UnsubscribeByToken(gomock.Any(), "valid").
+					Return(domain.Unsubscription{Email: "test@mail.ru", HouseIDs: []int{1}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedContains:   "<p>test@mail.ru is unsubscribed from notifications about house 1.</p>",
+		},
+		{
+			name:   "Invalid token",
+			method: "GET",
+			token:  "forged",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().VerifyUnsubscribe(gomock.Any(), "forged").Return(domain.Unsubscription{}, domain.ErrInvalidUnsubscribe)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedContains:   "<p>Unsubscribe link is invalid.</p>",
+		},
+		{
+			name:   "Expired token",
+			method: "GET",
+			token:  "expired",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().VerifyUnsubscribe(gomock.Any(), "expired").Return(domain.Unsubscription{}, domain.ErrUnsubscribeExpired)
+			},
+			expectedStatusCode: http.StatusGone,
+			expectedContains:   "<p>Unsubscribe link has expired",
+		},
+		{
+			name:   "Expired token one-click",
+			method: "POST",
+			token:  "expired",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().UnsubscribeByToken(gomock.Any(), "expired").Return(domain.Unsubscription{}, domain.ErrUnsubscribeExpired)
+			},
+			expectedStatusCode: http.StatusGone,
+			expectedContains:   "<p>Unsubscribe link has expired",
+		},
+		{
+			name:   "Internal server error",
+			method: "POST",
+			token:  "valid",
+			mockBehaviour: func(s *mocks_service.MockHouses) {
+				s.EXPECT().UnsubscribeByToken(gomock.Any(), "valid").Return(domain.Unsubscription{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedContains:   "<p>Something went wrong, please try again later.</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			houses := mocks_service.NewMockHouses(c)
+			tt.mockBehaviour(houses)
+
+			handler := NewHandler(&service.Services{Houses: houses}, nil)
+
+			r := gin.New()
+			handler.initUnsubscribeRoutes(r.Group("/api"))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/unsubscribe?token="+tt.token, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Body.String(), tt.expectedContains)
+		})
+	}
+}
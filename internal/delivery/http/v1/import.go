@@ -0,0 +1,188 @@
+package v1
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const maxImportFileSize = 32 << 20
+
+func (h *Handler) initImportRoutes(api *gin.RouterGroup) {
+	imports := api.Group("/import")
+	{
+		moderatorsOnly := imports.Group("/", h.isModerator)
+		{
+			moderatorsOnly.POST("/houses", h.importHouses)
+			moderatorsOnly.POST("/flats", h.importFlats)
+			moderatorsOnly.GET("/:id", h.getImportJob)
+		}
+	}
+}
+
+// @Summary		Import Houses
+// @Security		ModeratorsAuth
+// @Description	import houses from CSV or JSON file (raw body or multipart 'file' field) in background
+// @ID				importHouses
+// @Tags			import
+// @Accept			json,text/csv,multipart/form-data
+// @Produce		json
+// @Param			format		query		string	false	"file format, detected from content type if omitted"	Enums(csv, json)
+// @Param			mode		query		string	false	"import mode"	Enums(transaction, chunks)
+// @Param			chunk_size	query		int		false	"rows per transaction in chunks mode"
+// @Param			dry_run		query		bool	false	"validate without saving"
+// @Success		202			{object}	DataResponse[domain.ImportJob]
+// @Failure		400			{object}	response
+// @Failure		401			{object}	response
+// @Failure		500			{object}	response
+// @Failure		503			{object}	response
+// @Router			/import/houses [post]
+func (h *Handler) importHouses(c *gin.Context) {
+	h.startImport(c, domain.ImportEntityHouses)
+}
+
+// @Summary		Import Flats
+// @Security		ModeratorsAuth
+// @Description	import flats from CSV or JSON file (raw body or multipart 'file' field) in background,
+// @Description	every row is validated with the same rules as /flat/create input
+// @ID				importFlats
+// @Tags			import
+// @Accept			json,text/csv,multipart/form-data
+// @Produce		json
+// @Param			format		query		string	false	"file format, detected from content type if omitted"	Enums(csv, json)
+// @Param			mode		query		string	false	"import mode"	Enums(transaction, chunks)
+// @Param			chunk_size	query		int		false	"rows per transaction in chunks mode"
+// @Param			dry_run		query		bool	false	"validate without saving"
+// @Success		202			{object}	DataResponse[domain.ImportJob]
+// @Failure		400			{object}	response
+// @Failure		401			{object}	response
+// @Failure		500			{object}	response
+// @Failure		503			{object}	response
+// @Router			/import/flats [post]
+func (h *Handler) importFlats(c *gin.Context) {
+	h.startImport(c, domain.ImportEntityFlats)
+}
+
+func (h *Handler) startImport(c *gin.Context, entity domain.ImportEntity) {
+	var inp dtos.ImportInput
+	if err := c.ShouldBindQuery(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid query params")
+
+		return
+	}
+	inp.Entity = entity
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	file, format, err := importFile(c)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid import file")
+
+		return
+	}
+	defer file.Close()
+
+	if inp.Format == "" {
+		inp.Format = format
+	}
+
+	inp = inp.WithDefaults()
+	if err := inp.Validate(); err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	resp, err := h.services.Imports.Start(c.Request.Context(), inp, file)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidImportFile) {
+			messageResponse(c, http.StatusBadRequest, "invalid import file")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrTooManyImports) {
+			messageResponse(c, http.StatusServiceUnavailable, "too many imports in progress, retry later")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusAccepted, DataResponse[domain.ImportJob]{Data: resp})
+}
+
+// importFile returns uploaded file and its format guessed from content type or file name.
+func importFile(c *gin.Context) (io.ReadCloser, domain.ImportFormat, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return file, formatFromName(header.Filename, header.Header.Get("Content-Type")), nil
+	}
+
+	return c.Request.Body, formatFromName("", c.ContentType()), nil
+}
+
+func formatFromName(filename, contentType string) domain.ImportFormat {
+	switch {
+	case contentType == "text/csv", strings.EqualFold(filepath.Ext(filename), ".csv"):
+		return domain.ImportFormatCSV
+	case contentType == "application/json", strings.EqualFold(filepath.Ext(filename), ".json"):
+		return domain.ImportFormatJSON
+	}
+
+	return ""
+}
+
+// @Summary		Get Import Job
+// @Security		ModeratorsAuth
+// @Description	get import job status with per-row error report
+// @ID				getImportJob
+// @Tags			import
+// @Produce		json
+// @Param			id	path		string	true	"job id"
+// @Success		200	{object}	DataResponse[domain.ImportJob]
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Router			/import/:id [get]
+func (h *Handler) getImportJob(c *gin.Context) {
+	jobId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid job id")
+
+		return
+	}
+
+	resp, err := h.services.Imports.GetJob(jobId)
+	if err != nil {
+		if errors.Is(err, domain.ErrImportJobNotFound) {
+			messageResponse(c, http.StatusNotFound, "import job not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.ImportJob]{Data: resp})
+}
@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_ImportFlats(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockImports, inp dtos.ImportInput)
+
+	jobId := uuid.MustParse("8c1f0b5e-8f3a-4a63-9c64-8a5e3b1f2d10")
+	createdAt := time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		query              string
+		contentType        string
+		inpBody            string
+		inpImport          dtos.ImportInput
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:        "OK",
+			query:       "?mode=chunks&chunk_size=50&dry_run=true",
+			contentType: "text/csv",
+			inpBody:     "flat_number,house_id,price,rooms\n1,1,1000,2\n",
+			inpImport: dtos.ImportInput{
+				Entity:    domain.ImportEntityFlats,
+				Format:    domain.ImportFormatCSV,
+				Mode:      domain.ImportModeChunks,
+				ChunkSize: 50,
+				DryRun:    true,
+			},
+			mockBehaviour: func(s *mocks_service.MockImports, inp dtos.ImportInput) {
+				s.EXPECT().Start(gomock.Any(), inp, gomock.Any()).Return(domain.ImportJob{
+					ID:        jobId,
+					Entity:    domain.ImportEntityFlats,
+					Mode:      domain.ImportModeChunks,
+					DryRun:    true,
+					Status:    domain.ImportStatusPending,
+					Total:     1,
+					CreatedAt: createdAt,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusAccepted,
+			expectedReqBody: `{"data":{"ID":"8c1f0b5e-8f3a-4a63-9c64-8a5e3b1f2d10","Entity":"flats","Mode":"chunks","DryRun":true,` +
+				`"Status":"pending","Total":1,"Succeeded":0,"Failed":0,"Errors":null,"Error":"","CreatedAt":"2024-08-20T12:00:00Z","FinishedAt":null}}`,
+		},
+		{
+			name:               "Unknown format",
+			contentType:        "text/plain",
+			inpBody:            "flat_number,house_id,price,rooms\n",
+			mockBehaviour:      func(s *mocks_service.MockImports, inp dtos.ImportInput) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid import format"}`,
+		},
+		{
+			name:               "Invalid mode",
+			query:              "?mode=all",
+			contentType:        "application/json",
+			inpBody:            "[]",
+			mockBehaviour:      func(s *mocks_service.MockImports, inp dtos.ImportInput) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid import mode"}`,
+		},
+		{
+			name:        "Invalid file",
+			contentType: "application/json",
+			inpBody:     "{",
+			inpImport: dtos.ImportInput{
+				Entity:    domain.ImportEntityFlats,
+				Format:    domain.ImportFormatJSON,
+				Mode:      domain.ImportModeTransaction,
+				ChunkSize: dtos.DefaultImportChunkSize,
+			},
+			mockBehaviour: func(s *mocks_service.MockImports, inp dtos.ImportInput) {
+				s.EXPECT().Start(gomock.Any(), inp, gomock.Any()).Return(domain.ImportJob{}, domain.ErrInvalidImportFile)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid import file"}`,
+		},
+		{
+			name:        "Too many imports",
+			contentType: "application/json",
+			inpBody:     "[]",
+			inpImport: dtos.ImportInput{
+				Entity:    domain.ImportEntityFlats,
+				Format:    domain.ImportFormatJSON,
+				Mode:      domain.ImportModeTransaction,
+				ChunkSize: dtos.DefaultImportChunkSize,
+			},
+			mockBehaviour: func(s *mocks_service.MockImports, inp dtos.ImportInput) {
+				s.EXPECT().Start(gomock.Any(), inp, gomock.Any()).Return(domain.ImportJob{}, domain.ErrTooManyImports)
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedReqBody:    `{"message":"too many imports in progress, retry later"}`,
+		},
+		{
+			name:        "Internal server error",
+			contentType: "application/json",
+			inpBody:     "[]",
+			inpImport: dtos.ImportInput{
+				Entity:    domain.ImportEntityFlats,
+				Format:    domain.ImportFormatJSON,
+				Mode:      domain.ImportModeTransaction,
+				ChunkSize: dtos.DefaultImportChunkSize,
+			},
+			mockBehaviour: func(s *mocks_service.MockImports, inp dtos.ImportInput) {
+				s.EXPECT().Start(gomock.Any(), inp, gomock.Any()).Return(domain.ImportJob{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			imports := mocks_service.NewMockImports(c)
+			tt.mockBehaviour(imports, tt.inpImport)
+
+			services := &service.Services{
+				Imports: imports,
+			}
+			handler := NewHandler(services, nil)
+
+			r := gin.New()
+			r.POST("/api/import/flats", handler.importFlats)
+
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest("POST", "/api/import/flats"+tt.query, bytes.NewBufferString(tt.inpBody))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_GetImportJob(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	imports := mocks_service.NewMockImports(c)
+	imports.EXPECT().GetJob(gomock.Any()).Return(domain.ImportJob{}, domain.ErrImportJobNotFound)
+
+	handler := NewHandler(&service.Services{Imports: imports}, nil)
+
+	r := gin.New()
+	r.GET("/api/import/:id", handler.getImportJob)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/import/"+uuid.NewString(), nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"import job not found"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/import/not-uuid", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
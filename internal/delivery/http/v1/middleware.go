@@ -2,7 +2,9 @@ package v1
 
 import (
 	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/auth"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
 	"strings"
 )
@@ -11,22 +13,24 @@ const (
 	authorizationHeader = "Authorization"
 
 	userTypeCtx = "user-type"
+	userIdCtx   = "user-id"
 )
 
 func (h *Handler) isAuthorized(c *gin.Context) {
-	userType, err := h.parseAuthHeader(c)
+	claims, err := h.parseAuthHeader(c)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 			"message": "invalid auth token",
 		})
 	}
 
-	c.Set(userTypeCtx, userType)
+	c.Set(userTypeCtx, claims.UserType)
+	c.Set(userIdCtx, claims.UserID)
 }
 
 func (h *Handler) isModerator(c *gin.Context) {
 	// Check if the auth_tokens is a moderator auth_tokens
-	userType, err := h.parseAuthHeader(c)
+	claims, err := h.parseAuthHeader(c)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 			"message": "invalid auth token",
@@ -35,28 +39,34 @@ func (h *Handler) isModerator(c *gin.Context) {
 		return
 	}
 
-	if userType != "moderator" {
+	if claims.UserType != "moderator" {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 			"message": "only moderators are allowed",
 		})
 	}
 
-	c.Set(userTypeCtx, userType)
+	c.Set(userTypeCtx, claims.UserType)
+	c.Set(userIdCtx, claims.UserID)
 }
 
-func (h *Handler) parseAuthHeader(c *gin.Context) (string, error) {
+// getUserId returns id of the user set by auth middleware.
+func getUserId(c *gin.Context) (uuid.UUID, error) {
+	return uuid.Parse(c.GetString(userIdCtx))
+}
+
+func (h *Handler) parseAuthHeader(c *gin.Context) (auth.Claims, error) {
 	header := c.GetHeader(authorizationHeader)
 	if header == "" {
-		return "", errors.New("empty auth header")
+		return auth.Claims{}, errors.New("empty auth header")
 	}
 
 	hParts := strings.Split(header, " ")
 	if len(hParts) != 2 || hParts[0] != "Bearer" {
-		return "", errors.New("invalid auth header")
+		return auth.Claims{}, errors.New("invalid auth header")
 	}
 
 	if len(hParts[1]) == 0 {
-		return "", errors.New("auth_token is empty")
+		return auth.Claims{}, errors.New("auth_token is empty")
 	}
 
 	return h.tokensManager.Parse(hParts[1])
@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) initModerationRoutes(api *gin.RouterGroup) {
+	moderation := api.Group("/moderation")
+	{
+		moderatorsOnly := moderation.Group("/", h.isModerator)
+		{
+			moderatorsOnly.GET("/queue", h.getModerationQueue)
+			moderatorsOnly.POST("/:flatId/claim", h.claimFlat)
+			moderatorsOnly.GET("/decline-reasons", h.getDeclineReasons)
+			moderatorsOnly.PUT("/decline-reasons/:code", h.setDeclineReason)
+			moderatorsOnly.DELETE("/decline-reasons/:code", h.archiveDeclineReason)
+		}
+	}
+}
+
+// @Summary		Get Moderation Queue
+// @Security		ModeratorsAuth
+// @Description	get flats awaiting moderation, oldest first. Flats claimed by other moderators are left out,
+// @Description	ones claimed by the caller carry the claim.
+// @ID				getModerationQueue
+// @Tags			moderation
+// @Produce		json
+// @Param			house_id	query		int	false	"house id to limit queue to"
+// @Param			limit		query		int	false	"page size, 50 by default and 100 at most"
+// @Param			offset		query		int	false	"page offset"
+// @Success		200			{object}	DataResponse[[]domain.QueuedFlat]
+// @Failure		400			{object}	response
+// @Failure		401			{object}	response
+// @Failure		500			{object}	response
+// @Router			/moderation/queue [get]
+func (h *Handler) getModerationQueue(c *gin.Context) {
+	moderatorId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	var inp dtos.ModerationQueueInput
+	if err := c.ShouldBindQuery(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid query params")
+
+		return
+	}
+	inp.Normalize()
+
+	resp, err := h.services.Moderation.Queue(c, moderatorId, inp)
+	if err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.QueuedFlat]{Data: resp})
+}
+
+// @Summary		Claim Flat
+// @Security		ModeratorsAuth
+// @Description	assign flat awaiting moderation to the caller until lease expires, so that only the caller
+// @Description	may update its status. Claiming flat again extends the lease, expired claims are released.
+// @ID				claimFlat
+// @Tags			moderation
+// @Produce		json
+// @Param			flatId	path		string	true	"flat id"
+// @Success		200		{object}	DataResponse[domain.FlatClaim]
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		404		{object}	response
+// @Failure		409		{object}	response
+// @Failure		500		{object}	response
+// @Router			/moderation/:flatId/claim [post]
+func (h *Handler) claimFlat(c *gin.Context) {
+	moderatorId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	flatId, err := strconv.Atoi(c.Param("flatId"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid flat id type")
+
+		return
+	}
+
+	resp, err := h.services.Moderation.Claim(c, flatId, moderatorId)
+	if err != nil {
+		if errors.Is(err, domain.ErrFlatNotFound) {
+			messageResponse(c, http.StatusNotFound, "flat not found")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrFlatNotQueued) {
+			messageResponse(c, http.StatusConflict, "flat isn't awaiting moderation")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrFlatClaimed) {
+			messageResponse(c, http.StatusConflict, "flat is claimed by another moderator")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.FlatClaim]{Data: resp})
+}
+
+// @Summary		Get Decline Reasons
+// @Security		ModeratorsAuth
+// @Description	get catalogue of reasons flats are declined with, each with checklist for the flat creator
+// @ID				getDeclineReasons
+// @Tags			moderation
+// @Produce		json
+// @Param			archived	query		bool	false	"include archived reasons"
+// @Success		200			{object}	DataResponse[[]domain.DeclineReason]
+// @Failure		400			{object}	response
+// @Failure		401			{object}	response
+// @Failure		500			{object}	response
+// @Router			/moderation/decline-reasons [get]
+func (h *Handler) getDeclineReasons(c *gin.Context) {
+	archived, err := strconv.ParseBool(c.DefaultQuery("archived", "false"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid query params")
+
+		return
+	}
+
+	resp, err := h.services.Moderation.DeclineReasons(c, archived)
+	if err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.DeclineReason]{Data: resp})
+}
+
+// @Summary		Set Decline Reason
+// @Security		ModeratorsAuth
+// @Description	add decline reason to the catalogue or replace existing one, restoring it if archived
+// @ID				setDeclineReason
+// @Tags			moderation
+// @Accept			json
+// @Produce		json
+// @Param			code	path		string					true	"reason code of lowercase letters, digits and underscores"
+// @Param			input	body		dtos.DeclineReasonInput	true	"reason title and checklist"
+// @Success		200		{object}	DataResponse[domain.DeclineReason]
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		500		{object}	response
+// @Router			/moderation/decline-reasons/:code [put]
+func (h *Handler) setDeclineReason(c *gin.Context) {
+	var inp dtos.DeclineReasonInput
+	if err := c.BindJSON(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid input body")
+
+		return
+	}
+
+	inp.Code = c.Param("code")
+	if err := inp.Validate(); err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	resp, err := h.services.Moderation.SetDeclineReason(c, inp)
+	if err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.DeclineReason]{Data: resp})
+}
+
+// @Summary		Archive Decline Reason
+// @Security		ModeratorsAuth
+// @Description	withdraw decline reason from use, flats already declined with it keep it
+// @ID				archiveDeclineReason
+// @Tags			moderation
+// @Produce		json
+// @Param			code	path		string	true	"reason code"
+// @Success		200		{object}	response
+// @Failure		401		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/moderation/decline-reasons/:code [delete]
+func (h *Handler) archiveDeclineReason(c *gin.Context) {
+	if err := h.services.Moderation.ArchiveDeclineReason(c, c.Param("code")); err != nil {
+		if errors.Is(err, domain.ErrDeclineReasonNotFound) {
+			messageResponse(c, http.StatusNotFound, "decline reason not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "decline reason archived")
+}
@@ -0,0 +1,408 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_GetModerationQueue(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockModeration)
+
+	moderatorId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		query              string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:  "OK",
+			query: "?house_id=1&limit=10",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				inp := dtos.ModerationQueueInput{PageInput: dtos.PageInput{Limit: 10}, HouseId: 1}
+				s.EXPECT().Queue(gomock.Any(), moderatorId, inp).Return([]domain.QueuedFlat{
+					{
+						Flat:      domain.Flat{ID: 1, FlatNumber: 256, Price: 1000, Rooms: 3, Status: domain.StatusCreated},
+						HouseID:   1,
+						CreatedAt: time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":[{"ID":1,"FlatNumber":256,"Price":1000,"Rooms":3,"Status":"created",` +
+				`"HouseID":1,"CreatedAt":"2024-08-20T12:00:00Z","ClaimedBy":null,"ClaimExpiresAt":null}]}`,
+		},
+		{
+			name:               "Invalid house id",
+			query:              "?house_id=-1",
+			mockBehaviour:      func(s *mocks_service.MockModeration) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid query params"}`,
+		},
+		{
+			name:               "Invalid limit",
+			query:              "?limit=-1",
+			mockBehaviour:      func(s *mocks_service.MockModeration) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid query params"}`,
+		},
+		{
+			name:  "Internal server error",
+			query: "",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				inp := dtos.ModerationQueueInput{PageInput: dtos.PageInput{Limit: dtos.DefaultPageLimit}}
+				s.EXPECT().Queue(gomock.Any(), moderatorId, inp).Return(nil, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			moderation := mocks_service.NewMockModeration(c)
+			tt.mockBehaviour(moderation)
+
+			handler := NewHandler(&service.Services{Moderation: moderation}, nil)
+
+			r := gin.New()
+			r.GET("/api/moderation/queue", func(c *gin.Context) {
+				c.Set(userIdCtx, moderatorId.String())
+			}, handler.getModerationQueue)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/moderation/queue"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_ClaimFlat(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockModeration)
+
+	moderatorId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		id                 string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().Claim(gomock.Any(), 1, moderatorId).Return(domain.FlatClaim{
+					FlatID:      1,
+					ModeratorID: moderatorId,
+					ExpiresAt:   time.Date(2024, 8, 20, 12, 15, 0, 0, time.UTC),
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":{"FlatID":1,"ModeratorID":"3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c",` +
+				`"ExpiresAt":"2024-08-20T12:15:00Z"}}`,
+		},
+		{
+			name:               "Invalid id",
+			id:                 "one",
+			mockBehaviour:      func(s *mocks_service.MockModeration) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid flat id type"}`,
+		},
+		{
+			name: "Not found",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().Claim(gomock.Any(), 1, moderatorId).Return(domain.FlatClaim{}, domain.ErrFlatNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"flat not found"}`,
+		},
+		{
+			name: "Not queued",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().Claim(gomock.Any(), 1, moderatorId).Return(domain.FlatClaim{}, domain.ErrFlatNotQueued)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedReqBody:    `{"message":"flat isn't awaiting moderation"}`,
+		},
+		{
+			name: "Claimed by another moderator",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().Claim(gomock.Any(), 1, moderatorId).Return(domain.FlatClaim{}, domain.ErrFlatClaimed)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedReqBody:    `{"message":"flat is claimed by another moderator"}`,
+		},
+		{
+			name: "Internal server error",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().Claim(gomock.Any(), 1, moderatorId).Return(domain.FlatClaim{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			moderation := mocks_service.NewMockModeration(c)
+			tt.mockBehaviour(moderation)
+
+			handler := NewHandler(&service.Services{Moderation: moderation}, nil)
+
+			r := gin.New()
+			r.POST("/api/moderation/:flatId/claim", func(c *gin.Context) {
+				c.Set(userIdCtx, moderatorId.String())
+			}, handler.claimFlat)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/moderation/"+tt.id+"/claim", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_GetDeclineReasons(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockModeration)
+
+	tests := []struct {
+		name               string
+		query              string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:  "OK",
+			query: "",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().DeclineReasons(gomock.Any(), false).Return([]domain.DeclineReason{
+					{Code: "wrong_price", Title: "Wrong price", Checklist: []string{"Price is per month"}},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":[{"Code":"wrong_price","Title":"Wrong price","Checklist":["Price is per month"]}]}`,
+		},
+		{
+			name:  "With archived",
+			query: "?archived=true",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().DeclineReasons(gomock.Any(), true).Return([]domain.DeclineReason{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":[]}`,
+		},
+		{
+			name:               "Invalid query",
+			query:              "?archived=maybe",
+			mockBehaviour:      func(s *mocks_service.MockModeration) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid query params"}`,
+		},
+		{
+			name:  "Internal server error",
+			query: "",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().DeclineReasons(gomock.Any(), false).Return(nil, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			moderation := mocks_service.NewMockModeration(c)
+			tt.mockBehaviour(moderation)
+
+			handler := NewHandler(&service.Services{Moderation: moderation}, nil)
+
+			r := gin.New()
+			r.GET("/api/moderation/decline-reasons", handler.getDeclineReasons)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/moderation/decline-reasons"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_SetDeclineReason(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockModeration)
+
+	tests := []struct {
+		name               string
+		code               string
+		inpBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			code:    "no_photos",
+			inpBody: `{"title": "No photos", "checklist": ["Add photos of every room"]}`,
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().SetDeclineReason(gomock.Any(), dtos.DeclineReasonInput{
+					Code:      "no_photos",
+					Title:     "No photos",
+					Checklist: []string{"Add photos of every room"},
+				}).Return(domain.DeclineReason{
+					Code:      "no_photos",
+					Title:     "No photos",
+					Checklist: []string{"Add photos of every room"},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":{"Code":"no_photos","Title":"No photos","Checklist":["Add photos of every room"]}}`,
+		},
+		{
+			name:               "Invalid code",
+			code:               "No-Photos",
+			inpBody:            `{"title": "No photos"}`,
+			mockBehaviour:      func(s *mocks_service.MockModeration) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid reason code"}`,
+		},
+		{
+			name:               "Missing title",
+			code:               "no_photos",
+			inpBody:            `{"checklist": ["Add photos of every room"]}`,
+			mockBehaviour:      func(s *mocks_service.MockModeration) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid input body"}`,
+		},
+		{
+			name:    "Internal server error",
+			code:    "no_photos",
+			inpBody: `{"title": "No photos"}`,
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().SetDeclineReason(gomock.Any(), gomock.Any()).Return(domain.DeclineReason{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			moderation := mocks_service.NewMockModeration(c)
+			tt.mockBehaviour(moderation)
+
+			handler := NewHandler(&service.Services{Moderation: moderation}, nil)
+
+			r := gin.New()
+			r.PUT("/api/moderation/decline-reasons/:code", handler.setDeclineReason)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/moderation/decline-reasons/"+tt.code, bytes.NewBufferString(tt.inpBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_ArchiveDeclineReason(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockModeration)
+
+	tests := []struct {
+		name               string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().ArchiveDeclineReason(gomock.Any(), "duplicate").Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"message":"decline reason archived"}`,
+		},
+		{
+			name: "Not found",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().ArchiveDeclineReason(gomock.Any(), "duplicate").Return(domain.ErrDeclineReasonNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"decline reason not found"}`,
+		},
+		{
+			name: "Internal server error",
+			mockBehaviour: func(s *mocks_service.MockModeration) {
+				s.EXPECT().ArchiveDeclineReason(gomock.Any(), "duplicate").Return(errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			moderation := mocks_service.NewMockModeration(c)
+			tt.mockBehaviour(moderation)
+
+			handler := NewHandler(&service.Services{Moderation: moderation}, nil)
+
+			r := gin.New()
+			r.DELETE("/api/moderation/decline-reasons/:code", handler.archiveDeclineReason)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/moderation/decline-reasons/duplicate", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
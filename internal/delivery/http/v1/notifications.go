@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) initNotificationsRoutes(api *gin.RouterGroup) {
+	notifications := api.Group("/notifications")
+	{
+		moderatorsOnly := notifications.Group("/", h.isModerator)
+		{
+			moderatorsOnly.GET("/dead-letters", h.getDeadLetters)
+			moderatorsOnly.POST("/dead-letters/:id/redrive", h.redriveDeadLetter)
+			moderatorsOnly.GET("/templates", h.getNotificationTemplates)
+			moderatorsOnly.GET("/templates/:event/preview", h.previewNotificationTemplate)
+		}
+	}
+}
+
+// @Summary		Get Dead Letters
+// @Security		ModeratorsAuth
+// @Description	get notifications that weren't delivered in all attempts, most recently failed first
+// @ID				getDeadLetters
+// @Tags			notifications
+// @Produce		json
+// @Param			limit	query		int	false	"page size, 50 by default and 100 at most"
+// @Param			offset	query		int	false	"page offset"
+// @Success		200		{object}	DataResponse[[]domain.DeadLetter]
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		500		{object}	response
+// @Router			/notifications/dead-letters [get]
+func (h *Handler) getDeadLetters(c *gin.Context) {
+	var inp dtos.PageInput
+	if err := c.ShouldBindQuery(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid query params")
+
+		return
+	}
+	inp.Normalize()
+
+	resp, err := h.services.Outbox.DeadLetters(c, inp)
+	if err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.DeadLetter]{Data: resp})
+}
+
+// @Summary		Re-drive Dead Letter
+// @Security		ModeratorsAuth
+// @Description	put dead letter back to outbox to be sent again
+// @ID				redriveDeadLetter
+// @Tags			notifications
+// @Produce		json
+// @Param			id	path		string	true	"dead letter id"
+// @Success		200	{object}	DataResponse[domain.OutboxMessage]
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/notifications/dead-letters/:id/redrive [post]
+func (h *Handler) redriveDeadLetter(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid dead letter id")
+
+		return
+	}
+
+	resp, err := h.services.Outbox.Redrive(c, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrDeadLetterNotFound) {
+			messageResponse(c, http.StatusNotFound, "dead letter not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.OutboxMessage]{Data: resp})
+}
+
+// @Summary		Get Notification Templates
+// @Security		ModeratorsAuth
+// @Description	get event types notifications are sent for with locales their templates are available in
+// @ID				getNotificationTemplates
+// @Tags			notifications
+// @Produce		json
+// @Success		200	{object}	DataResponse[[]domain.NotificationTemplate]
+// @Failure		401	{object}	response
+// @Router			/notifications/templates [get]
+func (h *Handler) getNotificationTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, DataResponse[[]domain.NotificationTemplate]{Data: h.services.Outbox.Templates()})
+}
+
+// @Summary		Preview Notification Template
+// @Security		ModeratorsAuth
+// @Description	render event notification template in locale with sample data
+// @ID				previewNotificationTemplate
+// @Tags			notifications
+// @Produce		json
+// @Param			event	path		string	true	"event type"
+// @Param			locale	query		string	false	"template locale, default one if empty"
+// @Success		200		{object}	DataResponse[domain.NotificationPreview]
+// @Failure		401		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/notifications/templates/:event/preview [get]
+func (h *Handler) previewNotificationTemplate(c *gin.Context) {
+	resp, err := h.services.Outbox.Preview(domain.EventType(c.Param("event")), c.Query("locale"))
+	if err != nil {
+		if errors.Is(err, domain.ErrTemplateNotFound) {
+			messageResponse(c, http.StatusNotFound, "template not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.NotificationPreview]{Data: resp})
+}
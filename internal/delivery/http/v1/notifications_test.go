@@ -0,0 +1,188 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_GetDeadLetters(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockOutbox)
+
+	failedAt := time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		query              string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:  "OK",
+			query: "?limit=1000&offset=10",
+			mockBehaviour: func(s *mocks_service.MockOutbox) {
+				s.EXPECT().DeadLetters(gomock.Any(), dtos.PageInput{Limit: dtos.MaxPageLimit, Offset: 10}).Return([]domain.DeadLetter{
+					{
+						ID:        1,
+						OutboxID:  2,
+						EventType: domain.EventFlatApproved,
+						Channel:   domain.ChannelEmail,
+						Recipient: "test@mail.ru",
+						Payload:   json.RawMessage(`{"flat_id":1}`),
+						Attempts:  1,
+						LastError: "send attempts exhausted: internal error",
+						CreatedAt: failedAt,
+						FailedAt:  failedAt,
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":[{"ID":1,"OutboxID":2,"EventType":"flat_approved","Channel":"email","Recipient":"test@mail.ru","UserID":null,"Payload":{"flat_id":1},` +
+				`"Attempts":1,"LastError":"send attempts exhausted: internal error","CreatedAt":"2024-08-20T12:00:00Z","FailedAt":"2024-08-20T12:00:00Z"}]}`,
+		},
+		{
+			name:               "Invalid limit",
+			query:              "?limit=-1",
+			mockBehaviour:      func(s *mocks_service.MockOutbox) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid query params"}`,
+		},
+		{
+			name: "Internal server error",
+			mockBehaviour: func(s *mocks_service.MockOutbox) {
+				s.EXPECT().DeadLetters(gomock.Any(), dtos.PageInput{Limit: dtos.DefaultPageLimit}).Return(nil, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			outbox := mocks_service.NewMockOutbox(c)
+			tt.mockBehaviour(outbox)
+
+			handler := NewHandler(&service.Services{Outbox: outbox}, nil)
+
+			r := gin.New()
+			r.GET("/api/notifications/dead-letters", handler.getDeadLetters)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/notifications/dead-letters"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_RedriveDeadLetter(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	outbox := mocks_service.NewMockOutbox(c)
+	outbox.EXPECT().Redrive(gomock.Any(), int64(7)).Return(domain.OutboxMessage{}, domain.ErrDeadLetterNotFound)
+
+	handler := NewHandler(&service.Services{Outbox: outbox}, nil)
+
+	r := gin.New()
+	r.POST("/api/notifications/dead-letters/:id/redrive", handler.redriveDeadLetter)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/notifications/dead-letters/7/redrive", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"dead letter not found"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/notifications/dead-letters/seven/redrive", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PreviewNotificationTemplate(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockOutbox)
+
+	tests := []struct {
+		name               string
+		path               string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			path: "/api/notifications/templates/flat_approved/preview?locale=en",
+			mockBehaviour: func(s *mocks_service.MockOutbox) {
+				s.EXPECT().Preview(domain.EventFlatApproved, "en").Return(domain.NotificationPreview{
+					EventType: domain.EventFlatApproved,
+					Locale:    "en",
+					Subject:   "New flat in house 1",
+					Text:      "New flat №42 is available",
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":{"EventType":"flat_approved","Locale":"en","Subject":"New flat in house 1",` +
+				`"Text":"New flat №42 is available","HTML":""}}`,
+		},
+		{
+			name: "Template not found",
+			path: "/api/notifications/templates/flat_approved/preview?locale=de",
+			mockBehaviour: func(s *mocks_service.MockOutbox) {
+				s.EXPECT().Preview(domain.EventFlatApproved, "de").Return(domain.NotificationPreview{}, domain.ErrTemplateNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"template not found"}`,
+		},
+		{
+			name: "Internal server error",
+			path: "/api/notifications/templates/flat_approved/preview",
+			mockBehaviour: func(s *mocks_service.MockOutbox) {
+				s.EXPECT().Preview(domain.EventFlatApproved, "").Return(domain.NotificationPreview{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			outbox := mocks_service.NewMockOutbox(c)
+			tt.mockBehaviour(outbox)
+
+			handler := NewHandler(&service.Services{Outbox: outbox}, nil)
+
+			r := gin.New()
+			r.GET("/api/notifications/templates/:event/preview", handler.previewNotificationTemplate)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.path, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"net/http"
+)
+
+func (h *Handler) initUnsubscribeRoutes(api *gin.RouterGroup) {
+	// Links are followed from emails, so routes require no authorization, token is the proof of recipient.
+	// GET only asks to confirm, since links are also fetched by mail scanners and prefetchers.
+	// POST is sent by the confirmation form and mail clients supporting one-click List-Unsubscribe-Post header.
+	api.GET("/unsubscribe", h.unsubscribe)
+	api.POST("/unsubscribe", h.unsubscribeOneClick)
+}
+
+// unsubscribePage is shown to recipient following unsubscribe link, asking to confirm
+// unsubscribing while Token is set.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+{{if .Error}}<p>{{.Error}}</p>
+{{else if .Token}}<p>Unsubscribe {{.Email}} from notifications about {{template "houses" .HouseIDs}}?</p>
+<form method="post" action="?token={{.Token}}"><button type="submit">Unsubscribe</button></form>
+{{else}}<p>{{.Email}} is unsubscribed from notifications about {{template "houses" .HouseIDs}}.</p>
+<p>You can subscribe again at any time.</p>
+{{end}}</body>
+</html>
+{{define "houses"}}{{if eq (len .) 1}}house{{else}}houses{{end}}{{range $i, $id := .}}{{if $i}},{{end}} {{$id}}{{end}}{{end}}`))
+
+type unsubscribePageData struct {
+	domain.Unsubscription
+	Token string
+	Error string
+}
+
+// @Summary		Unsubscribe By Link
+// @Description	check signed expiring token from the link unsubscribing recipient of notification email from houses
+// @Description	it's about and ask to confirm unsubscribing, no authorization is required. Subscriptions are left as is.
+// @ID				unsubscribe
+// @Tags			house
+// @Produce		html
+// @Param			token	query		string	true	"unsubscribe token"
+// @Success		200		{string}	string	"confirmation form page"
+// @Failure		400		{string}	string	"invalid link page"
+// @Failure		410		{string}	string	"expired link page"
+// @Failure		500		{string}	string	"error page"
+// @Router			/unsubscribe [get]
+func (h *Handler) unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+
+	resp, err := h.services.Houses.VerifyUnsubscribe(c, token)
+	if err != nil {
+		unsubscribeErrorResponse(c, err)
+
+		return
+	}
+
+	unsubscribePageResponse(c, http.StatusOK, unsubscribePageData{Unsubscription: resp, Token: token})
+}
+
+// @Summary		One-Click Unsubscribe
+// @Description	unsubscribe recipient of notification email from houses it's about with signed expiring token
+// @Description	from the link, no authorization is required. Sent by the confirmation form and by mail clients
+// @Description	for List-Unsubscribe-Post header. Unsubscribing again succeeds as well.
+// @ID				unsubscribeOneClick
+// @Tags			house
+// @Accept			x-www-form-urlencoded
+// @Produce		html
+// @Param			token	query		string	true	"unsubscribe token"
+// @Success		200		{string}	string	"confirmation page"
+// @Failure		400		{string}	string	"invalid link page"
+// @Failure		410		{string}	string	"expired link page"
+// @Failure		500		{string}	string	"error page"
+// @Router			/unsubscribe [post]
+func (h *Handler) unsubscribeOneClick(c *gin.Context) {
+	resp, err := h.services.Houses.UnsubscribeByToken(c, c.Query("token"))
+	if err != nil {
+		unsubscribeErrorResponse(c, err)
+
+		return
+	}
+
+	unsubscribePageResponse(c, http.StatusOK, unsubscribePageData{Unsubscription: resp})
+}
+
+func unsubscribeErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrInvalidUnsubscribe) {
+		unsubscribePageResponse(c, http.StatusBadRequest, unsubscribePageData{Error: "Unsubscribe link is invalid."})
+
+		return
+	}
+
+	if errors.Is(err, domain.ErrUnsubscribeExpired) {
+		unsubscribePageResponse(c, http.StatusGone, unsubscribePageData{Error: "Unsubscribe link has expired, " +
+			"manage your subscriptions in your account instead."})
+
+		return
+	}
+
+	unsubscribePageResponse(c, http.StatusInternalServerError, unsubscribePageData{Error: "Something went wrong, please try again later."})
+}
+
+func unsubscribePageResponse(c *gin.Context, statusCode int, data unsubscribePageData) {
+	var buf bytes.Buffer
+	if err := unsubscribePage.Execute(&buf, data); err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.Data(statusCode, "text/html; charset=utf-8", buf.Bytes())
+}
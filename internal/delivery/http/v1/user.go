@@ -0,0 +1,438 @@
+package v1
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) initUserRoutes(api *gin.RouterGroup) {
+	user := api.Group("/user")
+	{
+		authorized := user.Group("/", h.isAuthorized)
+		{
+			authorized.GET("/flats", h.getUserFlats)
+			authorized.GET("/subscriptions", h.getUserSubscriptions)
+			authorized.PUT("/subscriptions/:id/delivery", h.setSubscriptionDelivery)
+			authorized.PUT("/language", h.setUserLanguage)
+			authorized.GET("/notification-settings", h.getNotificationSettings)
+			authorized.PUT("/notification-settings", h.setNotificationSettings)
+			authorized.GET("/notifications", h.getUserNotifications)
+			authorized.POST("/notifications/read", h.readAllUserNotifications)
+			authorized.POST("/notifications/:id/read", h.readUserNotification)
+			authorized.GET("/export", h.exportUserData)
+		}
+	}
+}
+
+// @Summary		Get User Flats
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	get flats created by user in any status, newest first, with summaries of their houses.
+// @Description	Flats edited since approval carry their approved version as well.
+// @ID				getUserFlats
+// @Tags			user
+// @Produce		json
+// @Success		200	{object}	DataResponse[[]domain.FlatDetails]
+// @Failure		401	{object}	response
+// @Failure		500	{object}	response
+// @Router			/user/flats [get]
+func (h *Handler) getUserFlats(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	resp, err := h.services.Flats.UserFlats(c, userId)
+	if err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.FlatDetails]{Data: resp})
+}
+
+// @Summary		Get User Subscriptions
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	get house subscriptions of registered user with their filters
+// @ID				getUserSubscriptions
+// @Tags			user
+// @Produce		json
+// @Success		200	{object}	DataResponse[[]domain.Subscription]
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/user/subscriptions [get]
+func (h *Handler) getUserSubscriptions(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	resp, err := h.services.Users.Subscriptions(c, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			messageResponse(c, http.StatusNotFound, "user not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.Subscription]{Data: resp})
+}
+
+// @Summary		Set Subscription Delivery
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	set how user is notified about new flats of the house: "immediate" message per flat,
+// @Description	"daily" or "weekly" digest listing each flat once. Flats already pending are sent with the pending digest.
+// @ID				setSubscriptionDelivery
+// @Tags			user
+// @Accept			json
+// @Produce		json
+// @Param			id		path		string							true	"house id"
+// @Param			input	body		dtos.SubscriptionDeliveryInput	true	"delivery"
+// @Success		200		{object}	response
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/user/subscriptions/:id/delivery [put]
+func (h *Handler) setSubscriptionDelivery(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	houseId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid house id type")
+
+		return
+	}
+
+	var inp dtos.SubscriptionDeliveryInput
+	if err = c.ShouldBindJSON(&inp); err != nil || !inp.Delivery.Validate() {
+		messageResponse(c, http.StatusBadRequest, "invalid input body")
+
+		return
+	}
+
+	if err = h.services.Users.SetSubscriptionDelivery(c, userId, houseId, inp.Delivery); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			messageResponse(c, http.StatusNotFound, "user not found")
+
+			return
+		}
+
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			messageResponse(c, http.StatusNotFound, "subscription not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "subscription delivery set")
+}
+
+// @Summary		Set User Language
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	set language notifications are sent to registered user in
+// @ID				setUserLanguage
+// @Tags			user
+// @Accept			json
+// @Produce		json
+// @Param			input	body		dtos.UserLanguageInput	true	"language, ru or en"
+// @Success		200		{object}	response
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/user/language [put]
+func (h *Handler) setUserLanguage(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	var inp dtos.UserLanguageInput
+	if err = c.ShouldBindJSON(&inp); err != nil || !inp.Language.Validate() {
+		messageResponse(c, http.StatusBadRequest, "invalid input body")
+
+		return
+	}
+
+	if err = h.services.Users.SetLanguage(c, userId, inp.Language); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			messageResponse(c, http.StatusNotFound, "user not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "language set")
+}
+
+// @Summary		Get Notification Settings
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	get contacts, quiet hours and channels notifications are sent to registered user through
+// @ID				getNotificationSettings
+// @Tags			user
+// @Produce		json
+// @Success		200	{object}	DataResponse[domain.NotificationSettings]
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/user/notification-settings [get]
+func (h *Handler) getNotificationSettings(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	resp, err := h.services.Users.NotificationSettings(c, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			messageResponse(c, http.StatusNotFound, "user not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.NotificationSettings]{Data: resp})
+}
+
+// @Summary		Set Notification Settings
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	replace contacts, quiet hours and channels notifications are sent to registered user through.
+// @Description	SMS and push notifications are delayed until the end of quiet hours.
+// @ID				setNotificationSettings
+// @Tags			user
+// @Accept			json
+// @Produce		json
+// @Param			input	body		dtos.NotificationSettingsInput	true	"notification settings"
+// @Success		200		{object}	response
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/user/notification-settings [put]
+func (h *Handler) setNotificationSettings(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	var inp dtos.NotificationSettingsInput
+	if err = c.ShouldBindJSON(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid input body")
+
+		return
+	}
+
+	if err = inp.Validate(); err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	if err = h.services.Users.SetNotificationSettings(c, userId, inp); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			messageResponse(c, http.StatusNotFound, "user not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "notification settings set")
+}
+
+// @Summary		Get User Notifications
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	get notifications from user in-app inbox, most recent first
+// @ID				getUserNotifications
+// @Tags			user
+// @Produce		json
+// @Param			limit	query		int		false	"page size, 50 by default and 100 at most"
+// @Param			offset	query		int		false	"page offset"
+// @Param			unread	query		bool	false	"only unread notifications"
+// @Success		200		{object}	DataResponse[[]domain.Notification]
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		500		{object}	response
+// @Router			/user/notifications [get]
+func (h *Handler) getUserNotifications(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	var inp dtos.NotificationsInput
+	if err = c.ShouldBindQuery(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid query params")
+
+		return
+	}
+	inp.Normalize()
+
+	resp, err := h.services.Notifications.GetAll(c, userId, inp)
+	if err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.Notification]{Data: resp})
+}
+
+// @Summary		Read User Notification
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	mark notification from user inbox read
+// @ID				readUserNotification
+// @Tags			user
+// @Produce		json
+// @Param			id	path		string	true	"notification id"
+// @Success		200	{object}	response
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/user/notifications/:id/read [post]
+func (h *Handler) readUserNotification(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid notification id")
+
+		return
+	}
+
+	if err = h.services.Notifications.MarkRead(c, userId, id); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			messageResponse(c, http.StatusNotFound, "notification not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "notification read")
+}
+
+// @Summary		Read All User Notifications
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	mark all notifications from user inbox read
+// @ID				readAllUserNotifications
+// @Tags			user
+// @Produce		json
+// @Success		200	{object}	response
+// @Failure		401	{object}	response
+// @Failure		500	{object}	response
+// @Router			/user/notifications/read [post]
+func (h *Handler) readAllUserNotifications(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	if err = h.services.Notifications.MarkAllRead(c, userId); err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "all notifications read")
+}
+
+// @Summary		Export User Data
+// @Security		ClientsAuth
+// @Security		ModeratorsAuth
+// @Description	get all data stored about user: account, notification settings, subscriptions, created flats
+// @Description	and status history of them along with status changes user made as moderator.
+// @ID				exportUserData
+// @Tags			user
+// @Produce		json
+// @Success		200	{object}	DataResponse[domain.AccountExport]
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/user/export [get]
+func (h *Handler) exportUserData(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		messageResponse(c, http.StatusUnauthorized, "user identity required")
+
+		return
+	}
+
+	resp, err := h.services.Users.Export(c, userId)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			messageResponse(c, http.StatusNotFound, "user not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.AccountExport]{Data: resp})
+}
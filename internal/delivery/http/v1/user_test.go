@@ -0,0 +1,626 @@
+package v1
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_GetUserSubscriptions(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockUsers, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+	minRooms := 2
+
+	tests := []struct {
+		name               string
+		userId             string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:   "OK",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().Subscriptions(gomock.Any(), userId).Return([]domain.Subscription{
+					{
+						HouseID:   1,
+						Email:     "test@mail.ru",
+						MinRooms:  &minRooms,
+						Delivery:  domain.DeliveryDaily,
+						CreatedAt: time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":[{"HouseID":1,"Email":"test@mail.ru","MinRooms":2,"MaxRooms":null,` +
+				`"MinPrice":null,"MaxPrice":null,"Delivery":"daily","CreatedAt":"2024-08-20T12:00:00Z"}]}`,
+		},
+		{
+			name:               "No identity",
+			userId:             "",
+			mockBehaviour:      func(s *mocks_service.MockUsers, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedReqBody:    `{"message":"user identity required"}`,
+		},
+		{
+			name:   "User not found",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().Subscriptions(gomock.Any(), userId).Return(nil, domain.ErrUserNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"user not found"}`,
+		},
+		{
+			name:   "Internal server error",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().Subscriptions(gomock.Any(), userId).Return(nil, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			users := mocks_service.NewMockUsers(c)
+			tt.mockBehaviour(users, userId)
+
+			handler := NewHandler(&service.Services{Users: users}, nil)
+
+			r := gin.New()
+			r.GET("/api/user/subscriptions", func(c *gin.Context) {
+				c.Set(userIdCtx, tt.userId)
+			}, handler.getUserSubscriptions)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/user/subscriptions", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_GetUserFlats(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockFlats, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		userId             string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:   "OK",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockFlats, userId uuid.UUID) {
+				s.EXPECT().UserFlats(gomock.Any(), userId).Return([]domain.FlatDetails{
+					{
+						Flat:     domain.Flat{ID: 2, FlatNumber: 12, Price: 2000, Rooms: 2, Status: domain.StatusCreated, CreatedBy: &userId},
+						House:    domain.HouseSummary{ID: 1, Address: "test address", Year: 2001},
+						Approved: &domain.FlatVersion{FlatNumber: 12, Price: 1000, Rooms: 2},
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":[{"ID":2,"FlatNumber":12,"Price":2000,"Rooms":2,"Status":"created",` +
+				`"House":{"ID":1,"Address":"test address","Year":2001,"Developer":""},` +
+				`"Approved":{"FlatNumber":12,"Price":1000,"Rooms":2}}]}`,
+		},
+		{
+			name:               "No identity",
+			userId:             "",
+			mockBehaviour:      func(s *mocks_service.MockFlats, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedReqBody:    `{"message":"user identity required"}`,
+		},
+		{
+			name:   "Internal server error",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockFlats, userId uuid.UUID) {
+				s.EXPECT().UserFlats(gomock.Any(), userId).Return(nil, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			flats := mocks_service.NewMockFlats(c)
+			tt.mockBehaviour(flats, userId)
+
+			handler := NewHandler(&service.Services{Flats: flats}, nil)
+
+			r := gin.New()
+			r.GET("/api/user/flats", func(c *gin.Context) {
+				c.Set(userIdCtx, tt.userId)
+			}, handler.getUserFlats)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/user/flats", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_SetUserLanguage(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockUsers, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		reqBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			reqBody: `{"language":"en"}`,
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().SetLanguage(gomock.Any(), userId, domain.LanguageEn).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"message":"language set"}`,
+		},
+		{
+			name:               "Unknown language",
+			reqBody:            `{"language":"de"}`,
+			mockBehaviour:      func(s *mocks_service.MockUsers, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid input body"}`,
+		},
+		{
+			name:    "User not found",
+			reqBody: `{"language":"ru"}`,
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().SetLanguage(gomock.Any(), userId, domain.LanguageRu).Return(domain.ErrUserNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"user not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			users := mocks_service.NewMockUsers(c)
+			tt.mockBehaviour(users, userId)
+
+			handler := NewHandler(&service.Services{Users: users}, nil)
+
+			r := gin.New()
+			r.PUT("/api/user/language", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+			}, handler.setUserLanguage)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/user/language", strings.NewReader(tt.reqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_SetNotificationSettings(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockUsers, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		reqBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			reqBody: `{"phone":"+79990000000","channels":{"flat_approved":["sms","in_app"]}}`,
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().SetNotificationSettings(gomock.Any(), userId, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"message":"notification settings set"}`,
+		},
+		{
+			name:               "No phone for sms",
+			reqBody:            `{"channels":{"flat_approved":["sms"]}}`,
+			mockBehaviour:      func(s *mocks_service.MockUsers, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"phone is required for sms channel"}`,
+		},
+		{
+			name:               "Bad quiet hours",
+			reqBody:            `{"quiet_hours":{"start":"22","end":"08:00"}}`,
+			mockBehaviour:      func(s *mocks_service.MockUsers, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"quiet hours must be in HH:MM format"}`,
+		},
+		{
+			name:    "User not found",
+			reqBody: `{"channels":{"flat_approved":["email"]}}`,
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().SetNotificationSettings(gomock.Any(), userId, gomock.Any()).Return(domain.ErrUserNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"user not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			users := mocks_service.NewMockUsers(c)
+			tt.mockBehaviour(users, userId)
+
+			handler := NewHandler(&service.Services{Users: users}, nil)
+
+			r := gin.New()
+			r.PUT("/api/user/notification-settings", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+			}, handler.setNotificationSettings)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/user/notification-settings", strings.NewReader(tt.reqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_GetUserNotifications(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockNotifications, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+	createdAt := time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		query              string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:  "OK",
+			query: "?unread=true&limit=10",
+			mockBehaviour: func(s *mocks_service.MockNotifications, userId uuid.UUID) {
+				inp := dtos.NotificationsInput{PageInput: dtos.PageInput{Limit: 10}, Unread: true}
+				s.EXPECT().GetAll(gomock.Any(), userId, inp).Return([]domain.Notification{
+					{
+						ID:        1,
+						EventType: domain.EventFlatApproved,
+						Title:     "New flat in house 1",
+						Body:      "New flat №42 is available",
+						Payload:   []byte(`{"flat_id":1}`),
+						CreatedAt: createdAt,
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"data":[{"ID":1,"EventType":"flat_approved","Title":"New flat in house 1","Body":"New flat №42 is available","Payload":{"flat_id":1},"ReadAt":null,"CreatedAt":"2024-08-20T12:00:00Z"}]}`,
+		},
+		{
+			name:               "Invalid query",
+			query:              "?limit=-1",
+			mockBehaviour:      func(s *mocks_service.MockNotifications, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid query params"}`,
+		},
+		{
+			name:  "Internal server error",
+			query: "",
+			mockBehaviour: func(s *mocks_service.MockNotifications, userId uuid.UUID) {
+				inp := dtos.NotificationsInput{PageInput: dtos.PageInput{Limit: dtos.DefaultPageLimit}}
+				s.EXPECT().GetAll(gomock.Any(), userId, inp).Return(nil, errors.New("internal error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			notifications := mocks_service.NewMockNotifications(c)
+			tt.mockBehaviour(notifications, userId)
+
+			handler := NewHandler(&service.Services{Notifications: notifications}, nil)
+
+			r := gin.New()
+			r.GET("/api/user/notifications", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+			}, handler.getUserNotifications)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/user/notifications"+tt.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_ReadUserNotification(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockNotifications, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		id                 string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			id:   "1",
+			mockBehaviour: func(s *mocks_service.MockNotifications, userId uuid.UUID) {
+				s.EXPECT().MarkRead(gomock.Any(), userId, int64(1)).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"message":"notification read"}`,
+		},
+		{
+			name:               "Invalid id",
+			id:                 "abc",
+			mockBehaviour:      func(s *mocks_service.MockNotifications, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid notification id"}`,
+		},
+		{
+			name: "Not found",
+			id:   "2",
+			mockBehaviour: func(s *mocks_service.MockNotifications, userId uuid.UUID) {
+				s.EXPECT().MarkRead(gomock.Any(), userId, int64(2)).Return(domain.ErrNotificationNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"notification not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			notifications := mocks_service.NewMockNotifications(c)
+			tt.mockBehaviour(notifications, userId)
+
+			handler := NewHandler(&service.Services{Notifications: notifications}, nil)
+
+			r := gin.New()
+			r.POST("/api/user/notifications/:id/read", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+			}, handler.readUserNotification)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/user/notifications/"+tt.id+"/read", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_SetSubscriptionDelivery(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockUsers, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		houseId            string
+		reqBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			houseId: "1",
+			reqBody: `{"delivery":"weekly"}`,
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().SetSubscriptionDelivery(gomock.Any(), userId, 1, domain.DeliveryWeekly).Return(nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody:    `{"message":"subscription delivery set"}`,
+		},
+		{
+			name:               "Unknown delivery",
+			houseId:            "1",
+			reqBody:            `{"delivery":"hourly"}`,
+			mockBehaviour:      func(s *mocks_service.MockUsers, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid input body"}`,
+		},
+		{
+			name:               "Invalid house id",
+			houseId:            "abc",
+			reqBody:            `{"delivery":"daily"}`,
+			mockBehaviour:      func(s *mocks_service.MockUsers, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid house id type"}`,
+		},
+		{
+			name:    "Subscription not found",
+			houseId: "2",
+			reqBody: `{"delivery":"daily"}`,
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().SetSubscriptionDelivery(gomock.Any(), userId, 2, domain.DeliveryDaily).Return(domain.ErrSubscriptionNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"subscription not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			users := mocks_service.NewMockUsers(c)
+			tt.mockBehaviour(users, userId)
+
+			handler := NewHandler(&service.Services{Users: users}, nil)
+
+			r := gin.New()
+			r.PUT("/api/user/subscriptions/:id/delivery", func(c *gin.Context) {
+				c.Set(userIdCtx, userId.String())
+			}, handler.setSubscriptionDelivery)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/user/subscriptions/"+tt.houseId+"/delivery", strings.NewReader(tt.reqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_ExportUserData(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockUsers, userId uuid.UUID)
+
+	userId := uuid.MustParse("3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c")
+
+	tests := []struct {
+		name               string
+		userId             string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:   "OK",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().Export(gomock.Any(), userId).Return(domain.AccountExport{
+					ID:                   userId,
+					Email:                "test@mail.ru",
+					UserType:             domain.UserTypeClient,
+					Language:             domain.LanguageEn,
+					NotificationSettings: domain.NotificationSettings{Timezone: domain.DefaultTimezone},
+					Subscriptions:        []domain.Subscription{},
+					Flats:                []domain.FlatDetails{},
+					StatusHistory: []domain.FlatStatusRecord{
+						{
+							FlatID: 1,
+							FlatStatusChange: domain.FlatStatusChange{
+								NewStatus: domain.StatusCreated,
+								Actor:     &userId,
+								CreatedAt: time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC),
+							},
+						},
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":{"ID":"3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c","Email":"test@mail.ru","UserType":"client",` +
+				`"Language":"en","NotificationSettings":{"Phone":null,"PushToken":null,"Timezone":"UTC","QuietHours":null,"Channels":null},` +
+				`"Subscriptions":[],"Flats":[],"StatusHistory":[{"FlatID":1,"NewStatus":"created",` +
+				`"Actor":"3b0d5c1e-2f4a-4e8b-9a7c-1d2e3f4a5b6c","CreatedAt":"2024-08-20T12:00:00Z"}]}}`,
+		},
+		{
+			name:               "No identity",
+			userId:             "",
+			mockBehaviour:      func(s *mocks_service.MockUsers, userId uuid.UUID) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedReqBody:    `{"message":"user identity required"}`,
+		},
+		{
+			name:   "User not found",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().Export(gomock.Any(), userId).Return(domain.AccountExport{}, domain.ErrUserNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"user not found"}`,
+		},
+		{
+			name:   "Internal server error",
+			userId: userId.String(),
+			mockBehaviour: func(s *mocks_service.MockUsers, userId uuid.UUID) {
+				s.EXPECT().Export(gomock.Any(), userId).Return(domain.AccountExport{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			users := mocks_service.NewMockUsers(c)
+			tt.mockBehaviour(users, userId)
+
+			handler := NewHandler(&service.Services{Users: users}, nil)
+
+			r := gin.New()
+			r.GET("/api/user/export", func(c *gin.Context) {
+				c.Set(userIdCtx, tt.userId)
+			}, handler.exportUserData)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/user/export", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) initWebhooksRoutes(api *gin.RouterGroup) {
+	// Group is moderators only as a whole, so that collection routes have no trailing slash.
+	webhooks := api.Group("/webhooks", h.isModerator)
+	{
+		webhooks.POST("", h.createWebhook)
+		webhooks.GET("", h.getWebhooks)
+		webhooks.DELETE("/:id", h.deleteWebhook)
+		webhooks.POST("/:id/enable", h.enableWebhook)
+		webhooks.GET("/:id/deliveries", h.getWebhookDeliveries)
+	}
+}
+
+// createdWebhook is the only response webhook secret is shown in.
+type createdWebhook struct {
+	domain.Webhook
+	Secret string
+}
+
+// @Summary		Create Webhook
+// @Security		ModeratorsAuth
+// @Description	register partner endpoint events are posted to, signed with HMAC-SHA256 of "<timestamp>.<body>"
+// @Description	keyed with secret, generated if not provided. Secret is shown only in this response.
+// @Description	URL host must resolve to public addresses, loopback, private and link-local ones are rejected.
+// @ID				createWebhook
+// @Tags			webhooks
+// @Accept			json
+// @Produce		json
+// @Param			input	body		dtos.WebhookCreateInput	true	"webhook info"
+// @Success		201		{object}	DataResponse[createdWebhook]
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		500		{object}	response
+// @Router			/webhooks [post]
+func (h *Handler) createWebhook(c *gin.Context) {
+	var inp dtos.WebhookCreateInput
+	if err := c.ShouldBindJSON(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid input body")
+
+		return
+	}
+
+	if err := inp.Validate(); err != nil {
+		messageResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	resp, err := h.services.Webhooks.Create(c, inp)
+	if err != nil {
+		if errors.Is(err, domain.ErrWebhookURLForbidden) {
+			messageResponse(c, http.StatusBadRequest, "url must resolve to public addresses")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, DataResponse[createdWebhook]{Data: createdWebhook{Webhook: resp, Secret: resp.Secret}})
+}
+
+// @Summary		Get Webhooks
+// @Security		ModeratorsAuth
+// @Description	get registered webhooks, secrets aren't shown
+// @ID				getWebhooks
+// @Tags			webhooks
+// @Produce		json
+// @Success		200	{object}	DataResponse[[]domain.Webhook]
+// @Failure		401	{object}	response
+// @Failure		500	{object}	response
+// @Router			/webhooks [get]
+func (h *Handler) getWebhooks(c *gin.Context) {
+	resp, err := h.services.Webhooks.GetAll(c)
+	if err != nil {
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.Webhook]{Data: resp})
+}
+
+// @Summary		Delete Webhook
+// @Security		ModeratorsAuth
+// @Description	delete webhook with its delivery logs
+// @ID				deleteWebhook
+// @Tags			webhooks
+// @Produce		json
+// @Param			id	path		string	true	"webhook id"
+// @Success		200	{object}	response
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/webhooks/:id [delete]
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid webhook id")
+
+		return
+	}
+
+	if err = h.services.Webhooks.Delete(c, id); err != nil {
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			messageResponse(c, http.StatusNotFound, "webhook not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	messageResponse(c, http.StatusOK, "webhook deleted")
+}
+
+// @Summary		Enable Webhook
+// @Security		ModeratorsAuth
+// @Description	re-enable webhook disabled after failed deliveries
+// @ID				enableWebhook
+// @Tags			webhooks
+// @Produce		json
+// @Param			id	path		string	true	"webhook id"
+// @Success		200	{object}	DataResponse[domain.Webhook]
+// @Failure		400	{object}	response
+// @Failure		401	{object}	response
+// @Failure		404	{object}	response
+// @Failure		500	{object}	response
+// @Router			/webhooks/:id/enable [post]
+func (h *Handler) enableWebhook(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid webhook id")
+
+		return
+	}
+
+	resp, err := h.services.Webhooks.Enable(c, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			messageResponse(c, http.StatusNotFound, "webhook not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[domain.Webhook]{Data: resp})
+}
+
+// @Summary		Get Webhook Deliveries
+// @Security		ModeratorsAuth
+// @Description	get delivery logs of webhook, most recent first
+// @ID				getWebhookDeliveries
+// @Tags			webhooks
+// @Produce		json
+// @Param			id		path		string	true	"webhook id"
+// @Param			limit	query		int		false	"page size, 50 by default and 100 at most"
+// @Param			offset	query		int		false	"page offset"
+// @Success		200		{object}	DataResponse[[]domain.WebhookDelivery]
+// @Failure		400		{object}	response
+// @Failure		401		{object}	response
+// @Failure		404		{object}	response
+// @Failure		500		{object}	response
+// @Router			/webhooks/:id/deliveries [get]
+func (h *Handler) getWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid webhook id")
+
+		return
+	}
+
+	var inp dtos.PageInput
+	if err = c.ShouldBindQuery(&inp); err != nil {
+		messageResponse(c, http.StatusBadRequest, "invalid query params")
+
+		return
+	}
+	inp.Normalize()
+
+	resp, err := h.services.Webhooks.Deliveries(c, id, inp)
+	if err != nil {
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			messageResponse(c, http.StatusNotFound, "webhook not found")
+
+			return
+		}
+
+		messageResponse(c, http.StatusInternalServerError, "internal server error")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, DataResponse[[]domain.WebhookDelivery]{Data: resp})
+}
@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	mocks_service "github.com/dzhordano/avito-bootcamp2024/internal/service/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_CreateWebhook(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockWebhooks)
+
+	createdAt := time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		reqBody            string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name:    "OK",
+			reqBody: `{"url":"https://partner.example/hooks","event_types":["flat_approved"]}`,
+			mockBehaviour: func(s *mocks_service.MockWebhooks) {
+				s.EXPECT().Create(gomock.Any(), dtos.WebhookCreateInput{
+					URL:        "https://partner.example/hooks",
+					EventTypes: []domain.EventType{domain.EventFlatApproved},
+				}).Return(domain.Webhook{
+					ID:         1,
+					URL:        "https://partner.example/hooks",
+					EventTypes: []domain.EventType{domain.EventFlatApproved},
+					Secret:     "generated",
+					Active:     true,
+					CreatedAt:  createdAt,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedReqBody: `{"data":{"ID":1,"URL":"https://partner.example/hooks","EventTypes":["flat_approved"],"Active":true,` +
+				`"ConsecutiveFailures":0,"DisabledAt":null,"CreatedAt":"2024-08-20T12:00:00Z","Secret":"generated"}}`,
+		},
+		{
+			name:               "Relative url",
+			reqBody:            `{"url":"/hooks","event_types":["flat_approved"]}`,
+			mockBehaviour:      func(s *mocks_service.MockWebhooks) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"url must be absolute http or https url"}`,
+		},
+		{
+			name:               "Unknown event type",
+			reqBody:            `{"url":"https://partner.example/hooks","event_types":["flat_created"]}`,
+			mockBehaviour:      func(s *mocks_service.MockWebhooks) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"unknown event type 'flat_created'"}`,
+		},
+		{
+			name:               "No event types",
+			reqBody:            `{"url":"https://partner.example/hooks","event_types":[]}`,
+			mockBehaviour:      func(s *mocks_service.MockWebhooks) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid input body"}`,
+		},
+		{
+			name:    "Internal address",
+			reqBody: `{"url":"http://169.254.169.254/latest/meta-data","event_types":["flat_approved"]}`,
+			mockBehaviour: func(s *mocks_service.MockWebhooks) {
+				s.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.Webhook{}, domain.ErrWebhookURLForbidden)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"url must resolve to public addresses"}`,
+		},
+		{
+			name:    "Internal server error",
+			reqBody: `{"url":"https://partner.example/hooks","event_types":["flat_approved"],"secret":"0123456789abcdef"}`,
+			mockBehaviour: func(s *mocks_service.MockWebhooks) {
+				s.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.Webhook{}, errors.New("internal server error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedReqBody:    `{"message":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooks := mocks_service.NewMockWebhooks(c)
+			tt.mockBehaviour(webhooks)
+
+			handler := NewHandler(&service.Services{Webhooks: webhooks}, nil)
+
+			r := gin.New()
+			r.POST("/api/webhooks", handler.createWebhook)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/webhooks", strings.NewReader(tt.reqBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
+
+func Test_GetWebhookDeliveries(t *testing.T) {
+	type mockBehaviour func(s *mocks_service.MockWebhooks)
+
+	statusCode := http.StatusServiceUnavailable
+	deliveryErr := "webhook delivery failed: unexpected response status 503"
+
+	tests := []struct {
+		name               string
+		path               string
+		mockBehaviour      mockBehaviour
+		expectedStatusCode int
+		expectedReqBody    string
+	}{
+		{
+			name: "OK",
+			path: "/api/webhooks/1/deliveries?limit=10",
+			mockBehaviour: func(s *mocks_service.MockWebhooks) {
+				s.EXPECT().Deliveries(gomock.Any(), int64(1), dtos.PageInput{Limit: 10}).Return([]domain.WebhookDelivery{
+					{
+						ID:         2,
+						WebhookID:  1,
+						OutboxID:   3,
+						EventType:  domain.EventFlatApproved,
+						StatusCode: &statusCode,
+						Attempts:   3,
+						DurationMs: 120,
+						Error:      &deliveryErr,
+						CreatedAt:  time.Date(2024, 8, 20, 12, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedReqBody: `{"data":[{"ID":2,"WebhookID":1,"OutboxID":3,"EventType":"flat_approved","Success":false,"StatusCode":503,` +
+				`"Attempts":3,"DurationMs":120,"Error":"webhook delivery failed: unexpected response status 503","CreatedAt":"2024-08-20T12:00:00Z"}]}`,
+		},
+		{
+			name:               "Invalid id",
+			path:               "/api/webhooks/one/deliveries",
+			mockBehaviour:      func(s *mocks_service.MockWebhooks) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedReqBody:    `{"message":"invalid webhook id"}`,
+		},
+		{
+			name: "Webhook not found",
+			path: "/api/webhooks/1/deliveries",
+			mockBehaviour: func(s *mocks_service.MockWebhooks) {
+				s.EXPECT().Deliveries(gomock.Any(), int64(1), dtos.PageInput{Limit: dtos.DefaultPageLimit}).Return(nil, domain.ErrWebhookNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedReqBody:    `{"message":"webhook not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooks := mocks_service.NewMockWebhooks(c)
+			tt.mockBehaviour(webhooks)
+
+			handler := NewHandler(&service.Services{Webhooks: webhooks}, nil)
+
+			r := gin.New()
+			r.GET("/api/webhooks/:id/deliveries", handler.getWebhookDeliveries)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.path, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedReqBody, w.Body.String())
+		})
+	}
+}
@@ -8,8 +8,29 @@ var (
 	ErrUserAlreadyExists     = errors.New("user already exists")
 	ErrUserNotFound          = errors.New("user not found")
 	ErrUserAlreadySubscribed = errors.New("user already subscribed")
+	ErrUserOrHouseNotFound   = errors.New("user or house not found")
+	ErrSubscriptionNotFound  = errors.New("subscription not found")
+	ErrForeignSubscription   = errors.New("subscription belongs to another user")
+	ErrInvalidUnsubscribe    = errors.New("invalid unsubscribe link")
+	ErrUnsubscribeExpired    = errors.New("unsubscribe link expired")
 	ErrFlatNotFound          = errors.New("flat not found")
 	ErrFlatAlreadyExists     = errors.New("flat already exist")
+	ErrFlatEditForbidden     = errors.New("flat may be edited by its creator or moderator only")
+	ErrFlatNotQueued         = errors.New("flat isn't awaiting moderation")
+	ErrFlatClaimed           = errors.New("flat is claimed by another moderator")
+	ErrFlatNotClaimed        = errors.New("flat isn't claimed by moderator")
+	ErrFlatModerated         = errors.New("flat is being moderated")
+	ErrInvalidTransition     = errors.New("invalid flat status transition")
+	ErrDeclineReasonNotFound = errors.New("decline reason not found")
 	ErrHouseNotFound         = errors.New("house not found")
 	ErrHouseAlreadyExists    = errors.New("house already exist")
+	ErrImportJobNotFound     = errors.New("import job not found")
+	ErrInvalidImportFile     = errors.New("invalid import file")
+	ErrTooManyImports        = errors.New("too many imports in progress")
+	ErrUndeliverable         = errors.New("message is undeliverable")
+	ErrDeadLetterNotFound    = errors.New("dead letter not found")
+	ErrTemplateNotFound      = errors.New("template not found")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrWebhookURLForbidden   = errors.New("webhook url must resolve to public addresses")
+	ErrNotificationNotFound  = errors.New("notification not found")
 )
@@ -1,11 +1,76 @@
 package domain
 
+import (
+	"github.com/google/uuid"
+	"slices"
+)
+
 type Flat struct {
 	ID         int
 	FlatNumber int // Есть условие "номер квартиры", но его почему-то нет в API.
 	Price      int
 	Rooms      int
 	Status     Status
+	// CreatedBy is id of the user created flat, nil for flats created before it was recorded and imported ones.
+	CreatedBy *uuid.UUID `json:"-"`
+}
+
+// FlatDetails is flat with summary of the house it belongs to.
+type FlatDetails struct {
+	Flat
+	House HouseSummary
+	// Approved is previously approved version of the flat, set while its later edit isn't approved.
+	Approved *FlatVersion `json:",omitempty"`
+	// Decline is set for flat declined by moderator until it's approved.
+	Decline *FlatDecline `json:",omitempty"`
+}
+
+// FlatVersion is flat attributes approved by moderator.
+type FlatVersion struct {
+	FlatNumber int
+	Price      int
+	Rooms      int
+}
+
+// ApprovedView returns flat as it was approved, hiding edit awaiting moderation.
+func (f FlatDetails) ApprovedView() FlatDetails {
+	f.FlatNumber = f.Approved.FlatNumber
+	f.Price = f.Approved.Price
+	f.Rooms = f.Approved.Rooms
+	f.Status = StatusApproved
+	f.Approved = nil
+	f.Decline = nil
+
+	return f
+}
+
+// FlatChanges are flat attributes to edit, nil ones are left as is.
+type FlatChanges struct {
+	FlatNumber *int
+	Price      *int
+	Rooms      *int
+}
+
+// VisibleTo reports whether user may see flat. Clients see approved flats only,
+// except for ones they created, and moderators see flats of any status.
+func (f Flat) VisibleTo(userId uuid.UUID, userType UserType) bool {
+	if f.Status == StatusApproved || userType == UserTypeModerator {
+		return true
+	}
+
+	return f.IsCreatedBy(userId)
+}
+
+// IsCreatedBy reports whether flat was created by user.
+func (f Flat) IsCreatedBy(userId uuid.UUID) bool {
+	return f.CreatedBy != nil && *f.CreatedBy == userId
+}
+
+type HouseSummary struct {
+	ID        int
+	Address   string
+	Year      int
+	Developer string
 }
 
 type Status string
@@ -28,3 +93,29 @@ func (s Status) Validate() bool {
 func (s Status) String() string {
 	return string(s)
 }
+
+// transitions lists statuses flat may move to from each status. Moderator approves or declines created flat,
+// and edited or resubmitted flat is created again. Flat awaiting moderation may be edited as well, staying created.
+// Flats don't stay on moderation, it lasts while moderator's claim is held, so no transitions lead from or to it.
+var transitions = map[Status][]Status{
+	StatusCreated:  {StatusApproved, StatusDeclined, StatusCreated},
+	StatusApproved: {StatusCreated},
+	StatusDeclined: {StatusCreated},
+}
+
+// NextStatuses returns statuses flat in status s may move to.
+func (s Status) NextStatuses() []Status {
+	return append([]Status{}, transitions[s]...)
+}
+
+// CanTransitionTo reports whether flat in status s may move to next status.
+func (s Status) CanTransitionTo(next Status) bool {
+	return slices.Contains(transitions[s], next)
+}
+
+// FlatTransitions are statuses flat may move to from its current status.
+type FlatTransitions struct {
+	FlatID int
+	Status Status
+	Next   []Status
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImportEntity string
+
+const (
+	ImportEntityHouses ImportEntity = "houses"
+	ImportEntityFlats  ImportEntity = "flats"
+)
+
+func (e ImportEntity) Validate() bool {
+	return e == ImportEntityHouses || e == ImportEntityFlats
+}
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV  ImportFormat = "csv"
+	ImportFormatJSON ImportFormat = "json"
+)
+
+func (f ImportFormat) Validate() bool {
+	return f == ImportFormatCSV || f == ImportFormatJSON
+}
+
+// ImportMode defines how rows are written.
+// In transaction mode whole file is imported or nothing is,
+// in chunks mode every chunk is committed separately, skipping failed rows.
+type ImportMode string
+
+const (
+	ImportModeTransaction ImportMode = "transaction"
+	ImportModeChunks      ImportMode = "chunks"
+)
+
+func (m ImportMode) Validate() bool {
+	return m == ImportModeTransaction || m == ImportModeChunks
+}
+
+type ImportStatus string
+
+const (
+	ImportStatusPending   ImportStatus = "pending"
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+type ImportJob struct {
+	ID         uuid.UUID
+	Entity     ImportEntity
+	Mode       ImportMode
+	DryRun     bool
+	Status     ImportStatus
+	Total      int
+	Succeeded  int
+	Failed     int
+	Errors     []ImportRowError
+	Error      string
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// ImportRowError describes why row wasn't imported. Rows are numbered from 1 not counting CSV header.
+type ImportRowError struct {
+	Row   int
+	Error string
+}
+
+// ImportFlat is a flat to import with house it belongs to.
+type ImportFlat struct {
+	HouseID int
+	Flat    Flat
+}
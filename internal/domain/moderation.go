@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// QueuedFlat is flat awaiting moderation. Claim is set while flat is claimed by moderator.
+type QueuedFlat struct {
+	Flat
+	HouseID        int
+	CreatedAt      time.Time
+	ClaimedBy      *uuid.UUID
+	ClaimExpiresAt *time.Time
+}
+
+// FlatClaim is moderator's lease on flat moderation. Only moderator holding the claim may
+// update flat status until it expires, after that flat returns to the queue.
+type FlatClaim struct {
+	FlatID      int
+	ModeratorID uuid.UUID
+	ExpiresAt   time.Time
+}
+
+// DeclineReason is entry of the catalogue moderators choose decline reason from.
+// Checklist lists what creator should check before resubmitting the flat.
+type DeclineReason struct {
+	Code       string
+	Title      string
+	Checklist  []string
+	ArchivedAt *time.Time `json:",omitempty"`
+}
+
+// FlatDecline explains to creator why flat was declined.
+type FlatDecline struct {
+	Reason  DeclineReason
+	Comment string
+}
+
+// FlatStatusChange is entry of flat status history. OldStatus is empty for the change flat was created with,
+// Actor is nil for changes made by the service itself, e.g. flat sent back to moderation queue.
+// Reason is set for declines.
+type FlatStatusChange struct {
+	OldStatus Status `json:",omitempty"`
+	NewStatus Status
+	Actor     *uuid.UUID `json:",omitempty"`
+	Reason    string     `json:",omitempty"`
+	CreatedAt time.Time
+}
+
+// FlatStatusRecord is status change of the flat, as listed apart from flat history.
+type FlatStatusRecord struct {
+	FlatID int
+	FlatStatusChange
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Notification is record in user in-app inbox, kept for every notification sent to user.
+type Notification struct {
+	ID        int64
+	UserID    uuid.UUID `json:"-"`
+	OutboxID  int64     `json:"-"`
+	EventType EventType
+	Title     string
+	Body      string
+	Payload   json.RawMessage `swaggertype:"object"`
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+)
+
+// NotificationSettings are user contacts and preferences of channels notifications are delivered through.
+type NotificationSettings struct {
+	Phone     *string
+	PushToken *string
+	// Timezone quiet hours are in, IANA name.
+	Timezone   string
+	QuietHours *QuietHours
+	// Channels user chose for event types, defaults are used for other ones.
+	Channels map[EventType][]Channel
+}
+
+const DefaultTimezone = "UTC"
+
+// QuietHours is daily period, possibly spanning midnight, intrusive channels are delayed during.
+type QuietHours struct {
+	// Start and End are local times in "15:04" format.
+	Start string
+	End   string
+}
+
+const QuietHoursLayout = "15:04"
+
+// Until returns end of quiet hours if now is within them.
+func (q QuietHours) Until(now time.Time, loc *time.Location) (time.Time, bool) {
+	start, err := time.Parse(QuietHoursLayout, q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	end, err := time.Parse(QuietHoursLayout, q.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minutes := func(t time.Time) int { return t.Hour()*60 + t.Minute() }
+	cur, from, to := minutes(local), minutes(start), minutes(end)
+
+	var within bool
+	switch {
+	case from < to:
+		within = cur >= from && cur < to
+	case from > to:
+		within = cur >= from || cur < to
+	}
+	if !within {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	return until, true
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventFlatApproved EventType = "flat_approved"
+	// EventFlatsDigest aggregates flats approved since previous digest of the subscriber.
+	EventFlatsDigest EventType = "flats_digest"
+)
+
+// EventTypes lists all event types.
+var EventTypes = []EventType{EventFlatApproved, EventFlatsDigest}
+
+func (e EventType) Validate() bool {
+	for _, eventType := range EventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Channel message is delivered through.
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	// ChannelSMS messages are addressed to phone number.
+	ChannelSMS Channel = "sms"
+	// ChannelPush messages are addressed to device push token.
+	ChannelPush Channel = "push"
+	// ChannelInApp messages are stored in inbox of user with id in recipient.
+	ChannelInApp Channel = "in_app"
+	// ChannelWebhook messages are addressed to webhook with id in recipient.
+	ChannelWebhook Channel = "webhook"
+	// ChannelUser messages are addressed to user with email in recipient
+	// and are routed to channels chosen by the user on dispatch.
+	ChannelUser Channel = "user"
+)
+
+// UserChannels lists channels user may choose to receive notifications through.
+var UserChannels = []Channel{ChannelEmail, ChannelSMS, ChannelPush, ChannelInApp}
+
+// Validate reports whether user may choose channel.
+func (c Channel) Validate() bool {
+	for _, channel := range UserChannels {
+		if c == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OutboxMessage is a notification for a single recipient stored together with the change that caused it.
+type OutboxMessage struct {
+	ID        int64
+	EventType EventType
+	Channel   Channel
+	Recipient string
+	// UserID of the recipient, nil if recipient isn't a registered user.
+	UserID    *uuid.UUID
+	Payload   json.RawMessage `swaggertype:"object"`
+	Attempts  int
+	CreatedAt time.Time
+	// AvailableAt is time message is dispatched not earlier than.
+	AvailableAt time.Time
+	// Language of the recipient, empty if recipient isn't a registered user.
+	Language Language
+}
+
+// FlatApprovedPayload describes flat that became visible to clients.
+type FlatApprovedPayload struct {
+	HouseID    int `json:"house_id"`
+	FlatID     int `json:"flat_id"`
+	FlatNumber int `json:"flat_number"`
+	Rooms      int `json:"rooms"`
+	Price      int `json:"price"`
+}
+
+// FlatsDigestPayload lists flats approved in houses subscriber is notified about with digests.
+// Flat approved several times within digest period is listed once, as of the last approval.
+type FlatsDigestPayload struct {
+	Delivery Delivery              `json:"delivery"`
+	Flats    []FlatApprovedPayload `json:"flats"`
+}
+
+// DeadLetter is outbox message that couldn't be delivered and won't be retried until re-driven.
+type DeadLetter struct {
+	ID        int64
+	OutboxID  int64
+	EventType EventType
+	Channel   Channel
+	Recipient string
+	UserID    *uuid.UUID
+	Payload   json.RawMessage `swaggertype:"object"`
+	Attempts  int
+	LastError string
+	CreatedAt time.Time
+	FailedAt  time.Time
+}
+
+// NotificationTemplate lists locales notification of event type is available in.
+type NotificationTemplate struct {
+	EventType EventType
+	Locales   []string
+}
+
+// NotificationPreview is notification rendered with sample data.
+type NotificationPreview struct {
+	EventType EventType
+	Locale    string
+	Subject   string
+	Text      string
+	HTML      string
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// Subscription of user to new flats of the house.
+// Nil bounds are not checked, so subscription without them matches any flat.
+type Subscription struct {
+	HouseID   int
+	Email     string
+	MinRooms  *int
+	MaxRooms  *int
+	MinPrice  *int
+	MaxPrice  *int
+	Delivery  Delivery
+	CreatedAt time.Time
+}
+
+// Unsubscription is removal of user email subscriptions to houses by unsubscribe link.
+type Unsubscription struct {
+	Email    string
+	HouseIDs []int
+}
+
+// Delivery is how subscriber is notified about new flats:
+// right away or with a single digest of all flats once a day or a week.
+type Delivery string
+
+const (
+	DeliveryImmediate Delivery = "immediate"
+	DeliveryDaily     Delivery = "daily"
+	DeliveryWeekly    Delivery = "weekly"
+)
+
+func (d Delivery) Validate() bool {
+	switch d {
+	case DeliveryImmediate, DeliveryDaily, DeliveryWeekly:
+		return true
+	}
+
+	return false
+}
@@ -9,6 +9,20 @@ type User struct {
 	Email    string
 	Password string
 	UserType UserType
+	Language Language
+}
+
+// AccountExport is all data stored about user, handed over to them on request.
+type AccountExport struct {
+	ID                   uuid.UUID
+	Email                string
+	UserType             UserType
+	Language             Language
+	NotificationSettings NotificationSettings
+	Subscriptions        []Subscription
+	Flats                []FlatDetails
+	// StatusHistory is status changes of flats created by user and ones made by user, oldest first.
+	StatusHistory []FlatStatusRecord
 }
 
 type UserType string
@@ -25,3 +39,17 @@ func (u UserType) Validate() bool {
 func (u UserType) String() string {
 	return string(u)
 }
+
+// Language user receives notifications in.
+type Language string
+
+const (
+	LanguageRu Language = "ru"
+	LanguageEn Language = "en"
+
+	DefaultLanguage = LanguageRu
+)
+
+func (l Language) Validate() bool {
+	return l == LanguageRu || l == LanguageEn
+}
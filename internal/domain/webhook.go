@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventTypes lists event types posted to webhooks. Digests are sent to users only.
+var WebhookEventTypes = []EventType{EventFlatApproved}
+
+// Webhook is partner endpoint events are posted to.
+// Endpoint is disabled after too many deliveries in a row failed.
+type Webhook struct {
+	ID                  int64
+	URL                 string
+	EventTypes          []EventType
+	Secret              string `json:"-"`
+	Active              bool
+	ConsecutiveFailures int
+	DisabledAt          *time.Time
+	CreatedAt           time.Time
+}
+
+// WebhookDelivery is log record of an attempt to post event to webhook, Attempts is its number.
+type WebhookDelivery struct {
+	ID         int64
+	WebhookID  int64
+	OutboxID   int64
+	EventType  EventType
+	Success    bool
+	StatusCode *int
+	Attempts   int
+	DurationMs int
+	Error      *string
+	CreatedAt  time.Time
+}
+
+// WebhookEvent is request body posted to webhook.
+type WebhookEvent struct {
+	ID        int64           `json:"id"`
+	Event     EventType       `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+}
@@ -3,6 +3,7 @@ package dtos
 import (
 	"errors"
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"strings"
 )
 
 type FlatCreateInput struct {
@@ -12,9 +13,33 @@ type FlatCreateInput struct {
 	Rooms      int `json:"rooms" binding:"required"`
 }
 
+// Validate applies the same rules to the input as /flat/create does while binding it.
+func (f *FlatCreateInput) Validate() error {
+	return validateBinding(f)
+}
+
+// FlatEditInput holds flat attributes to change, omitted ones are left as is.
+type FlatEditInput struct {
+	FlatNumber *int `json:"flat_number" binding:"omitempty,gt=0"`
+	Price      *int `json:"price" binding:"omitempty,gt=0"`
+	Rooms      *int `json:"rooms" binding:"omitempty,gt=0"`
+}
+
+func (f *FlatEditInput) Validate() error {
+	if f.FlatNumber == nil && f.Price == nil && f.Rooms == nil {
+		return errors.New("nothing to edit")
+	}
+
+	return nil
+}
+
 type FlatUpdateInput struct {
 	FlatId int           `json:"flat_id" binding:"required"`
 	Status domain.Status `json:"status" binding:"required"`
+	// Reason is code of decline reason from the catalogue, Comment explains it to the flat creator.
+	// Both are required when declining flat and not allowed otherwise.
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
 }
 
 func (s *FlatUpdateInput) Validate() error {
@@ -26,5 +51,13 @@ func (s *FlatUpdateInput) Validate() error {
 		return errors.New("invalid status")
 	}
 
+	if s.Status == domain.StatusDeclined {
+		if s.Reason == "" || strings.TrimSpace(s.Comment) == "" {
+			return errors.New("reason and comment are required when declining")
+		}
+	} else if s.Reason != "" || s.Comment != "" {
+		return errors.New("reason and comment are allowed when declining only")
+	}
+
 	return nil
 }
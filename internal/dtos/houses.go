@@ -1,11 +1,52 @@
 package dtos
 
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+)
+
 type HouseCreateInput struct {
 	Address   string `json:"address" binding:"required"`
 	Year      int    `json:"year" binding:"required"`
 	Developer string `json:"developer,omitempty"`
 }
 
+// Validate applies the same rules to the input as /house/create does while binding it.
+func (h *HouseCreateInput) Validate() error {
+	return validateBinding(h)
+}
+
 type HouseSubscribeInput struct {
+	Email    string `json:"email" binding:"required"`
+	MinRooms *int   `json:"min_rooms,omitempty" binding:"omitempty,gte=0"`
+	MaxRooms *int   `json:"max_rooms,omitempty" binding:"omitempty,gte=0"`
+	MinPrice *int   `json:"min_price,omitempty" binding:"omitempty,gte=0"`
+	MaxPrice *int   `json:"max_price,omitempty" binding:"omitempty,gte=0"`
+	// Delivery is "immediate" by default.
+	Delivery domain.Delivery `json:"delivery,omitempty"`
+}
+
+// Validate checks that filter ranges are not empty and delivery is known.
+func (h *HouseSubscribeInput) Validate() error {
+	if h.Delivery != "" && !h.Delivery.Validate() {
+		return errors.New("invalid delivery")
+	}
+
+	if h.MinRooms != nil && h.MaxRooms != nil && *h.MinRooms > *h.MaxRooms {
+		return errors.New("min_rooms is greater than max_rooms")
+	}
+
+	if h.MinPrice != nil && h.MaxPrice != nil && *h.MinPrice > *h.MaxPrice {
+		return errors.New("min_price is greater than max_price")
+	}
+
+	return nil
+}
+
+type SubscriptionDeliveryInput struct {
+	Delivery domain.Delivery `json:"delivery" binding:"required"`
+}
+
+type HouseUnsubscribeInput struct {
 	Email string `json:"email" binding:"required"`
 }
@@ -0,0 +1,53 @@
+package dtos
+
+import (
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+)
+
+const (
+	DefaultImportChunkSize = 100
+	MaxImportChunkSize     = 1000
+)
+
+type ImportInput struct {
+	Entity    domain.ImportEntity `json:"entity"`
+	Format    domain.ImportFormat `json:"format" form:"format"`
+	Mode      domain.ImportMode   `json:"mode" form:"mode"`
+	ChunkSize int                 `json:"chunk_size" form:"chunk_size"`
+	DryRun    bool                `json:"dry_run" form:"dry_run"`
+}
+
+// WithDefaults returns import options with omitted mode and chunk size filled in.
+func (i ImportInput) WithDefaults() ImportInput {
+	if i.Mode == "" {
+		i.Mode = domain.ImportModeTransaction
+	}
+
+	if i.ChunkSize == 0 {
+		i.ChunkSize = DefaultImportChunkSize
+	}
+
+	return i
+}
+
+// Validate checks import options, defaults must be filled in with WithDefaults beforehand.
+func (i ImportInput) Validate() error {
+	if !i.Entity.Validate() {
+		return errors.New("invalid import entity")
+	}
+
+	if !i.Format.Validate() {
+		return errors.New("invalid import format")
+	}
+
+	if !i.Mode.Validate() {
+		return errors.New("invalid import mode")
+	}
+
+	if i.ChunkSize <= 0 || i.ChunkSize > MaxImportChunkSize {
+		return errors.New("invalid chunk_size")
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package dtos
+
+import (
+	"errors"
+	"regexp"
+)
+
+type ModerationQueueInput struct {
+	PageInput
+	// HouseId limits queue to flats of the house.
+	HouseId int `form:"house_id" binding:"omitempty,min=1"`
+}
+
+var declineReasonCode = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+type DeclineReasonInput struct {
+	Code      string   `json:"-"`
+	Title     string   `json:"title" binding:"required,max=255"`
+	Checklist []string `json:"checklist" binding:"max=20,dive,required,max=255"`
+}
+
+// Validate checks code taken from path, as body is validated while binding.
+func (r *DeclineReasonInput) Validate() error {
+	if !declineReasonCode.MatchString(r.Code) {
+		return errors.New("invalid reason code")
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"time"
+)
+
+type QuietHoursInput struct {
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+}
+
+// NotificationSettingsInput replaces all notification settings of user.
+type NotificationSettingsInput struct {
+	Phone     *string `json:"phone,omitempty"`
+	PushToken *string `json:"push_token,omitempty"`
+	// Timezone is IANA name quiet hours are in, UTC if empty.
+	Timezone   string           `json:"timezone,omitempty"`
+	QuietHours *QuietHoursInput `json:"quiet_hours,omitempty"`
+	// Channels for event types, defaults are used for omitted ones and no notifications are sent for empty ones.
+	Channels map[domain.EventType][]domain.Channel `json:"channels,omitempty"`
+}
+
+// Validate checks timezone, quiet hours and channels, and that user has contacts for chosen channels.
+func (n *NotificationSettingsInput) Validate() error {
+	if n.Timezone != "" {
+		if _, err := time.LoadLocation(n.Timezone); err != nil {
+			return errors.New("unknown timezone")
+		}
+	}
+
+	if n.QuietHours != nil {
+		_, startErr := time.Parse(domain.QuietHoursLayout, n.QuietHours.Start)
+		_, endErr := time.Parse(domain.QuietHoursLayout, n.QuietHours.End)
+		if startErr != nil || endErr != nil {
+			return errors.New("quiet hours must be in HH:MM format")
+		}
+	}
+
+	for eventType, channels := range n.Channels {
+		if !eventType.Validate() {
+			return fmt.Errorf("unknown event type '%s'", eventType)
+		}
+
+		for _, channel := range channels {
+			if !channel.Validate() {
+				return fmt.Errorf("unknown channel '%s'", channel)
+			}
+
+			if channel == domain.ChannelSMS && (n.Phone == nil || *n.Phone == "") {
+				return errors.New("phone is required for sms channel")
+			}
+
+			if channel == domain.ChannelPush && (n.PushToken == nil || *n.PushToken == "") {
+				return errors.New("push token is required for push channel")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Settings returns domain settings input describes.
+func (n *NotificationSettingsInput) Settings() domain.NotificationSettings {
+	settings := domain.NotificationSettings{
+		Phone:     n.Phone,
+		PushToken: n.PushToken,
+		Timezone:  n.Timezone,
+		Channels:  n.Channels,
+	}
+
+	if settings.Timezone == "" {
+		settings.Timezone = domain.DefaultTimezone
+	}
+
+	if n.QuietHours != nil {
+		settings.QuietHours = &domain.QuietHours{Start: n.QuietHours.Start, End: n.QuietHours.End}
+	}
+
+	return settings
+}
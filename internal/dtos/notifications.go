@@ -0,0 +1,7 @@
+package dtos
+
+type NotificationsInput struct {
+	PageInput
+	// Unread limits notifications to unread ones.
+	Unread bool `form:"unread"`
+}
@@ -0,0 +1,20 @@
+package dtos
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+type PageInput struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// Normalize fills in default limit and caps it with MaxPageLimit.
+func (p *PageInput) Normalize() {
+	if p.Limit == 0 {
+		p.Limit = DefaultPageLimit
+	}
+
+	p.Limit = min(p.Limit, MaxPageLimit)
+}
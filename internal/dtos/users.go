@@ -10,6 +10,7 @@ type UserRegisterInput struct {
 	Email    string          `json:"email" binding:"required"`
 	Password string          `json:"password" binding:"required"`
 	UserType domain.UserType `json:"userType" binding:"required"`
+	Language domain.Language `json:"language,omitempty"`
 }
 
 type UserLoginInput struct {
@@ -22,6 +23,10 @@ func (u UserRegisterInput) Validate() error {
 		return errors.New("invalid user type")
 	}
 
+	if u.Language != "" && !u.Language.Validate() {
+		return errors.New("invalid language")
+	}
+
 	if u.Password == "" {
 		return errors.New("invalid password")
 	}
@@ -44,3 +49,7 @@ func (u *UserLoginInput) Validate() error {
 
 	return nil
 }
+
+type UserLanguageInput struct {
+	Language domain.Language `json:"language" binding:"required"`
+}
@@ -0,0 +1,9 @@
+package dtos
+
+import "github.com/gin-gonic/gin/binding"
+
+// validateBinding checks struct against its `binding` tags,
+// exactly as gin does when binding request body.
+func validateBinding(obj any) error {
+	return binding.Validator.ValidateStruct(obj)
+}
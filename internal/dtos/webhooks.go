@@ -0,0 +1,32 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"net/url"
+	"slices"
+)
+
+type WebhookCreateInput struct {
+	URL        string             `json:"url" binding:"required"`
+	EventTypes []domain.EventType `json:"event_types" binding:"required,min=1"`
+	// Secret payloads are signed with, generated if empty.
+	Secret string `json:"secret,omitempty" binding:"omitempty,min=16"`
+}
+
+// Validate checks that URL is absolute http(s) one and event types are ones posted to webhooks.
+func (w *WebhookCreateInput) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be absolute http or https url")
+	}
+
+	for _, eventType := range w.EventTypes {
+		if !slices.Contains(domain.WebhookEventTypes, eventType) {
+			return fmt.Errorf("unknown event type '%s'", eventType)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// errBatchRollback is used to roll back batch transaction on purpose.
+var errBatchRollback = errors.New("batch rolled back")
+
+// execBatch runs fn for every of n batch items inside a single transaction.
+// Each item is isolated with a savepoint, so a failing item doesn't abort the others
+// and its error is reported at the same index of returned slice.
+// Transaction is rolled back if dryRun is set or if atomic is set and any item failed.
+func execBatch(ctx context.Context, db *pgxpool.Pool, n int, atomic, dryRun bool, fn func(ctx context.Context, tx pgx.Tx, i int) error) ([]error, error) {
+	itemErrs := make([]error, n)
+	var failed bool
+
+	err := pgx.BeginTxFunc(ctx, db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		for i := 0; i < n; i++ {
+			// Nested transaction is a savepoint.
+			err := pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+				return fn(ctx, sp, i)
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				itemErrs[i] = err
+				failed = true
+			}
+		}
+
+		if dryRun || (atomic && failed) {
+			return errBatchRollback
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRollback) {
+		return nil, err
+	}
+
+	return itemErrs, nil
+}
+
+// wrapBatchErr prefixes every non-nil item error with op.
+func wrapBatchErr(op string, errs []error) []error {
+	for i, err := range errs {
+		if err != nil {
+			errs[i] = fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/cache"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// houseFlatsKey identifies cached house flats list. Lists differ between user types,
+// and client lists include flats of the client, so they are cached per user.
+type houseFlatsKey struct {
+	houseId  int
+	userType string
+	userId   uuid.UUID
+}
+
+// houseFlats is cached house flats list along with house modification time it was read at.
+type houseFlats struct {
+	flats    []domain.Flat
+	modified time.Time
+}
+
+// HousesCache keeps house flats lists read by Houses.GetById.
+// It validates lists on every read: list is served only while house modification time is the one
+// it was read at, so that writes from other processes, e.g. import CLI, aren't hidden by the cache.
+// Cache hit therefore still costs a query of house modification time by primary key, it saves
+// the flats query only. Lists expire after ttl as well.
+type HousesCache struct {
+	lru *cache.LRU[houseFlatsKey, houseFlats]
+
+	// Generation of a house is bumped on every invalidation,
+	// so that list read before the change isn't cached after it.
+	// Epoch is bumped when all houses are invalidated at once.
+	mu          sync.Mutex
+	epoch       uint64
+	generations map[int]uint64
+}
+
+func NewHousesCache(size int, ttl time.Duration) *HousesCache {
+	return &HousesCache{
+		lru:         cache.NewLRU[houseFlatsKey, houseFlats](size, ttl),
+		generations: make(map[int]uint64),
+	}
+}
+
+func (c *HousesCache) Stats() cache.Stats {
+	return c.lru.Stats()
+}
+
+func (c *HousesCache) generation(houseId int) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.epoch + c.generations[houseId]
+}
+
+// get returns cached list if it was read when house was last modified.
+func (c *HousesCache) get(key houseFlatsKey, modified time.Time) ([]domain.Flat, bool) {
+	entry, ok := c.lru.Get(key)
+	if !ok || !entry.modified.Equal(modified) {
+		return nil, false
+	}
+
+	// Callers may modify returned list.
+	return append([]domain.Flat(nil), entry.flats...), true
+}
+
+// set caches flats read at house modification time if house wasn't invalidated since generation was taken.
+func (c *HousesCache) set(key houseFlatsKey, flats []domain.Flat, modified time.Time, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.epoch+c.generations[key.houseId] == generation {
+		c.lru.Set(key, houseFlats{flats: append([]domain.Flat(nil), flats...), modified: modified})
+	}
+}
+
+func (c *HousesCache) invalidate(houseId int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[houseId]++
+	c.lru.DeleteFunc(func(key houseFlatsKey) bool {
+		return key.houseId == houseId
+	})
+}
+
+func (c *HousesCache) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	c.lru.DeleteFunc(func(key houseFlatsKey) bool {
+		return true
+	})
+}
+
+// WithHousesCache returns repository serving house flats lists from cache.
+// Flats and houses writes going through returned repository invalidate cached lists.
+func (r *Repository) WithHousesCache(c *HousesCache) *Repository {
+	cached := *r
+	cached.Houses = &CachedHousesRepo{Houses: r.Houses, cache: c}
+	cached.Flats = &CachedFlatsRepo{Flats: r.Flats, cache: c}
+
+	return &cached
+}
+
+type CachedHousesRepo struct {
+	Houses
+	cache *HousesCache
+}
+
+// GetById returns house flats list, cached one if house wasn't modified since it was read.
+func (r *CachedHousesRepo) GetById(ctx context.Context, id int) ([]domain.Flat, error) {
+	key := houseFlatsKey{houseId: id, userType: userTypeFromCtx(ctx)}
+	if userId := userIdFromCtx(ctx); userId != nil && key.userType != string(domain.UserTypeModerator) {
+		key.userId = *userId
+	}
+
+	// Modification time is read before flats, so that cached list is never older than the time it's cached with.
+	modified, err := r.Houses.GetUpdatedAt(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if flats, ok := r.cache.get(key, modified); ok {
+		return flats, nil
+	}
+
+	generation := r.cache.generation(id)
+
+	flats, err := r.Houses.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.set(key, flats, modified, generation)
+
+	return flats, nil
+}
+
+func (r *CachedHousesRepo) Create(ctx context.Context, house domain.House) (domain.House, error) {
+	resp, err := r.Houses.Create(ctx, house)
+	if err == nil {
+		// Empty list might have been cached for id before house existed.
+		r.cache.invalidate(resp.ID)
+	}
+
+	return resp, err
+}
+
+func (r *CachedHousesRepo) CreateBatch(ctx context.Context, houses []domain.House, atomic, dryRun bool) ([]error, error) {
+	errs, err := r.Houses.CreateBatch(ctx, houses, atomic, dryRun)
+	if err == nil && !dryRun {
+		r.cache.invalidateAll()
+	}
+
+	return errs, err
+}
+
+type CachedFlatsRepo struct {
+	Flats
+	cache *HousesCache
+}
+
+func (r *CachedFlatsRepo) Create(ctx context.Context, houseId int, flat domain.Flat) (domain.Flat, error) {
+	resp, err := r.Flats.Create(ctx, houseId, flat)
+	if err == nil {
+		r.cache.invalidate(houseId)
+	}
+
+	return resp, err
+}
+
+func (r *CachedFlatsRepo) CreateBatch(ctx context.Context, flats []domain.ImportFlat, atomic, dryRun bool) ([]error, error) {
+	errs, err := r.Flats.CreateBatch(ctx, flats, atomic, dryRun)
+	if err == nil && !dryRun {
+		for i, flat := range flats {
+			if errs[i] == nil {
+				r.cache.invalidate(flat.HouseID)
+			}
+		}
+	}
+
+	return errs, err
+}
+
+func (r *CachedFlatsRepo) Update(ctx context.Context, flatId int, moderatorId uuid.UUID, status string, decline *domain.FlatDecline) (domain.Flat, error) {
+	defer r.invalidateFlatHouse(ctx, flatId)
+
+	return r.Flats.Update(ctx, flatId, moderatorId, status, decline)
+}
+
+func (r *CachedFlatsRepo) Edit(ctx context.Context, flatId int, userId uuid.UUID, changes domain.FlatChanges) (domain.Flat, error) {
+	defer r.invalidateFlatHouse(ctx, flatId)
+
+	return r.Flats.Edit(ctx, flatId, userId, changes)
+}
+
+// invalidateFlatHouse drops cached lists of the house flat belongs to.
+// Status might have changed even if write failed, so it's called regardless of the result.
+func (r *CachedFlatsRepo) invalidateFlatHouse(ctx context.Context, flatId int) {
+	// Write may have failed because of cancelled context, house must be found anyway.
+	houseId, err := r.Flats.GetHouseId(context.WithoutCancel(ctx), flatId)
+	if err != nil {
+		return
+	}
+
+	r.cache.invalidate(houseId)
+}
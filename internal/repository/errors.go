@@ -7,9 +7,17 @@ var (
 	ErrUserNotFound          = errors.New("user not found")
 	ErrUserOrHouseNotFound   = errors.New("user or house not found")
 	ErrUserAlreadySubscribed = errors.New("user already subscribed")
+	ErrSubscriptionNotFound  = errors.New("subscription not found")
 	ErrFlatNotFound          = errors.New("flat not found")
 	ErrFlatAlreadyExists     = errors.New("flat already exist")
 	ErrHouseNotFound         = errors.New("house not found")
 	ErrHouseAlreadyExists    = errors.New("house already exist")
-	ErrFlatOnModeration      = errors.New("flat on moderation")
+	ErrFlatNotQueued         = errors.New("flat isn't awaiting moderation")
+	ErrFlatClaimed           = errors.New("flat is claimed by another moderator")
+	ErrFlatNotClaimed        = errors.New("flat isn't claimed by moderator")
+	ErrInvalidTransition     = errors.New("invalid flat status transition")
+	ErrDeclineReasonNotFound = errors.New("decline reason not found")
+	ErrDeadLetterNotFound    = errors.New("dead letter not found")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrNotificationNotFound  = errors.New("notification not found")
 )
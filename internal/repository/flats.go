@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -39,16 +40,55 @@ func (r *FlatsRepo) Create(ctx context.Context, houseId int, flat domain.Flat) (
 		}
 	}()
 
+	flat.ID, err = r.insert(ctx, tx, houseId, flat)
+	if err != nil {
+		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return flat, nil
+}
+
+// CreateBatch creates flats in a single transaction, reporting error for each of them separately.
+// See execBatch for atomic and dryRun meaning.
+func (r *FlatsRepo) CreateBatch(ctx context.Context, flats []domain.ImportFlat, atomic, dryRun bool) ([]error, error) {
+	const op = "repository.Flats.CreateBatch"
+
+	errs, err := execBatch(ctx, r.db, len(flats), atomic, dryRun, func(ctx context.Context, tx pgx.Tx, i int) error {
+		_, err := r.insert(ctx, tx, flats[i].HouseID, flats[i].Flat)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return wrapBatchErr(op, errs), nil
+}
+
+// insert adds flat to the house within tx and returns flat id.
+func (r *FlatsRepo) insert(ctx context.Context, tx querier, houseId int, flat domain.Flat) (int, error) {
+	// Flat created approved, e.g. imported one, is its own approved version.
+	var approvedNumber, approvedPrice, approvedRooms *int
+	if flat.Status == domain.StatusApproved {
+		approvedNumber, approvedPrice, approvedRooms = &flat.FlatNumber, &flat.Price, &flat.Rooms
+	}
+
 	// Create flat
 	query, args, err := squirrel.
 		Insert(flatsTable).
-		Columns("flat_number", "price", "rooms", "status").
-		Values(flat.FlatNumber, flat.Price, flat.Rooms, flat.Status).
+		Columns("house_id", "flat_number", "price", "rooms", "status", "created_by",
+			"approved_flat_number", "approved_price", "approved_rooms").
+		Values(houseId, flat.FlatNumber, flat.Price, flat.Rooms, flat.Status, flat.CreatedBy,
+			approvedNumber, approvedPrice, approvedRooms).
 		PlaceholderFormat(squirrel.Dollar).
 		Suffix("RETURNING id").
 		ToSql()
 	if err != nil {
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+		return 0, err
 	}
 
 	var flatId int
@@ -57,98 +97,295 @@ func (r *FlatsRepo) Create(ctx context.Context, houseId int, flat domain.Flat) (
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			if pgErr.Code == pgerrcode.UniqueViolation {
-				return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrFlatAlreadyExists)
+				return 0, ErrFlatAlreadyExists
 			}
-		}
 
-		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrHouseNotFound)
+			if pgErr.Code == pgerrcode.ForeignKeyViolation {
+				return 0, ErrHouseNotFound
+			}
 		}
 
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+		return 0, err
+	}
+
+	err = r.recordStatusChange(ctx, tx, flatId, domain.FlatStatusChange{NewStatus: flat.Status, Actor: flat.CreatedBy})
+	if err != nil {
+		return 0, err
 	}
 
-	// Add flat to house'tests flats
+	// Update house
 	query, args, err = squirrel.
-		Insert(houseFlatsTable).
-		Columns("flat_number", "flat_id", "house_id").
-		Values(flat.FlatNumber, flatId, houseId).
+		Update(housesTable).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": houseId}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+		return 0, err
 	}
 
 	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == pgerrcode.UniqueViolation {
-				return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrFlatAlreadyExists)
-			}
-		}
+		return 0, err
+	}
+
+	return flatId, nil
+}
 
-		if pgErr.Code == pgerrcode.ForeignKeyViolation {
-			return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrHouseNotFound)
+// GetById returns flat with summary of its house regardless of flat status,
+// along with its approved version if the flat was edited after approval.
+func (r *FlatsRepo) GetById(ctx context.Context, flatId int) (domain.FlatDetails, error) {
+	const op = "repository.Flats.GetById"
+
+	query, args, err := r.selectDetails().
+		Where(squirrel.Eq{"f.id": flatId}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.FlatDetails{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	f, err := scanDetails(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.FlatDetails{}, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
 		}
 
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+		return domain.FlatDetails{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// Update house
-	query, args, err = squirrel.
-		Update(housesTable).
-		Set("updated_at", time.Now()).
-		Where(squirrel.Eq{"id": houseId}).
+	return f, nil
+}
+
+// GetByCreator returns flats created by user in any status, newest first.
+func (r *FlatsRepo) GetByCreator(ctx context.Context, userId uuid.UUID) ([]domain.FlatDetails, error) {
+	const op = "repository.Flats.GetByCreator"
+
+	query, args, err := r.selectDetails().
+		Where(squirrel.Eq{"f.created_by": userId}).
+		OrderBy("f.id DESC").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	_, err = tx.Exec(ctx, query, args...)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
 
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+	flats := []domain.FlatDetails{}
+	for rows.Next() {
+		f, err := scanDetails(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		flats = append(flats, f)
 	}
 
-	err = tx.Commit(ctx)
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return flats, nil
+}
+
+// selectDetails selects flats with their approved versions, decline explanations
+// and summaries of their houses, see scanDetails.
+func (r *FlatsRepo) selectDetails() squirrel.SelectBuilder {
+	return squirrel.
+		Select("f.id", "f.flat_number", "f.price", "f.rooms", "f.status", "f.created_by",
+			"f.approved_flat_number", "f.approved_price", "f.approved_rooms",
+			"h.id", "h.address", "h.year", "coalesce(h.developer, '')",
+			"d.code", "d.title", "d.checklist", "f.decline_comment").
+		From(flatsTable + " f").
+		Join(housesTable + " h ON h.id = f.house_id").
+		LeftJoin(declineReasonsTable + " d ON d.code = f.decline_reason")
+}
+
+func scanDetails(row pgx.Row) (domain.FlatDetails, error) {
+	var (
+		f                                            domain.FlatDetails
+		approvedNumber, approvedPrice, approvedRooms *int
+		declineCode, declineTitle, declineComment    *string
+		declineChecklist                             []string
+	)
+	err := row.Scan(&f.ID, &f.FlatNumber, &f.Price, &f.Rooms, &f.Status, &f.CreatedBy,
+		&approvedNumber, &approvedPrice, &approvedRooms,
+		&f.House.ID, &f.House.Address, &f.House.Year, &f.House.Developer,
+		&declineCode, &declineTitle, &declineChecklist, &declineComment)
 	if err != nil {
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+		return domain.FlatDetails{}, err
 	}
 
-	// Add id to response
-	flat.ID = flatId
+	if declineCode != nil {
+		f.Decline = &domain.FlatDecline{
+			Reason:  domain.DeclineReason{Code: *declineCode, Title: *declineTitle, Checklist: declineChecklist},
+			Comment: *declineComment,
+		}
+	}
 
-	return flat, nil
+	// Approved flat is its own approved version, it's reported only while it differs from the current one.
+	if approvedPrice != nil && f.Status != domain.StatusApproved {
+		f.Approved = &domain.FlatVersion{FlatNumber: *approvedNumber, Price: *approvedPrice, Rooms: *approvedRooms}
+	}
+
+	return f, nil
+}
+
+// GetHouseId returns id of the house flat belongs to.
+func (r *FlatsRepo) GetHouseId(ctx context.Context, flatId int) (int, error) {
+	const op = "repository.Flats.GetHouseId"
+
+	houseId, err := r.getHouseId(ctx, r.db, flatId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return houseId, nil
 }
 
-func (r *FlatsRepo) Update(ctx context.Context, flatId int, status string) (domain.Flat, error) {
+func (r *FlatsRepo) getHouseId(ctx context.Context, q querier, flatId int) (int, error) {
+	query, args, err := squirrel.
+		Select("house_id").
+		From(flatsTable).
+		Where(squirrel.Eq{"id": flatId}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var houseId int
+	err = q.QueryRow(ctx, query, args...).Scan(&houseId)
+
+	return houseId, err
+}
+
+// Update sets status of flat claimed by moderator and releases the claim. Flat row is locked while status transition
+// and the claim are checked, so that of concurrent calls only one succeeds and the others find flat moderated.
+// Approving flat makes it the approved version and enqueues notifications for matching house subscribers.
+// Other statuses keep approved version of the edited flat, so that clients still see it,
+// while unedited flat loses it and gets hidden from clients.
+// Decline explanation replaces previous one, nil decline clears it.
+// Status change is recorded in flat history on behalf of moderator.
+func (r *FlatsRepo) Update(ctx context.Context, flatId int, moderatorId uuid.UUID, status string, decline *domain.FlatDecline) (domain.Flat, error) {
 	const op = "repository.Flats.Update"
 
 	// To simulate slow network
 	// time.Sleep(5 * time.Second)
 
-	query, args, err := squirrel.
+	var declineReason, declineComment *string
+	if decline != nil {
+		declineReason, declineComment = &decline.Reason.Code, &decline.Comment
+	}
+
+	lock, lockArgs, err := squirrel.
+		Select("status").
+		Column("coalesce(claimed_by = ? AND claim_expires_at > now(), false)", moderatorId).
+		From(flatsTable).
+		Where(squirrel.Eq{"id": flatId}).
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Decision is made, so moderator's claim is released.
+	builder := squirrel.
 		Update(flatsTable).
 		Set("status", status).
+		Set("decline_reason", declineReason).
+		Set("decline_comment", declineComment).
+		Set("claimed_by", nil).
+		Set("claim_expires_at", nil)
+
+	if domain.Status(status) == domain.StatusApproved {
+		builder = builder.SetMap(map[string]any{
+			"approved_flat_number": squirrel.Expr("flat_number"),
+			"approved_price":       squirrel.Expr("price"),
+			"approved_rooms":       squirrel.Expr("rooms"),
+		})
+	} else {
+		const unedited = "flat_number = approved_flat_number AND price = approved_price AND rooms = approved_rooms"
+
+		builder = builder.SetMap(map[string]any{
+			"approved_flat_number": squirrel.Expr("CASE WHEN " + unedited + " THEN NULL ELSE approved_flat_number END"),
+			"approved_price":       squirrel.Expr("CASE WHEN " + unedited + " THEN NULL ELSE approved_price END"),
+			"approved_rooms":       squirrel.Expr("CASE WHEN " + unedited + " THEN NULL ELSE approved_rooms END"),
+		})
+	}
+
+	query, args, err := builder.
 		Where(squirrel.Eq{"id": flatId}).
-		Suffix("RETURNING *").
+		Suffix("RETURNING id, flat_number, price, rooms, status").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
 		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// Update flat and return it.
+	// Check claim and transition holding the lock, then update flat, record the change,
+	// bump its house modification time and return flat.
 	var flat domain.Flat
-	err = r.db.QueryRow(ctx, query, args...).Scan(&flat.ID, &flat.FlatNumber, &flat.Price, &flat.Rooms, &flat.Status)
+	err = pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var (
+			old     domain.Status
+			claimed bool
+		)
+		if err := tx.QueryRow(ctx, lock, lockArgs...).Scan(&old, &claimed); err != nil {
+			return err
+		}
+
+		// Moderator decides on created flat, sending it back to created is up to its creator.
+		if next := domain.Status(status); next == domain.StatusCreated || !old.CanTransitionTo(next) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, old, status)
+		}
+
+		if !claimed {
+			return ErrFlatNotClaimed
+		}
+
+		err := tx.QueryRow(ctx, query, args...).Scan(&flat.ID, &flat.FlatNumber, &flat.Price, &flat.Rooms, &flat.Status)
+		if err != nil {
+			return err
+		}
+
+		change := domain.FlatStatusChange{OldStatus: old, NewStatus: flat.Status, Actor: &moderatorId}
+		if decline != nil {
+			change.Reason = decline.Reason.Code
+		}
+
+		if err = r.recordStatusChange(ctx, tx, flatId, change); err != nil {
+			return err
+		}
+
+		if err = r.touchHouse(ctx, tx, flatId); err != nil {
+			return err
+		}
+
+		if flat.Status != domain.StatusApproved {
+			return nil
+		}
+
+		return r.enqueueApproved(ctx, tx, flat)
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
+		}
+
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == pgerrcode.NoDataFound {
-				return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
-			}
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrDeclineReasonNotFound)
 		}
 
 		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
@@ -157,72 +394,226 @@ func (r *FlatsRepo) Update(ctx context.Context, flatId int, status string) (doma
 	return flat, nil
 }
 
-func (r *FlatsRepo) SwitchModeration(ctx context.Context, flatId int) (bool, error) {
-	const op = "repository.Flats.SwitchModeration"
+// Edit changes flat attributes and sends it back to moderation, keeping its approved version.
+// Flat row is locked while the claim and transition to created are checked, so flat claimed by moderator
+// other than user isn't edited.
+// Status change is recorded in flat history on behalf of user edited the flat.
+func (r *FlatsRepo) Edit(ctx context.Context, flatId int, userId uuid.UUID, changes domain.FlatChanges) (domain.Flat, error) {
+	const op = "repository.Flats.Edit"
 
 	query, args, err := squirrel.
-		Select("status").
-		From(flatsTable).
+		Update(flatsTable).
+		Set("flat_number", squirrel.Expr("coalesce(?, flat_number)", changes.FlatNumber)).
+		Set("price", squirrel.Expr("coalesce(?, price)", changes.Price)).
+		Set("rooms", squirrel.Expr("coalesce(?, rooms)", changes.Rooms)).
+		Set("status", domain.StatusCreated).
 		Where(squirrel.Eq{"id": flatId}).
+		Suffix("RETURNING id, flat_number, price, rooms, status, created_by").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return true, fmt.Errorf("%s: %w", op, err)
+		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// Get flat status and check if it'tests on moderation
-	var status string
-	err = r.db.QueryRow(ctx, query, args...).Scan(&status)
+	var flat domain.Flat
+	err = pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		status, claimed, err := r.lockStatus(ctx, tx, flatId, userId)
+		if err != nil {
+			return err
+		}
+
+		// Moderator deciding on flat sees it as claimed, so it isn't changed under them.
+		if claimed {
+			return ErrFlatClaimed
+		}
+
+		if !status.CanTransitionTo(domain.StatusCreated) {
+			return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, status, domain.StatusCreated)
+		}
+
+		err = tx.QueryRow(ctx, query, args...).Scan(&flat.ID, &flat.FlatNumber, &flat.Price, &flat.Rooms, &flat.Status, &flat.CreatedBy)
+		if err != nil {
+			return err
+		}
+
+		err = r.recordStatusChange(ctx, tx, flatId, domain.FlatStatusChange{OldStatus: status, NewStatus: flat.Status, Actor: &userId})
+		if err != nil {
+			return err
+		}
+
+		return r.touchHouse(ctx, tx, flatId)
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return true, fmt.Errorf("%s: %s", op, ErrFlatNotFound)
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
 		}
 
-		return true, fmt.Errorf("%s: %w", op, err)
-	}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrFlatAlreadyExists)
+		}
 
-	if domain.Status(status) == domain.StatusOnModeration {
-		return true, fmt.Errorf("%s: %s", op, ErrFlatOnModeration)
+		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	query, args, err = squirrel.
-		Update(flatsTable).
-		Set("status", domain.StatusOnModeration).
-		Where(squirrel.Eq{"id": flatId}).
+	return flat, nil
+}
+
+// GetHistory returns status changes of flat, oldest first.
+func (r *FlatsRepo) GetHistory(ctx context.Context, flatId int) ([]domain.FlatStatusChange, error) {
+	const op = "repository.Flats.GetHistory"
+
+	query, args, err := squirrel.
+		Select("coalesce(old_status, '')", "new_status", "actor", "coalesce(reason, '')", "created_at").
+		From(statusHistoryTable).
+		Where(squirrel.Eq{"flat_id": flatId}).
+		OrderBy("id").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return true, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// Update flat status to 'moderating'.
-	_, err = r.db.Exec(ctx, query, args...)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	history := []domain.FlatStatusChange{}
+	var change domain.FlatStatusChange
+	_, err = pgx.ForEachRow(rows, []any{&change.OldStatus, &change.NewStatus, &change.Actor, &change.Reason, &change.CreatedAt}, func() error {
+		history = append(history, change)
+		change.Actor = nil
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-		return true, fmt.Errorf("%s: %w", op, err)
+	// Every flat has at least the status it was created with, so empty history means there is no flat.
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
 	}
 
-	return false, nil
+	return history, nil
 }
 
-func (r *FlatsRepo) SwitchModerationBackTo(ctx context.Context, flatId int, status string) error {
-	const op = "repository.Flats.SwitchModerationBackTo"
+// GetUserHistory returns status changes of flats created by user and changes made by user, oldest first.
+func (r *FlatsRepo) GetUserHistory(ctx context.Context, userId uuid.UUID) ([]domain.FlatStatusRecord, error) {
+	const op = "repository.Flats.GetUserHistory"
 
 	query, args, err := squirrel.
-		Update(flatsTable).
-		Set("status", status).
+		Select("flat_id", "coalesce(old_status, '')", "new_status", "actor", "coalesce(reason, '')", "created_at").
+		From(statusHistoryTable).
+		Where(squirrel.Or{
+			squirrel.Eq{"actor": userId},
+			squirrel.Expr("flat_id IN (SELECT id FROM "+flatsTable+" WHERE created_by = ?)", userId),
+		}).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	history := []domain.FlatStatusRecord{}
+	var rec domain.FlatStatusRecord
+	_, err = pgx.ForEachRow(rows, []any{&rec.FlatID, &rec.OldStatus, &rec.NewStatus, &rec.Actor, &rec.Reason, &rec.CreatedAt}, func() error {
+		history = append(history, rec)
+		rec.Actor = nil
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return history, nil
+}
+
+// lockStatus returns flat status and whether flat is claimed by moderator other than user,
+// and locks flat until tx ends, so that concurrent changes are recorded in order.
+func (r *FlatsRepo) lockStatus(ctx context.Context, tx pgx.Tx, flatId int, userId uuid.UUID) (domain.Status, bool, error) {
+	query, args, err := squirrel.
+		Select("status").
+		Column("coalesce(claimed_by <> ? AND claim_expires_at > now(), false)", userId).
+		From(flatsTable).
 		Where(squirrel.Eq{"id": flatId}).
+		Suffix("FOR UPDATE").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return "", false, err
 	}
 
-	// Simply change flat status back to 'status'.
-	_, err = r.db.Exec(ctx, query, args...)
+	var (
+		status  domain.Status
+		claimed bool
+	)
+	err = tx.QueryRow(ctx, query, args...).Scan(&status, &claimed)
+
+	return status, claimed, err
+}
+
+// recordStatusChange appends flat status change to its history within the transaction making the change.
+func (r *FlatsRepo) recordStatusChange(ctx context.Context, q querier, flatId int, change domain.FlatStatusChange) error {
+	query, args, err := squirrel.
+		Insert(statusHistoryTable).
+		Columns("flat_id", "old_status", "new_status", "actor", "reason").
+		Values(flatId, squirrel.Expr("nullif(?, '')", change.OldStatus), change.NewStatus, change.Actor,
+			squirrel.Expr("nullif(?, '')", change.Reason)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, query, args...)
+
+	return err
+}
+
+func (r *FlatsRepo) enqueueApproved(ctx context.Context, tx pgx.Tx, flat domain.Flat) error {
+	houseId, err := r.getHouseId(ctx, tx, flat.ID)
+	if err != nil {
+		return err
+	}
+
+	payload := domain.FlatApprovedPayload{
+		HouseID:    houseId,
+		FlatID:     flat.ID,
+		FlatNumber: flat.FlatNumber,
+		Rooms:      flat.Rooms,
+		Price:      flat.Price,
+	}
+
+	if err = enqueueForSubscribers(ctx, tx, domain.EventFlatApproved, houseId, flat, payload); err != nil {
+		return err
+	}
+
+	return enqueueForWebhooks(ctx, tx, domain.EventFlatApproved, payload)
+}
+
+// touchHouse bumps modification time of the house flat belongs to,
+// so that clients polling house notice flat status change.
+func (r *FlatsRepo) touchHouse(ctx context.Context, q querier, flatId int) error {
+	query, args, err := squirrel.
+		Update(housesTable).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Expr("id = (SELECT house_id FROM "+flatsTable+" WHERE id = ?)", flatId)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return err
 	}
 
-	return nil
+	_, err = q.Exec(ctx, query, args...)
+
+	return err
 }
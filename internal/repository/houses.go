@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
 )
 
 type HousesRepo struct {
@@ -25,86 +26,207 @@ func NewHousesRepo(db *pgxpool.Pool) *HousesRepo {
 func (r *HousesRepo) GetById(ctx context.Context, id int) ([]domain.Flat, error) {
 	const op = "repository.HousesRepo.GetById"
 
-	// Get flats to query
-	query, args, err := squirrel.
-		Select("flat_id").
-		From(houseFlatsTable).
-		Where(squirrel.Eq{"house_id": id}).
+	query, args, err := r.selectFlats(ctx, id).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	var flatsIds []int
-
+	var flats []domain.Flat
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("%s: %w", op, ErrHouseNotFound)
-		}
-
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var flatId int
-		if err = rows.Scan(&flatId); err != nil {
+		flat := domain.Flat{}
+		if err = rows.Scan(&flat.ID, &flat.FlatNumber, &flat.Price, &flat.Rooms, &flat.Status); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
 
-		flatsIds = append(flatsIds, flatId)
+		flats = append(flats, flat)
 	}
 
-	// Get flats by ids
-	query, args, err = squirrel.
-		Select("id", "flat_number", "price", "rooms", "status").
-		From(flatsTable).
-		Where(squirrel.Eq{"id": flatsIds, "status": r.statusesFromUserType(ctx)}).
+	return flats, nil
+}
+
+// GetUpdatedAt returns house modification time, which is bumped on every change of its flats.
+func (r *HousesRepo) GetUpdatedAt(ctx context.Context, id int) (time.Time, error) {
+	const op = "repository.HousesRepo.GetUpdatedAt"
+
+	query, args, err := squirrel.
+		Select("updated_at").
+		From(housesTable).
+		Where(squirrel.Eq{"id": id}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	var flats []domain.Flat
-	rows, err = r.db.Query(ctx, query, args...)
+	var updatedAt time.Time
+	err = r.db.QueryRow(ctx, query, args...).Scan(&updatedAt)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, fmt.Errorf("%s: %w", op, ErrHouseNotFound)
+		}
+
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updatedAt, nil
+}
+
+// ForEachFlat calls fn for every house flat visible to user, reading them from database one at a time.
+func (r *HousesRepo) ForEachFlat(ctx context.Context, id int, fn func(flat domain.Flat) error) error {
+	const op = "repository.HousesRepo.ForEachFlat"
+
+	if err := r.checkExists(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query, args, err := r.selectFlats(ctx, id).
+		OrderBy("flat_number").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
-	for rows.Next() {
-		flat := domain.Flat{}
-		if err = rows.Scan(&flat.ID, &flat.FlatNumber, &flat.Price, &flat.Rooms, &flat.Status); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
+	var flat domain.Flat
+	_, err = pgx.ForEachRow(rows, []any{&flat.ID, &flat.FlatNumber, &flat.Price, &flat.Rooms, &flat.Status}, func() error {
+		return fn(flat)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
-		flats = append(flats, flat)
+	return nil
+}
+
+// ForEach calls fn for every house, reading them from database one at a time.
+func (r *HousesRepo) ForEach(ctx context.Context, fn func(house domain.House) error) error {
+	const op = "repository.HousesRepo.ForEach"
+
+	query, args, err := squirrel.
+		Select("id", "address", "year", "coalesce(developer, '')", "created_at", "updated_at").
+		From(housesTable).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return flats, nil
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var house domain.House
+	_, err = pgx.ForEachRow(rows, []any{&house.ID, &house.Address, &house.Year, &house.Developer, &house.CreatedAt, &house.UpdatedAt}, func() error {
+		return fn(house)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
 }
 
-func (r *HousesRepo) statusesFromUserType(ctx context.Context) []domain.Status {
-	userType := ctx.Value("user-type").(string)
+// checkExists returns ErrHouseNotFound if there is no house with id.
+func (r *HousesRepo) checkExists(ctx context.Context, id int) error {
+	query, args, err := squirrel.
+		Select("1").
+		From(housesTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
 
-	if userType == string(domain.UserTypeModerator) {
-		return []domain.Status{
-			domain.StatusCreated,
-			domain.StatusApproved,
-			domain.StatusDeclined,
-			domain.StatusOnModeration,
+	var exists int
+	err = r.db.QueryRow(ctx, query, args...).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrHouseNotFound
 		}
+
+		return err
+	}
+
+	return nil
+}
+
+// selectFlats selects house flats visible to user. Moderators see flats of any status as they are,
+// while clients see approved versions of flats, including ones edited since approval,
+// and their own flats as they are.
+func (r *HousesRepo) selectFlats(ctx context.Context, id int) squirrel.SelectBuilder {
+	if userTypeFromCtx(ctx) == string(domain.UserTypeModerator) {
+		return squirrel.
+			Select("id", "flat_number", "price", "rooms", "status").
+			From(flatsTable).
+			Where(squirrel.Eq{"house_id": id})
 	}
 
-	return []domain.Status{domain.StatusApproved}
+	// Nil user owns no flats, as comparison with NULL is never true.
+	userId := userIdFromCtx(ctx)
+
+	return squirrel.
+		Select("id").
+		Column(squirrel.Expr("CASE WHEN created_by = ? THEN flat_number ELSE approved_flat_number END", userId)).
+		Column(squirrel.Expr("CASE WHEN created_by = ? THEN price ELSE approved_price END", userId)).
+		Column(squirrel.Expr("CASE WHEN created_by = ? THEN rooms ELSE approved_rooms END", userId)).
+		Column(squirrel.Expr("CASE WHEN created_by = ? THEN status ELSE ? END", userId, domain.StatusApproved)).
+		From(flatsTable).
+		Where(squirrel.Eq{"house_id": id}).
+		Where(squirrel.Or{
+			squirrel.NotEq{"approved_price": nil},
+			squirrel.Expr("created_by = ?", userId),
+		})
 }
 
 func (r *HousesRepo) Create(ctx context.Context, house domain.House) (domain.House, error) {
 	const op = "repository.HousesRepo.Create"
 
+	houseId, err := r.insert(ctx, r.db, house)
+	if err != nil {
+		return domain.House{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	house.ID = houseId
+
+	return house, nil
+}
+
+// CreateBatch creates houses in a single transaction, reporting error for each of them separately.
+// See execBatch for atomic and dryRun meaning.
+func (r *HousesRepo) CreateBatch(ctx context.Context, houses []domain.House, atomic, dryRun bool) ([]error, error) {
+	const op = "repository.HousesRepo.CreateBatch"
+
+	errs, err := execBatch(ctx, r.db, len(houses), atomic, dryRun, func(ctx context.Context, tx pgx.Tx, i int) error {
+		_, err := r.insert(ctx, tx, houses[i])
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return wrapBatchErr(op, errs), nil
+}
+
+// insert adds house and returns its id.
+func (r *HousesRepo) insert(ctx context.Context, q querier, house domain.House) (int, error) {
 	query, args, err := squirrel.
 		Insert(housesTable).
 		Columns("address", "year", "developer", "created_at", "updated_at").
@@ -113,27 +235,25 @@ func (r *HousesRepo) Create(ctx context.Context, house domain.House) (domain.Hou
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
-		return domain.House{}, fmt.Errorf("%s: %w", op, err)
+		return 0, err
 	}
 
 	var houseId int
-	err = r.db.QueryRow(ctx, query, args...).Scan(&houseId)
+	err = q.QueryRow(ctx, query, args...).Scan(&houseId)
 	if err != nil {
-		return domain.House{}, fmt.Errorf("%s: %w", op, err)
+		return 0, err
 	}
 
-	house.ID = houseId
-
-	return house, nil
+	return houseId, nil
 }
 
-func (r *HousesRepo) SubscribeUser(ctx context.Context, houseId int, email string) error {
+func (r *HousesRepo) SubscribeUser(ctx context.Context, sub domain.Subscription) error {
 	const op = "repository.HousesRepo.SubscribeUser"
 
 	query, args, err := squirrel.
 		Insert(houseSubsTable).
-		Columns("house_id", "user_email").
-		Values(houseId, email).
+		Columns("house_id", "user_email", "min_rooms", "max_rooms", "min_price", "max_price", "delivery").
+		Values(sub.HouseID, sub.Email, sub.MinRooms, sub.MaxRooms, sub.MinPrice, sub.MaxPrice, sub.Delivery).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
@@ -143,29 +263,157 @@ func (r *HousesRepo) SubscribeUser(ctx context.Context, houseId int, email strin
 	_, err = r.db.Exec(ctx, query, args...)
 	if err != nil {
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-
-			return fmt.Errorf("%s: %w", op, ErrUserAlreadySubscribed)
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == pgerrcode.UniqueViolation {
+				return fmt.Errorf("%s: %w", op, ErrUserAlreadySubscribed)
+			}
+
+			if pgErr.Code == pgerrcode.ForeignKeyViolation {
+				return fmt.Errorf("%s: %w", op, ErrUserOrHouseNotFound)
+			}
 		}
 
-		if pgErr.Code == pgerrcode.ForeignKeyViolation {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
 
-			return fmt.Errorf("%s: %w", op, ErrUserOrHouseNotFound)
+func (r *HousesRepo) UnsubscribeUser(ctx context.Context, houseId int, email string) error {
+	const op = "repository.HousesRepo.UnsubscribeUser"
+
+	query, args, err := squirrel.
+		Delete(houseSubsTable).
+		Where(squirrel.Eq{"house_id": houseId, "user_email": email}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrSubscriptionNotFound)
+	}
+
+	return nil
+}
+
+// UnsubscribeUserFromHouses deletes subscriptions of user to houses along with flats of them pending
+// in user digests, so that nothing about the houses is sent anymore. Returns number of deleted subscriptions.
+func (r *HousesRepo) UnsubscribeUserFromHouses(ctx context.Context, email string, houseIds []int) (int64, error) {
+	const op = "repository.HousesRepo.UnsubscribeUserFromHouses"
+
+	subs, subsArgs, err := squirrel.
+		Delete(houseSubsTable).
+		Where(squirrel.Eq{"user_email": email, "house_id": houseIds}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	events, eventsArgs, err := squirrel.
+		Delete(digestEventsTable).
+		Where(squirrel.Eq{"user_email": email, "(payload->>'house_id')::integer": houseIds}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var deleted int64
+	err = pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, subs, subsArgs...)
+		if err != nil {
+			return err
 		}
+		deleted = tag.RowsAffected()
+
+		_, err = tx.Exec(ctx, events, eventsArgs...)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// UpdateSubscriptionDelivery sets how user with email is notified about new flats of the house.
+// Flats already pending for digest are sent with it.
+func (r *HousesRepo) UpdateSubscriptionDelivery(ctx context.Context, houseId int, email string, delivery domain.Delivery) error {
+	const op = "repository.HousesRepo.UpdateSubscriptionDelivery"
 
+	query, args, err := squirrel.
+		Update(houseSubsTable).
+		Set("delivery", delivery).
+		Where(squirrel.Eq{"house_id": houseId, "user_email": email}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrSubscriptionNotFound)
+	}
+
 	return nil
 }
 
-func (r *HousesRepo) GetHouseSubscribers(ctx context.Context, houseId int) ([]string, error) {
+// GetUserSubscriptions returns all subscriptions of user with email, oldest first.
+func (r *HousesRepo) GetUserSubscriptions(ctx context.Context, email string) ([]domain.Subscription, error) {
+	const op = "repository.HousesRepo.GetUserSubscriptions"
+
+	query, args, err := squirrel.
+		Select("house_id", "user_email", "min_rooms", "max_rooms", "min_price", "max_price", "delivery", "created_at").
+		From(houseSubsTable).
+		Where(squirrel.Eq{"user_email": email}).
+		OrderBy("created_at", "house_id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	subs := []domain.Subscription{}
+	var sub domain.Subscription
+	_, err = pgx.ForEachRow(rows, []any{&sub.HouseID, &sub.Email, &sub.MinRooms, &sub.MaxRooms, &sub.MinPrice, &sub.MaxPrice, &sub.Delivery, &sub.CreatedAt}, func() error {
+		subs = append(subs, sub)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subs, nil
+}
+
+// GetHouseSubscribers returns emails of house subscribers whose criteria flat satisfies.
+func (r *HousesRepo) GetHouseSubscribers(ctx context.Context, houseId int, flat domain.Flat) ([]string, error) {
 	const op = "repository.HousesRepo.GetHouseSubscribers"
 
 	query, args, err := squirrel.
 		Select("user_email").
 		From(houseSubsTable).
-		Where(squirrel.Eq{"house_id": houseId}).
+		Where(subscribersFilter(houseId, flat)).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
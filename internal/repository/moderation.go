@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+// ModerationRepo keeps queue of flats awaiting moderation and moderators' claims on them.
+// Claim is held while claim_expires_at is in the future, expired claims are ignored until released.
+type ModerationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewModerationRepo(db *pgxpool.Pool) *ModerationRepo {
+	return &ModerationRepo{
+		db: db,
+	}
+}
+
+// claimAvailable matches flats that aren't claimed by other moderator.
+func claimAvailable(moderatorId uuid.UUID) squirrel.Sqlizer {
+	return squirrel.Or{
+		squirrel.Eq{"claimed_by": nil},
+		squirrel.Expr("claim_expires_at <= now()"),
+		squirrel.Eq{"claimed_by": moderatorId},
+	}
+}
+
+// GetQueue returns flats awaiting moderation available to moderator, oldest first.
+// Flats claimed by moderator are included, flats claimed by others aren't. Zero houseId means any house.
+func (r *ModerationRepo) GetQueue(ctx context.Context, moderatorId uuid.UUID, houseId, limit, offset int) ([]domain.QueuedFlat, error) {
+	const op = "repository.Moderation.GetQueue"
+
+	builder := squirrel.
+		Select("id", "house_id", "flat_number", "price", "rooms", "status", "created_at").
+		Column("CASE WHEN claim_expires_at > now() THEN claimed_by END").
+		Column("CASE WHEN claim_expires_at > now() THEN claim_expires_at END").
+		From(flatsTable).
+		Where(squirrel.Eq{"status": domain.StatusCreated}).
+		Where(claimAvailable(moderatorId))
+
+	if houseId != 0 {
+		builder = builder.Where(squirrel.Eq{"house_id": houseId})
+	}
+
+	query, args, err := builder.
+		OrderBy("created_at", "id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	flats := []domain.QueuedFlat{}
+	var f domain.QueuedFlat
+	_, err = pgx.ForEachRow(rows, []any{&f.ID, &f.HouseID, &f.FlatNumber, &f.Price, &f.Rooms, &f.Status, &f.CreatedAt, &f.ClaimedBy, &f.ClaimExpiresAt}, func() error {
+		flats = append(flats, f)
+		f.ClaimedBy, f.ClaimExpiresAt = nil, nil
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return flats, nil
+}
+
+// Claim assigns flat awaiting moderation to moderator for lease. Moderator holding the claim extends it.
+func (r *ModerationRepo) Claim(ctx context.Context, flatId int, moderatorId uuid.UUID, lease time.Duration) (domain.FlatClaim, error) {
+	const op = "repository.Moderation.Claim"
+
+	query, args, err := squirrel.
+		Update(flatsTable).
+		Set("claimed_by", moderatorId).
+		Set("claim_expires_at", squirrel.Expr("now() + ? * interval '1 second'", lease.Seconds())).
+		Where(squirrel.Eq{"id": flatId, "status": domain.StatusCreated}).
+		Where(claimAvailable(moderatorId)).
+		Suffix("RETURNING id, claimed_by, claim_expires_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var claim domain.FlatClaim
+	err = r.db.QueryRow(ctx, query, args...).Scan(&claim.FlatID, &claim.ModeratorID, &claim.ExpiresAt)
+	if err == nil {
+		return claim, nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Find out why flat can't be claimed.
+	query, args, err = squirrel.
+		Select("status").
+		From(flatsTable).
+		Where(squirrel.Eq{"id": flatId}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var status domain.Status
+	err = r.db.QueryRow(ctx, query, args...).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
+		}
+
+		return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if status != domain.StatusCreated {
+		return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, ErrFlatNotQueued)
+	}
+
+	return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, ErrFlatClaimed)
+}
+
+// ReleaseExpiredClaims clears claims whose lease expired and returns their number.
+func (r *ModerationRepo) ReleaseExpiredClaims(ctx context.Context) (int64, error) {
+	const op = "repository.Moderation.ReleaseExpiredClaims"
+
+	query, args, err := squirrel.
+		Update(flatsTable).
+		Set("claimed_by", nil).
+		Set("claim_expires_at", nil).
+		Where("claim_expires_at <= now()").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetDeclineReasons returns catalogue of decline reasons ordered by code, archived ones are included on request.
+func (r *ModerationRepo) GetDeclineReasons(ctx context.Context, withArchived bool) ([]domain.DeclineReason, error) {
+	const op = "repository.Moderation.GetDeclineReasons"
+
+	builder := squirrel.
+		Select("code", "title", "checklist", "archived_at").
+		From(declineReasonsTable).
+		OrderBy("code")
+
+	if !withArchived {
+		builder = builder.Where(squirrel.Eq{"archived_at": nil})
+	}
+
+	query, args, err := builder.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reasons := []domain.DeclineReason{}
+	var reason domain.DeclineReason
+	_, err = pgx.ForEachRow(rows, []any{&reason.Code, &reason.Title, &reason.Checklist, &reason.ArchivedAt}, func() error {
+		reasons = append(reasons, reason)
+		reason.Checklist, reason.ArchivedAt = nil, nil
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reasons, nil
+}
+
+// GetDeclineReason returns decline reason by code, ErrDeclineReasonNotFound is returned for archived one.
+func (r *ModerationRepo) GetDeclineReason(ctx context.Context, code string) (domain.DeclineReason, error) {
+	const op = "repository.Moderation.GetDeclineReason"
+
+	query, args, err := squirrel.
+		Select("code", "title", "checklist").
+		From(declineReasonsTable).
+		Where(squirrel.Eq{"code": code, "archived_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.DeclineReason{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var reason domain.DeclineReason
+	err = r.db.QueryRow(ctx, query, args...).Scan(&reason.Code, &reason.Title, &reason.Checklist)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.DeclineReason{}, fmt.Errorf("%s: %w", op, ErrDeclineReasonNotFound)
+		}
+
+		return domain.DeclineReason{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reason, nil
+}
+
+// SetDeclineReason creates decline reason or replaces existing one with the same code, restoring it if archived.
+func (r *ModerationRepo) SetDeclineReason(ctx context.Context, reason domain.DeclineReason) error {
+	const op = "repository.Moderation.SetDeclineReason"
+
+	query, args, err := squirrel.
+		Insert(declineReasonsTable).
+		Columns("code", "title", "checklist").
+		Values(reason.Code, reason.Title, reason.Checklist).
+		Suffix("ON CONFLICT (code) DO UPDATE SET title = EXCLUDED.title, checklist = EXCLUDED.checklist, archived_at = NULL").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ArchiveDeclineReason withdraws decline reason from use, flats declined with it keep it.
+func (r *ModerationRepo) ArchiveDeclineReason(ctx context.Context, code string) error {
+	const op = "repository.Moderation.ArchiveDeclineReason"
+
+	query, args, err := squirrel.
+		Update(declineReasonsTable).
+		Set("archived_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"code": code, "archived_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrDeclineReasonNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// GetNotificationSettings returns notification settings of user, default ones if user has never set them.
+func (r *UsersRepo) GetNotificationSettings(ctx context.Context, id uuid.UUID) (domain.NotificationSettings, error) {
+	const op = "repository.UsersRepo.GetNotificationSettings"
+
+	query, args, err := squirrel.
+		Select("phone", "push_token", "timezone", "quiet_hours_start", "quiet_hours_end").
+		From(settingsTable).
+		Where(squirrel.Eq{"user_id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	settings := domain.NotificationSettings{
+		Timezone: domain.DefaultTimezone,
+		Channels: map[domain.EventType][]domain.Channel{},
+	}
+
+	var quietStart, quietEnd *string
+	err = r.db.QueryRow(ctx, query, args...).Scan(&settings.Phone, &settings.PushToken, &settings.Timezone, &quietStart, &quietEnd)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if quietStart != nil && quietEnd != nil {
+		settings.QuietHours = &domain.QuietHours{Start: *quietStart, End: *quietEnd}
+	}
+
+	query, args, err = squirrel.
+		Select("event_type", "channels").
+		From(channelsTable).
+		Where(squirrel.Eq{"user_id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var eventType domain.EventType
+	var channels []string
+	_, err = pgx.ForEachRow(rows, []any{&eventType, &channels}, func() error {
+		resp := make([]domain.Channel, 0, len(channels))
+		for _, channel := range channels {
+			resp = append(resp, domain.Channel(channel))
+		}
+		settings.Channels[eventType] = resp
+
+		return nil
+	})
+	if err != nil {
+		return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return settings, nil
+}
+
+// UpdateNotificationSettings replaces notification settings of user.
+func (r *UsersRepo) UpdateNotificationSettings(ctx context.Context, id uuid.UUID, settings domain.NotificationSettings) error {
+	const op = "repository.UsersRepo.UpdateNotificationSettings"
+
+	var quietStart, quietEnd *string
+	if settings.QuietHours != nil {
+		quietStart, quietEnd = &settings.QuietHours.Start, &settings.QuietHours.End
+	}
+
+	upsert, upsertArgs, err := squirrel.
+		Insert(settingsTable).
+		Columns("user_id", "phone", "push_token", "timezone", "quiet_hours_start", "quiet_hours_end", "updated_at").
+		Values(id, settings.Phone, settings.PushToken, settings.Timezone, quietStart, quietEnd, squirrel.Expr("now()")).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET phone = EXCLUDED.phone, push_token = EXCLUDED.push_token, " +
+			"timezone = EXCLUDED.timezone, quiet_hours_start = EXCLUDED.quiet_hours_start, " +
+			"quiet_hours_end = EXCLUDED.quiet_hours_end, updated_at = EXCLUDED.updated_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	del, delArgs, err := squirrel.
+		Delete(channelsTable).
+		Where(squirrel.Eq{"user_id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, upsert, upsertArgs...); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, del, delArgs...); err != nil {
+			return err
+		}
+
+		if len(settings.Channels) == 0 {
+			return nil
+		}
+
+		insert := squirrel.
+			Insert(channelsTable).
+			Columns("user_id", "event_type", "channels")
+		for eventType, channels := range settings.Channels {
+			text := make([]string, 0, len(channels))
+			for _, channel := range channels {
+				text = append(text, string(channel))
+			}
+
+			insert = insert.Values(id, eventType, text)
+		}
+
+		query, args, err := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, query, args...)
+
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationsRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationsRepo(db *pgxpool.Pool) *NotificationsRepo {
+	return &NotificationsRepo{
+		db: db,
+	}
+}
+
+// Create stores notification in user inbox. Notification of outbox message that is
+// already stored is skipped, so that message delivered again isn't shown twice.
+func (r *NotificationsRepo) Create(ctx context.Context, notification domain.Notification) error {
+	const op = "repository.Notifications.Create"
+
+	query, args, err := squirrel.
+		Insert(notificationsTable).
+		Columns("user_id", "outbox_id", "event_type", "title", "body", "payload").
+		Values(notification.UserID, notification.OutboxID, notification.EventType, notification.Title,
+			notification.Body, notification.Payload).
+		Suffix("ON CONFLICT (outbox_id) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetByUser returns user notifications, most recent first.
+func (r *NotificationsRepo) GetByUser(ctx context.Context, userId uuid.UUID, unreadOnly bool, limit, offset int) ([]domain.Notification, error) {
+	const op = "repository.Notifications.GetByUser"
+
+	builder := squirrel.
+		Select("id", "user_id", "outbox_id", "event_type", "title", "body", "payload", "read_at", "created_at").
+		From(notificationsTable).
+		Where(squirrel.Eq{"user_id": userId})
+	if unreadOnly {
+		builder = builder.Where(squirrel.Eq{"read_at": nil})
+	}
+
+	query, args, err := builder.
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	notifications := []domain.Notification{}
+	var n domain.Notification
+	_, err = pgx.ForEachRow(rows, []any{&n.ID, &n.UserID, &n.OutboxID, &n.EventType, &n.Title, &n.Body, &n.Payload, &n.ReadAt, &n.CreatedAt}, func() error {
+		notifications = append(notifications, n)
+		// Reset nullable and referenced fields, so that next row doesn't scan into values referenced by this one.
+		n.ReadAt, n.Payload = nil, nil
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks user notification read. Notification that is already read keeps its read time.
+func (r *NotificationsRepo) MarkRead(ctx context.Context, userId uuid.UUID, id int64) error {
+	const op = "repository.Notifications.MarkRead"
+
+	query, args, err := squirrel.
+		Update(notificationsTable).
+		Set("read_at", squirrel.Expr("COALESCE(read_at, now())")).
+		Where(squirrel.Eq{"id": id, "user_id": userId}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrNotificationNotFound)
+	}
+
+	return nil
+}
+
+// MarkAllRead marks all unread user notifications read and returns their number.
+func (r *NotificationsRepo) MarkAllRead(ctx context.Context, userId uuid.UUID) (int64, error) {
+	const op = "repository.Notifications.MarkAllRead"
+
+	query, args, err := squirrel.
+		Update(notificationsTable).
+		Set("read_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"user_id": userId, "read_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
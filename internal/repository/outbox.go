@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+type OutboxRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepo(db *pgxpool.Pool) *OutboxRepo {
+	return &OutboxRepo{
+		db: db,
+	}
+}
+
+// claimQuery leases up to $1 pending messages available by now for $2 seconds and returns them
+// with language of their recipients. Rows are locked only while the statement runs, lease keeps
+// them from other dispatchers after that, and if dispatcher stops they are picked up once lease expires.
+var claimQuery = fmt.Sprintf(`WITH claimed AS (
+    UPDATE %[1]s SET attempts = attempts + 1, available_at = now() + $2 * interval '1 second'
+    WHERE id IN (
+        SELECT id FROM %[1]s
+        WHERE processed_at IS NULL AND available_at <= now()
+        ORDER BY id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, event_type, channel, recipient, user_id, payload, attempts, created_at, available_at
+)
+SELECT c.id, c.event_type, c.channel, c.recipient, c.user_id, c.payload, c.attempts, c.created_at, c.available_at,
+       COALESCE(u.language, '')
+FROM claimed c LEFT JOIN %[2]s u ON u.user_id = c.user_id
+ORDER BY c.id`, outboxTable, usersTable)
+
+// Claim leases up to limit pending messages available by now, so that concurrent dispatchers skip them,
+// and returns them with attempts counting this one. Leased messages must be marked processed or failed,
+// or moved to dead letters, before lease expires, otherwise they are claimed again.
+func (r *OutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	const op = "repository.Outbox.Claim"
+
+	rows, err := r.db.Query(ctx, claimQuery, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	msgs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.OutboxMessage, error) {
+		var msg domain.OutboxMessage
+		err := row.Scan(&msg.ID, &msg.EventType, &msg.Channel, &msg.Recipient, &msg.UserID, &msg.Payload, &msg.Attempts, &msg.CreatedAt,
+			&msg.AvailableAt, &msg.Language)
+
+		return msg, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return msgs, nil
+}
+
+// MarkProcessed marks delivered message processed.
+func (r *OutboxRepo) MarkProcessed(ctx context.Context, id int64) error {
+	const op = "repository.Outbox.MarkProcessed"
+
+	query, args, err := squirrel.
+		Update(outboxTable).
+		Set("processed_at", time.Now()).
+		Set("last_error", nil).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkFailed records delivery error of message and makes it available for the next attempt at retryAt.
+func (r *OutboxRepo) MarkFailed(ctx context.Context, id int64, sendErr error, retryAt time.Time) error {
+	const op = "repository.Outbox.MarkFailed"
+
+	query, args, err := squirrel.
+		Update(outboxTable).
+		Set("last_error", sendErr.Error()).
+		Set("available_at", retryAt).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MoveToDeadLetters replaces message that can't be delivered with dead letter keeping its last error.
+func (r *OutboxRepo) MoveToDeadLetters(ctx context.Context, msg domain.OutboxMessage, sendErr error) error {
+	const op = "repository.Outbox.MoveToDeadLetters"
+
+	insert, insertArgs, err := squirrel.
+		Insert(deadLettersTable).
+		Columns("outbox_id", "event_type", "channel", "recipient", "user_id", "payload", "attempts", "last_error", "created_at").
+		Values(msg.ID, msg.EventType, msg.Channel, msg.Recipient, msg.UserID, msg.Payload, msg.Attempts, sendErr.Error(), msg.CreatedAt).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	del, delArgs, err := squirrel.
+		Delete(outboxTable).
+		Where(squirrel.Eq{"id": msg.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, insert, insertArgs...); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, del, delArgs...)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetDeadLetters returns dead letters, most recently failed first.
+func (r *OutboxRepo) GetDeadLetters(ctx context.Context, limit, offset int) ([]domain.DeadLetter, error) {
+	const op = "repository.Outbox.GetDeadLetters"
+
+	query, args, err := squirrel.
+		Select("id", "outbox_id", "event_type", "channel", "recipient", "user_id", "payload", "attempts", "last_error", "created_at", "failed_at").
+		From(deadLettersTable).
+		OrderBy("failed_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	letters := []domain.DeadLetter{}
+	var l domain.DeadLetter
+	_, err = pgx.ForEachRow(rows, []any{&l.ID, &l.OutboxID, &l.EventType, &l.Channel, &l.Recipient, &l.UserID, &l.Payload, &l.Attempts, &l.LastError, &l.CreatedAt, &l.FailedAt}, func() error {
+		l.Payload = append([]byte(nil), l.Payload...)
+		letters = append(letters, l)
+		l.UserID = nil
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return letters, nil
+}
+
+// RedriveDeadLetter moves dead letter back to outbox as a new pending message and returns it.
+func (r *OutboxRepo) RedriveDeadLetter(ctx context.Context, id int64) (domain.OutboxMessage, error) {
+	const op = "repository.Outbox.RedriveDeadLetter"
+
+	query, args, err := squirrel.
+		Delete(deadLettersTable).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING event_type, channel, recipient, user_id, payload").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.OutboxMessage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var msg domain.OutboxMessage
+	err = pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, args...).Scan(&msg.EventType, &msg.Channel, &msg.Recipient, &msg.UserID, &msg.Payload)
+		if err != nil {
+			return err
+		}
+
+		query, args, err := squirrel.
+			Insert(outboxTable).
+			Columns("event_type", "channel", "recipient", "user_id", "payload").
+			Values(msg.EventType, msg.Channel, msg.Recipient, msg.UserID, msg.Payload).
+			Suffix("RETURNING id, created_at, available_at").
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, query, args...).Scan(&msg.ID, &msg.CreatedAt, &msg.AvailableAt)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.OutboxMessage{}, fmt.Errorf("%s: %w", op, ErrDeadLetterNotFound)
+		}
+
+		return domain.OutboxMessage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return msg, nil
+}
+
+// Enqueue adds messages to outbox.
+func (r *OutboxRepo) Enqueue(ctx context.Context, msgs []domain.OutboxMessage) error {
+	const op = "repository.Outbox.Enqueue"
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	insert := squirrel.
+		Insert(outboxTable).
+		Columns("event_type", "channel", "recipient", "user_id", "payload", "available_at")
+	for _, msg := range msgs {
+		availableAt := msg.AvailableAt
+		if availableAt.IsZero() {
+			availableAt = time.Now()
+		}
+
+		insert = insert.Values(msg.EventType, msg.Channel, msg.Recipient, msg.UserID, msg.Payload, availableAt)
+	}
+
+	query, args, err := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// flushDigestsQuery replaces due digest events with a digest message for each subscriber and delivery.
+// Events are deleted and digests inserted in a single statement, so concurrent flushes don't send them twice.
+var flushDigestsQuery = fmt.Sprintf(`WITH due AS (
+    DELETE FROM %s WHERE due_at <= now()
+    RETURNING user_email, delivery, flat_id, payload
+)
+INSERT INTO %s (event_type, channel, recipient, user_id, payload)
+SELECT $1, $2, due.user_email, u.user_id,
+       jsonb_build_object('delivery', due.delivery, 'flats', jsonb_agg(due.payload ORDER BY due.flat_id))
+FROM due JOIN %s u ON u.email = due.user_email
+GROUP BY due.user_email, u.user_id, due.delivery`, digestEventsTable, outboxTable, usersTable)
+
+// FlushDigests adds digests of due events to outbox and returns their number.
+func (r *OutboxRepo) FlushDigests(ctx context.Context) (int64, error) {
+	const op = "repository.Outbox.FlushDigests"
+
+	tag, err := r.db.Exec(ctx, flushDigestsQuery, domain.EventFlatsDigest, domain.ChannelUser)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// enqueueForSubscribers adds message with payload to outbox for every house subscriber whose filters flat matches.
+// Messages are routed to channels chosen by subscribers on dispatch. Subscribers receiving digests get flat
+// added to their next digest instead, flat that is already there is listed once with the latest payload.
+func enqueueForSubscribers(ctx context.Context, q querier, eventType domain.EventType, houseId int, flat domain.Flat, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	subscribers := squirrel.
+		Select().
+		Column("?::text", eventType).
+		Column("?::text", domain.ChannelUser).
+		Column("s.user_email").
+		Column("u.user_id").
+		Column("?::jsonb", data).
+		From(houseSubsTable + " s").
+		LeftJoin(usersTable + " u ON u.email = s.user_email").
+		Where(subscribersFilter(houseId, flat)).
+		Where(squirrel.Eq{"s.delivery": domain.DeliveryImmediate})
+
+	query, args, err := squirrel.
+		Insert(outboxTable).
+		Columns("event_type", "channel", "recipient", "user_id", "payload").
+		Select(subscribers).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = q.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	// Daily digests are due at the next midnight and weekly ones at the next Monday midnight.
+	digestSubscribers := squirrel.
+		Select("user_email", "delivery").
+		Column("?::integer", flat.ID).
+		Column("?::jsonb", data).
+		Column(squirrel.Expr("CASE delivery WHEN ? THEN date_trunc('day', now()) + interval '1 day' "+
+			"ELSE date_trunc('week', now()) + interval '1 week' END", domain.DeliveryDaily)).
+		From(houseSubsTable).
+		Where(subscribersFilter(houseId, flat)).
+		Where(squirrel.NotEq{"delivery": domain.DeliveryImmediate})
+
+	query, args, err = squirrel.
+		Insert(digestEventsTable).
+		Columns("user_email", "delivery", "flat_id", "payload", "due_at").
+		Select(digestSubscribers).
+		Suffix("ON CONFLICT (user_email, delivery, flat_id) DO UPDATE SET payload = EXCLUDED.payload, updated_at = now()").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, query, args...)
+
+	return err
+}
+
+// subscribersFilter matches subscriptions to the house whose rooms and price ranges include flat.
+func subscribersFilter(houseId int, flat domain.Flat) squirrel.Sqlizer {
+	return squirrel.And{
+		squirrel.Eq{"house_id": houseId},
+		squirrel.Or{squirrel.Eq{"min_rooms": nil}, squirrel.LtOrEq{"min_rooms": flat.Rooms}},
+		squirrel.Or{squirrel.Eq{"max_rooms": nil}, squirrel.GtOrEq{"max_rooms": flat.Rooms}},
+		squirrel.Or{squirrel.Eq{"min_price": nil}, squirrel.LtOrEq{"min_price": flat.Price}},
+		squirrel.Or{squirrel.Eq{"max_price": nil}, squirrel.GtOrEq{"max_price": flat.Price}},
+	}
+}
+
+// enqueueForWebhooks adds message with payload to outbox for every active webhook subscribed to event type.
+func enqueueForWebhooks(ctx context.Context, q querier, eventType domain.EventType, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoints := squirrel.
+		Select().
+		Column("?::text", eventType).
+		Column("?::text", domain.ChannelWebhook).
+		Column("id::text").
+		Column("?::jsonb", data).
+		From(webhooksTable).
+		Where(squirrel.Eq{"active": true}).
+		Where(squirrel.Expr("?::text = ANY(event_types)", eventType))
+
+	query, args, err := squirrel.
+		Insert(outboxTable).
+		Columns("event_type", "channel", "recipient", "payload").
+		Select(endpoints).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, query, args...)
+
+	return err
+}
@@ -2,22 +2,64 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
 )
 
 var (
-	usersTable      = "users"
-	housesTable     = "houses"
-	flatsTable      = "flats"
-	houseFlatsTable = "house_flats"
-	houseSubsTable  = "house_subscriptions"
+	usersTable          = "users"
+	settingsTable       = "notification_settings"
+	channelsTable       = "notification_channels"
+	housesTable         = "houses"
+	flatsTable          = "flats"
+	houseSubsTable      = "house_subscriptions"
+	outboxTable         = "outbox"
+	deadLettersTable    = "dead_letters"
+	webhooksTable       = "webhooks"
+	deliveriesTable     = "webhook_deliveries"
+	notificationsTable  = "notifications"
+	digestEventsTable   = "digest_events"
+	declineReasonsTable = "decline_reasons"
+	statusHistoryTable  = "flat_status_history"
 )
 
+// userTypeFromCtx returns type of user making request, set by auth middleware.
+func userTypeFromCtx(ctx context.Context) string {
+	userType, _ := ctx.Value("user-type").(string)
+
+	return userType
+}
+
+// userIdFromCtx returns id of user making request, set by auth middleware, or nil if it's unknown.
+func userIdFromCtx(ctx context.Context) *uuid.UUID {
+	userId, err := uuid.Parse(fmt.Sprint(ctx.Value("user-id")))
+	if err != nil {
+		return nil
+	}
+
+	return &userId
+}
+
+// querier is implemented by both pool and transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type Repository struct {
-	Houses Houses
-	Flats  Flats
-	Users  Users
+	Houses        Houses
+	Flats         Flats
+	Users         Users
+	Outbox        Outbox
+	Webhooks      Webhooks
+	Notifications Notifications
+	Moderation    Moderation
 }
 
 type Deps struct {
@@ -25,29 +67,92 @@ type Deps struct {
 
 func New(db *pgxpool.Pool) *Repository {
 	return &Repository{
-		Houses: NewHousesRepo(db),
-		Flats:  NewFlatsRepo(db),
-		Users:  NewUsersRepo(db),
+		Houses:        NewHousesRepo(db),
+		Flats:         NewFlatsRepo(db),
+		Users:         NewUsersRepo(db),
+		Outbox:        NewOutboxRepo(db),
+		Webhooks:      NewWebhooksRepo(db),
+		Notifications: NewNotificationsRepo(db),
+		Moderation:    NewModerationRepo(db),
 	}
 }
 
 type Houses interface {
 	GetById(ctx context.Context, id int) ([]domain.Flat, error)
+	GetUpdatedAt(ctx context.Context, id int) (time.Time, error)
+	ForEachFlat(ctx context.Context, id int, fn func(flat domain.Flat) error) error
+	ForEach(ctx context.Context, fn func(house domain.House) error) error
 	Create(ctx context.Context, house domain.House) (domain.House, error)
+	CreateBatch(ctx context.Context, houses []domain.House, atomic, dryRun bool) ([]error, error)
 
-	SubscribeUser(ctx context.Context, houseId int, email string) error
-	GetHouseSubscribers(ctx context.Context, houseId int) ([]string, error)
+	SubscribeUser(ctx context.Context, sub domain.Subscription) error
+	UnsubscribeUser(ctx context.Context, houseId int, email string) error
+	UnsubscribeUserFromHouses(ctx context.Context, email string, houseIds []int) (int64, error)
+	UpdateSubscriptionDelivery(ctx context.Context, houseId int, email string, delivery domain.Delivery) error
+	GetUserSubscriptions(ctx context.Context, email string) ([]domain.Subscription, error)
+	GetHouseSubscribers(ctx context.Context, houseId int, flat domain.Flat) ([]string, error)
 }
 
 type Flats interface {
 	Create(ctx context.Context, houseId int, flat domain.Flat) (domain.Flat, error)
+	CreateBatch(ctx context.Context, flats []domain.ImportFlat, atomic, dryRun bool) ([]error, error)
+	GetById(ctx context.Context, flatId int) (domain.FlatDetails, error)
+	GetByCreator(ctx context.Context, userId uuid.UUID) ([]domain.FlatDetails, error)
+	GetHouseId(ctx context.Context, flatId int) (int, error)
+	GetHistory(ctx context.Context, flatId int) ([]domain.FlatStatusChange, error)
+	GetUserHistory(ctx context.Context, userId uuid.UUID) ([]domain.FlatStatusRecord, error)
+
+	Update(ctx context.Context, flatId int, moderatorId uuid.UUID, status string, decline *domain.FlatDecline) (domain.Flat, error)
+	Edit(ctx context.Context, flatId int, userId uuid.UUID, changes domain.FlatChanges) (domain.Flat, error)
+}
+
+type Moderation interface {
+	GetQueue(ctx context.Context, moderatorId uuid.UUID, houseId, limit, offset int) ([]domain.QueuedFlat, error)
+	Claim(ctx context.Context, flatId int, moderatorId uuid.UUID, lease time.Duration) (domain.FlatClaim, error)
+	ReleaseExpiredClaims(ctx context.Context) (int64, error)
 
-	Update(ctx context.Context, flatId int, status string) (domain.Flat, error)
-	SwitchModeration(ctx context.Context, flatId int) (bool, error)
-	SwitchModerationBackTo(ctx context.Context, flatId int, status string) error
+	GetDeclineReasons(ctx context.Context, withArchived bool) ([]domain.DeclineReason, error)
+	GetDeclineReason(ctx context.Context, code string) (domain.DeclineReason, error)
+	SetDeclineReason(ctx context.Context, reason domain.DeclineReason) error
+	ArchiveDeclineReason(ctx context.Context, code string) error
 }
 
 type Users interface {
 	Create(ctx context.Context, user domain.User) error
 	GetByCredentials(ctx context.Context, email, password string) (domain.User, error)
+	GetById(ctx context.Context, id uuid.UUID) (domain.User, error)
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
+	UpdateLanguage(ctx context.Context, id uuid.UUID, language domain.Language) error
+
+	GetNotificationSettings(ctx context.Context, id uuid.UUID) (domain.NotificationSettings, error)
+	UpdateNotificationSettings(ctx context.Context, id uuid.UUID, settings domain.NotificationSettings) error
+}
+
+type Outbox interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	MarkProcessed(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, sendErr error, retryAt time.Time) error
+	MoveToDeadLetters(ctx context.Context, msg domain.OutboxMessage, sendErr error) error
+	Enqueue(ctx context.Context, msgs []domain.OutboxMessage) error
+	FlushDigests(ctx context.Context) (int64, error)
+	GetDeadLetters(ctx context.Context, limit, offset int) ([]domain.DeadLetter, error)
+	RedriveDeadLetter(ctx context.Context, id int64) (domain.OutboxMessage, error)
+}
+
+type Webhooks interface {
+	Create(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	GetAll(ctx context.Context) ([]domain.Webhook, error)
+	GetById(ctx context.Context, id int64) (domain.Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	Enable(ctx context.Context, id int64) (domain.Webhook, error)
+
+	RecordDelivery(ctx context.Context, delivery domain.WebhookDelivery, disableAfter int) (bool, error)
+	GetDeliveries(ctx context.Context, webhookId int64, limit, offset int) ([]domain.WebhookDelivery, error)
+}
+
+type Notifications interface {
+	Create(ctx context.Context, notification domain.Notification) error
+	GetByUser(ctx context.Context, userId uuid.UUID, unreadOnly bool, limit, offset int) ([]domain.Notification, error)
+	MarkRead(ctx context.Context, userId uuid.UUID, id int64) error
+	MarkAllRead(ctx context.Context, userId uuid.UUID) (int64, error)
 }
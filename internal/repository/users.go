@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -27,8 +28,8 @@ func (r *UsersRepo) Create(ctx context.Context, user domain.User) error {
 
 	query, args, err := squirrel.
 		Insert(usersTable).
-		Columns("user_id", "email", "password_hash", "user_type").
-		Values(user.ID, user.Email, user.Password, user.UserType).
+		Columns("user_id", "email", "password_hash", "user_type", "language").
+		Values(user.ID, user.Email, user.Password, user.UserType, user.Language).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
@@ -53,7 +54,7 @@ func (r *UsersRepo) GetByCredentials(ctx context.Context, email, password string
 	const op = "repository.UsersRepo.GetByCredentials"
 
 	query, args, err := squirrel.
-		Select("user_id", "email", "password_hash", "user_type").
+		Select("user_id", "email", "password_hash", "user_type", "language").
 		From(usersTable).
 		Where(squirrel.And{
 			squirrel.Eq{"email": email},
@@ -66,7 +67,7 @@ func (r *UsersRepo) GetByCredentials(ctx context.Context, email, password string
 	}
 
 	var user domain.User
-	err = r.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Email, &user.Password, &user.UserType)
+	err = r.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Email, &user.Password, &user.UserType, &user.Language)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			
@@ -78,3 +79,80 @@ func (r *UsersRepo) GetByCredentials(ctx context.Context, email, password string
 
 	return user, nil
 }
+
+func (r *UsersRepo) GetById(ctx context.Context, id uuid.UUID) (domain.User, error) {
+	const op = "repository.UsersRepo.GetById"
+
+	query, args, err := squirrel.
+		Select("user_id", "email", "password_hash", "user_type", "language").
+		From(usersTable).
+		Where(squirrel.Eq{"user_id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var user domain.User
+	err = r.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Email, &user.Password, &user.UserType, &user.Language)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return domain.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (r *UsersRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	const op = "repository.UsersRepo.GetByEmail"
+
+	query, args, err := squirrel.
+		Select("user_id", "email", "password_hash", "user_type", "language").
+		From(usersTable).
+		Where(squirrel.Eq{"email": email}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var user domain.User
+	err = r.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Email, &user.Password, &user.UserType, &user.Language)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return domain.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+func (r *UsersRepo) UpdateLanguage(ctx context.Context, id uuid.UUID, language domain.Language) error {
+	const op = "repository.UsersRepo.UpdateLanguage"
+
+	query, args, err := squirrel.
+		Update(usersTable).
+		Set("language", language).
+		Where(squirrel.Eq{"user_id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+	}
+
+	return nil
+}
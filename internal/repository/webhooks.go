@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strings"
+)
+
+var webhookColumns = []string{"id", "url", "event_types", "secret", "active", "consecutive_failures", "disabled_at", "created_at"}
+
+type WebhooksRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhooksRepo(db *pgxpool.Pool) *WebhooksRepo {
+	return &WebhooksRepo{
+		db: db,
+	}
+}
+
+func (r *WebhooksRepo) Create(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	const op = "repository.Webhooks.Create"
+
+	query, args, err := squirrel.
+		Insert(webhooksTable).
+		Columns("url", "event_types", "secret").
+		Values(webhook.URL, eventTypesToText(webhook.EventTypes), webhook.Secret).
+		Suffix("RETURNING " + joinColumns(webhookColumns)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := scanWebhook(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+func (r *WebhooksRepo) GetAll(ctx context.Context) ([]domain.Webhook, error) {
+	const op = "repository.Webhooks.GetAll"
+
+	query, args, err := squirrel.
+		Select(webhookColumns...).
+		From(webhooksTable).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Webhook, error) {
+		return scanWebhook(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if resp == nil {
+		resp = []domain.Webhook{}
+	}
+
+	return resp, nil
+}
+
+func (r *WebhooksRepo) GetById(ctx context.Context, id int64) (domain.Webhook, error) {
+	const op = "repository.Webhooks.GetById"
+
+	query, args, err := squirrel.
+		Select(webhookColumns...).
+		From(webhooksTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := scanWebhook(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Webhook{}, fmt.Errorf("%s: %w", op, ErrWebhookNotFound)
+		}
+
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Delete deletes webhook with its delivery logs. Pending messages to it are dropped on dispatch.
+func (r *WebhooksRepo) Delete(ctx context.Context, id int64) error {
+	const op = "repository.Webhooks.Delete"
+
+	query, args, err := squirrel.
+		Delete(webhooksTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+// Enable activates webhook and resets its failures counter.
+func (r *WebhooksRepo) Enable(ctx context.Context, id int64) (domain.Webhook, error) {
+	const op = "repository.Webhooks.Enable"
+
+	query, args, err := squirrel.
+		Update(webhooksTable).
+		Set("active", true).
+		Set("consecutive_failures", 0).
+		Set("disabled_at", nil).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("RETURNING " + joinColumns(webhookColumns)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := scanWebhook(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Webhook{}, fmt.Errorf("%s: %w", op, ErrWebhookNotFound)
+		}
+
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// RecordDelivery logs delivery and updates failures counter of its webhook.
+// Webhook is disabled once disableAfter deliveries in a row failed, returns whether it's disabled.
+func (r *WebhooksRepo) RecordDelivery(ctx context.Context, delivery domain.WebhookDelivery, disableAfter int) (bool, error) {
+	const op = "repository.Webhooks.RecordDelivery"
+
+	insert, insertArgs, err := squirrel.
+		Insert(deliveriesTable).
+		Columns("webhook_id", "outbox_id", "event_type", "success", "status_code", "attempts", "duration_ms", "error").
+		Values(delivery.WebhookID, delivery.OutboxID, delivery.EventType, delivery.Success, delivery.StatusCode,
+			delivery.Attempts, delivery.DurationMs, delivery.Error).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	update := squirrel.
+		Update(webhooksTable).
+		Where(squirrel.Eq{"id": delivery.WebhookID}).
+		Suffix("RETURNING NOT active")
+	if delivery.Success {
+		update = update.Set("consecutive_failures", 0)
+	} else {
+		update = update.
+			Set("consecutive_failures", squirrel.Expr("consecutive_failures + 1")).
+			Set("active", squirrel.Expr("active AND consecutive_failures + 1 < ?", disableAfter)).
+			Set("disabled_at", squirrel.Expr("CASE WHEN active AND consecutive_failures + 1 >= ? THEN now() ELSE disabled_at END", disableAfter))
+	}
+
+	query, args, err := update.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var disabled bool
+	err = pgx.BeginTxFunc(ctx, r.db, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, insert, insertArgs...); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(ctx, query, args...).Scan(&disabled)
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.Is(err, pgx.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation) {
+			return false, fmt.Errorf("%s: %w", op, ErrWebhookNotFound)
+		}
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return disabled, nil
+}
+
+// GetDeliveries returns delivery logs of webhook, most recent first.
+func (r *WebhooksRepo) GetDeliveries(ctx context.Context, webhookId int64, limit, offset int) ([]domain.WebhookDelivery, error) {
+	const op = "repository.Webhooks.GetDeliveries"
+
+	query, args, err := squirrel.
+		Select("id", "webhook_id", "outbox_id", "event_type", "success", "status_code", "attempts", "duration_ms", "error", "created_at").
+		From(deliveriesTable).
+		Where(squirrel.Eq{"webhook_id": webhookId}).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deliveries := []domain.WebhookDelivery{}
+	var d domain.WebhookDelivery
+	_, err = pgx.ForEachRow(rows, []any{&d.ID, &d.WebhookID, &d.OutboxID, &d.EventType, &d.Success, &d.StatusCode, &d.Attempts, &d.DurationMs, &d.Error, &d.CreatedAt}, func() error {
+		deliveries = append(deliveries, d)
+		// Reset nullable fields, so that next row doesn't scan into values referenced by this one.
+		d.StatusCode, d.Error = nil, nil
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func scanWebhook(row pgx.Row) (domain.Webhook, error) {
+	var w domain.Webhook
+	var eventTypes []string
+
+	err := row.Scan(&w.ID, &w.URL, &eventTypes, &w.Secret, &w.Active, &w.ConsecutiveFailures, &w.DisabledAt, &w.CreatedAt)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	w.EventTypes = make([]domain.EventType, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		w.EventTypes = append(w.EventTypes, domain.EventType(eventType))
+	}
+
+	return w, nil
+}
+
+func eventTypesToText(eventTypes []domain.EventType) []string {
+	resp := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		resp = append(resp, string(eventType))
+	}
+
+	return resp
+}
+
+func joinColumns(columns []string) string {
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"log/slog"
+	"time"
+)
+
+// DigestsService turns flats pending for subscribers with daily and weekly delivery
+// into a single digest message per subscriber once digest is due.
+type DigestsService struct {
+	repo repository.Outbox
+	log  *slog.Logger
+}
+
+func NewDigestsService(repo repository.Outbox, log *slog.Logger) *DigestsService {
+	return &DigestsService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Run flushes due digests every interval until ctx is done.
+func (s *DigestsService) Run(ctx context.Context, interval time.Duration) {
+	const op = "service.Digests.Run"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("starting digests scheduler")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Flush(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("failed to flush digests: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("digests scheduler stopped")
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush adds due digests to outbox and returns their number.
+func (s *DigestsService) Flush(ctx context.Context) (int64, error) {
+	const op = "service.Digests.Flush"
+
+	n, err := s.repo.FlushDigests(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n > 0 {
+		s.log.Info("digests enqueued", slog.String("op", op), slog.Int64("count", n))
+	}
+
+	return n, nil
+}
@@ -7,32 +7,25 @@ import (
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
 	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
 	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
-	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/sender"
+	"github.com/google/uuid"
 	"log/slog"
-	"sync"
 )
 
-var ()
-
 type FlatsService struct {
-	repo          repository.Flats
-	houseRepo     repository.Houses
-	notifications sender.Sender
-	wg            *sync.WaitGroup
-	log           *slog.Logger
+	repo       repository.Flats
+	moderation repository.Moderation
+	log        *slog.Logger
 }
 
-func NewFlatsService(repo repository.Flats, houseRepo repository.Houses, notifications sender.Sender, wg *sync.WaitGroup, log *slog.Logger) *FlatsService {
+func NewFlatsService(repo repository.Flats, moderation repository.Moderation, log *slog.Logger) *FlatsService {
 	return &FlatsService{
-		repo:          repo,
-		houseRepo:     houseRepo,
-		notifications: notifications,
-		wg:            wg,
-		log:           log,
+		repo:       repo,
+		moderation: moderation,
+		log:        log,
 	}
 }
 
-func (s *FlatsService) Create(ctx context.Context, flatInp dtos.FlatCreateInput) (domain.Flat, error) {
+func (s *FlatsService) Create(ctx context.Context, userId uuid.UUID, flatInp dtos.FlatCreateInput) (domain.Flat, error) {
 	const op = "service.Flats.Create"
 
 	log := s.log.With(
@@ -46,6 +39,7 @@ func (s *FlatsService) Create(ctx context.Context, flatInp dtos.FlatCreateInput)
 		Price:      flatInp.Price,
 		Rooms:      flatInp.Rooms,
 		Status:     domain.StatusCreated,
+		CreatedBy:  &userId,
 	}
 
 	log.Info("creating flatInp")
@@ -69,78 +63,191 @@ func (s *FlatsService) Create(ctx context.Context, flatInp dtos.FlatCreateInput)
 		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	s.wg.Add(1)
-	go func() {
-		defer s.wg.Done()
+	return resp, nil
+}
 
-		ctx := context.Background()
+// GetById returns flat with its house summary if user may see it.
+// Flat hidden from user is reported not found, so that its existence isn't disclosed.
+func (s *FlatsService) GetById(ctx context.Context, flatId int, userId uuid.UUID, userType domain.UserType) (domain.FlatDetails, error) {
+	const op = "service.Flats.GetById"
 
-		var recipients []string
-		subscribers, err := s.houseRepo.GetHouseSubscribers(ctx, flatInp.HouseId)
-		if err != nil {
-			s.log.Error("failed to get subscribers: " + err.Error())
+	resp, err := s.repo.GetById(ctx, flatId)
+	if err != nil {
+		if errors.Is(err, repository.ErrFlatNotFound) {
+			return domain.FlatDetails{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotFound)
+		}
+
+		s.log.Error("failed to get flat: " + err.Error())
 
-			return
-		} else {
-			recipients = append(recipients, subscribers...)
+		return domain.FlatDetails{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !resp.VisibleTo(userId, userType) {
+		// Edit of approved flat awaiting moderation is hidden, its approved version is shown instead.
+		if resp.Approved != nil {
+			return resp.ApprovedView(), nil
 		}
 
-		log.Info("sending email")
-		for _, r := range recipients {
-			if err := s.notifications.SendEmail(ctx, r, fmt.Sprintf("message for house %d subscriber!", flatInp.HouseId)); err != nil {
-				s.log.Error("failed to send email: " + err.Error())
-			}
+		return domain.FlatDetails{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotFound)
+	}
+
+	return resp, nil
+}
+
+// UserFlats returns flats created by user in any status.
+func (s *FlatsService) UserFlats(ctx context.Context, userId uuid.UUID) ([]domain.FlatDetails, error) {
+	const op = "service.Flats.UserFlats"
+
+	resp, err := s.repo.GetByCreator(ctx, userId)
+	if err != nil {
+		s.log.Error("failed to get user flats: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Transitions returns statuses flat may move to from its current status.
+func (s *FlatsService) Transitions(ctx context.Context, flatId int) (domain.FlatTransitions, error) {
+	const op = "service.Flats.Transitions"
+
+	flat, err := s.repo.GetById(ctx, flatId)
+	if err != nil {
+		if errors.Is(err, repository.ErrFlatNotFound) {
+			return domain.FlatTransitions{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotFound)
+		}
+
+		s.log.Error("failed to get flat: " + err.Error())
+
+		return domain.FlatTransitions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return domain.FlatTransitions{
+		FlatID: flat.ID,
+		Status: flat.Status,
+		Next:   flat.Status.NextStatuses(),
+	}, nil
+}
+
+// History returns status changes of flat, oldest first.
+func (s *FlatsService) History(ctx context.Context, flatId int) ([]domain.FlatStatusChange, error) {
+	const op = "service.Flats.History"
+
+	resp, err := s.repo.GetHistory(ctx, flatId)
+	if err != nil {
+		if errors.Is(err, repository.ErrFlatNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, domain.ErrFlatNotFound)
 		}
-	}()
+
+		s.log.Error("failed to get flat history: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
 	return resp, nil
 }
 
-func (s *FlatsService) Update(ctx context.Context, flatId int, status domain.Status) (domain.Flat, error) {
-	const op = "service.Flats.Update"
+// Edit changes flat attributes on behalf of its creator or moderator and sends flat back to moderation.
+// Approved version of the flat stays visible to clients until the edit is approved.
+func (s *FlatsService) Edit(ctx context.Context, flatId int, userId uuid.UUID, userType domain.UserType, inp dtos.FlatEditInput) (domain.Flat, error) {
+	const op = "service.Flats.Edit"
+
 	log := s.log.With(
 		slog.String("op", op),
 		slog.Int("flatId", flatId),
-		slog.String("status", status.String()),
 	)
 
-	log.Info("switching flat status")
-	// Check if flat is currently on moderation or start it.
-	// Switch Moderation (naming) is due to a necessity to switch it back in case of an error.
-	isBlocked, err := s.repo.SwitchModeration(ctx, flatId)
+	flat, err := s.GetById(ctx, flatId, userId, userType)
 	if err != nil {
-		s.log.Error("failed to switch flat status to 'moderation':" + err.Error())
+		return domain.Flat{}, err
+	}
 
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
+	if userType != domain.UserTypeModerator && !flat.IsCreatedBy(userId) {
+		return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrFlatEditForbidden)
 	}
 
-	if isBlocked {
-		s.log.Error("failed to switch flat status to 'moderation': " + ErrAlreadyModerating.Error())
+	log.Info("editing flat")
 
-		return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrAlreadyModerating)
+	resp, err := s.repo.Edit(ctx, flatId, userId, domain.FlatChanges{
+		FlatNumber: inp.FlatNumber,
+		Price:      inp.Price,
+		Rooms:      inp.Rooms,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrFlatNotFound) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotFound)
+		}
+
+		if errors.Is(err, repository.ErrFlatClaimed) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrFlatModerated)
+		}
+
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrInvalidTransition)
+		}
+
+		if errors.Is(err, repository.ErrFlatAlreadyExists) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrFlatAlreadyExists)
+		}
+
+		s.log.Error("failed to edit flat: " + err.Error())
+
+		return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	log.Info("updating flat status")
+	return resp, nil
+}
 
-	resp, err := s.repo.Update(ctx, flatId, status.String())
-	if err != nil {
-		err = s.repo.SwitchModerationBackTo(context.Background(), flatId, status.String())
+// Update sets flat status on behalf of moderator, who must hold the claim on the flat.
+// Declined flat gets decline reason from the catalogue and moderator's comment.
+// Claim and status transition are checked and flat is updated in a single transaction,
+// so that flat can't be left halfway through moderation.
+func (s *FlatsService) Update(ctx context.Context, moderatorId uuid.UUID, inp dtos.FlatUpdateInput) (domain.Flat, error) {
+	const op = "service.Flats.Update"
+
+	flatId, status := inp.FlatId, inp.Status
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("flatId", flatId),
+		slog.String("status", status.String()),
+	)
+
+	var decline *domain.FlatDecline
+	if status == domain.StatusDeclined {
+		reason, err := s.moderation.GetDeclineReason(ctx, inp.Reason)
 		if err != nil {
-			s.log.Error("failed to update and switch flat status to back: " + err.Error())
+			if errors.Is(err, repository.ErrDeclineReasonNotFound) {
+				return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrDeclineReasonNotFound)
+			}
+
+			s.log.Error("failed to get decline reason: " + err.Error())
 
 			return domain.Flat{}, fmt.Errorf("%s: %w", op, err)
 		}
 
-		if ctx.Err() != nil {
-			s.log.Error("failed to update flat status due to context error: " + ctx.Err().Error())
+		decline = &domain.FlatDecline{Reason: reason, Comment: inp.Comment}
+	}
 
-			return domain.Flat{}, fmt.Errorf("%s: %w", op, ctx.Err())
-		}
+	log.Info("updating flat status")
 
+	resp, err := s.repo.Update(ctx, flatId, moderatorId, status.String(), decline)
+	if err != nil {
 		if errors.Is(err, repository.ErrFlatNotFound) {
-			s.log.Error("flat not found: " + err.Error())
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotFound)
+		}
+
+		if errors.Is(err, repository.ErrFlatNotClaimed) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotClaimed)
+		}
+
+		if errors.Is(err, repository.ErrInvalidTransition) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrInvalidTransition)
+		}
 
-			return domain.Flat{}, fmt.Errorf("%s: %w", op, ErrFlatNotFound)
+		if errors.Is(err, repository.ErrDeclineReasonNotFound) {
+			return domain.Flat{}, fmt.Errorf("%s: %w", op, domain.ErrDeclineReasonNotFound)
 		}
 
 		s.log.Error("failed to update flat status: " + err.Error())
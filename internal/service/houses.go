@@ -7,20 +7,26 @@ import (
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
 	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
 	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/unsubscribe"
+	"github.com/google/uuid"
 	"log/slog"
 	"time"
 )
 
 type HousesService struct {
-	repo repository.Houses
+	repo        repository.Houses
+	usersRepo   repository.Users
+	unsubscribe *unsubscribe.Signer
 
 	log *slog.Logger
 }
 
-func NewHousesService(repo repository.Houses, log *slog.Logger) *HousesService {
+func NewHousesService(repo repository.Houses, usersRepo repository.Users, unsubscribe *unsubscribe.Signer, log *slog.Logger) *HousesService {
 	return &HousesService{
-		repo: repo,
-		log:  log,
+		repo:        repo,
+		usersRepo:   usersRepo,
+		unsubscribe: unsubscribe,
+		log:         log,
 	}
 }
 
@@ -49,6 +55,69 @@ func (s *HousesService) GetById(ctx context.Context, id int) ([]domain.Flat, err
 	return resp, nil
 }
 
+// LastModified returns time of the last change of house or any of its flats.
+func (s *HousesService) LastModified(ctx context.Context, id int) (time.Time, error) {
+	const op = "service.Houses.LastModified"
+
+	updatedAt, err := s.repo.GetUpdatedAt(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrHouseNotFound) {
+			s.log.Error("house not found: " + err.Error())
+
+			return time.Time{}, fmt.Errorf("%s: %w", op, domain.ErrHouseNotFound)
+		}
+
+		s.log.Error("failed to get house modification time: " + err.Error())
+
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updatedAt, nil
+}
+
+// ExportFlats passes house flats visible to user to fn one by one.
+func (s *HousesService) ExportFlats(ctx context.Context, id int, fn func(flat domain.Flat) error) error {
+	const op = "service.Houses.ExportFlats"
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("house_id", id),
+	)
+
+	log.Info("exporting house flats")
+
+	if err := s.repo.ForEachFlat(ctx, id, fn); err != nil {
+		if errors.Is(err, repository.ErrHouseNotFound) {
+			s.log.Error("house not found: " + err.Error())
+
+			return fmt.Errorf("%s: %w", op, domain.ErrHouseNotFound)
+		}
+
+		s.log.Error("failed to export house flats: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ExportHouses passes all houses to fn one by one.
+func (s *HousesService) ExportHouses(ctx context.Context, fn func(house domain.House) error) error {
+	const op = "service.Houses.ExportHouses"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	log.Info("exporting houses")
+
+	if err := s.repo.ForEach(ctx, fn); err != nil {
+		s.log.Error("failed to export houses: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
 func (s *HousesService) Create(ctx context.Context, house dtos.HouseCreateInput) (domain.House, error) {
 	const op = "service.Houses.Create"
 
@@ -84,29 +153,42 @@ func (s *HousesService) Create(ctx context.Context, house dtos.HouseCreateInput)
 	return resp, nil
 }
 
-func (s *HousesService) Subscribe(ctx context.Context, houseId int, email string) error {
+func (s *HousesService) Subscribe(ctx context.Context, houseId int, inp dtos.HouseSubscribeInput) error {
 	const op = "service.Houses.Subscribe"
 
 	log := s.log.With(
 		slog.String("op", op),
 		slog.Int("house_id", houseId),
-		slog.String("email", email),
+		slog.String("email", inp.Email),
 	)
 
 	log.Info("subscribing user to house")
 
-	if err := s.repo.SubscribeUser(ctx, houseId, email); err != nil {
+	sub := domain.Subscription{
+		HouseID:  houseId,
+		Email:    inp.Email,
+		MinRooms: inp.MinRooms,
+		MaxRooms: inp.MaxRooms,
+		MinPrice: inp.MinPrice,
+		MaxPrice: inp.MaxPrice,
+		Delivery: inp.Delivery,
+	}
+	if sub.Delivery == "" {
+		sub.Delivery = domain.DeliveryImmediate
+	}
+
+	if err := s.repo.SubscribeUser(ctx, sub); err != nil {
 
 		if errors.Is(err, repository.ErrUserAlreadySubscribed) {
 			s.log.Error("user already subscribed: " + err.Error())
 
-			return fmt.Errorf("%s: %w", op, ErrUserAlreadySubscribed)
+			return fmt.Errorf("%s: %w", op, domain.ErrUserAlreadySubscribed)
 		}
 
-		if errors.Is(err, repository.ErrHouseNotFound) {
-			s.log.Error("house not found: " + err.Error())
+		if errors.Is(err, repository.ErrUserOrHouseNotFound) {
+			s.log.Error("user or house not found: " + err.Error())
 
-			return fmt.Errorf("%s: %w", op, ErrHouseNotFound)
+			return fmt.Errorf("%s: %w", op, domain.ErrUserOrHouseNotFound)
 		}
 
 		s.log.Error("failed to subscribe user: " + err.Error())
@@ -116,3 +198,104 @@ func (s *HousesService) Subscribe(ctx context.Context, houseId int, email string
 
 	return nil
 }
+
+func (s *HousesService) Unsubscribe(ctx context.Context, houseId int, userId uuid.UUID, email string) error {
+	const op = "service.Houses.Unsubscribe"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("house_id", houseId),
+		slog.String("user_id", userId.String()),
+		slog.String("email", email),
+	)
+
+	log.Info("unsubscribing user from house")
+
+	// Users may only cancel subscriptions of their own email.
+	user, err := s.usersRepo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrForeignSubscription)
+		}
+
+		s.log.Error("failed to get user: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.Email != email {
+		return fmt.Errorf("%s: %w", op, domain.ErrForeignSubscription)
+	}
+
+	if err := s.repo.UnsubscribeUser(ctx, houseId, email); err != nil {
+		if errors.Is(err, repository.ErrSubscriptionNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrSubscriptionNotFound)
+		}
+
+		s.log.Error("failed to unsubscribe user: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// VerifyUnsubscribe returns subscriptions signed unsubscribe token of notification email is issued for,
+// leaving them as is, so that recipient confirms unsubscribing first.
+func (s *HousesService) VerifyUnsubscribe(ctx context.Context, token string) (domain.Unsubscription, error) {
+	const op = "service.Houses.VerifyUnsubscribe"
+
+	claims, err := s.verifyUnsubscribe(token)
+	if err != nil {
+		return domain.Unsubscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return domain.Unsubscription{
+		Email:    claims.Email,
+		HouseIDs: claims.HouseIDs,
+	}, nil
+}
+
+// UnsubscribeByToken removes subscriptions signed unsubscribe token of notification email is issued for.
+// Repeated unsubscribing succeeds as well, so that link may be followed several times.
+func (s *HousesService) UnsubscribeByToken(ctx context.Context, token string) (domain.Unsubscription, error) {
+	const op = "service.Houses.UnsubscribeByToken"
+
+	claims, err := s.verifyUnsubscribe(token)
+	if err != nil {
+		return domain.Unsubscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("email", claims.Email),
+		slog.Any("house_ids", claims.HouseIDs),
+	)
+
+	log.Info("unsubscribing user by link")
+
+	if _, err = s.repo.UnsubscribeUserFromHouses(ctx, claims.Email, claims.HouseIDs); err != nil {
+		s.log.Error("failed to unsubscribe user: " + err.Error())
+
+		return domain.Unsubscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return domain.Unsubscription{
+		Email:    claims.Email,
+		HouseIDs: claims.HouseIDs,
+	}, nil
+}
+
+// verifyUnsubscribe returns claims of unsubscribe token, telling expired token from invalid one.
+func (s *HousesService) verifyUnsubscribe(token string) (unsubscribe.Claims, error) {
+	claims, err := s.unsubscribe.Verify(token)
+	if err != nil {
+		if errors.Is(err, unsubscribe.ErrTokenExpired) {
+			return unsubscribe.Claims{}, domain.ErrUnsubscribeExpired
+		}
+
+		return unsubscribe.Claims{}, domain.ErrInvalidUnsubscribe
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/workerpool"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ImportsService struct {
+	flatsRepo  repository.Flats
+	housesRepo repository.Houses
+
+	// Finished jobs are kept for polling for jobTTL.
+	jobsMu sync.RWMutex
+	jobs   map[uuid.UUID]*domain.ImportJob
+	jobTTL time.Duration
+
+	pool *workerpool.Pool
+	log  *slog.Logger
+}
+
+func NewImportsService(flatsRepo repository.Flats, housesRepo repository.Houses, pool *workerpool.Pool, jobTTL time.Duration, log *slog.Logger) *ImportsService {
+	return &ImportsService{
+		flatsRepo:  flatsRepo,
+		housesRepo: housesRepo,
+		jobs:       make(map[uuid.UUID]*domain.ImportJob),
+		jobTTL:     jobTTL,
+		pool:       pool,
+		log:        log,
+	}
+}
+
+// importRows holds decoded rows of a file together with the rows that failed to decode or validate.
+type importRows struct {
+	total   int
+	houses  []domain.House
+	flats   []domain.ImportFlat
+	numbers []int // numbers[i] is a file row number of i-th valid house or flat.
+	errors  []domain.ImportRowError
+}
+
+// Start decodes and validates file and imports it in background.
+// Returned job can be polled with GetJob. Job is rejected if too many of them are already waiting.
+func (s *ImportsService) Start(ctx context.Context, inp dtos.ImportInput, file io.Reader) (domain.ImportJob, error) {
+	const op = "service.Imports.Start"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("entity", string(inp.Entity)),
+		slog.String("mode", string(inp.Mode)),
+		slog.Bool("dry_run", inp.DryRun),
+	)
+
+	inp = inp.WithDefaults()
+	if err := inp.Validate(); err != nil {
+		return domain.ImportJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := decodeImport(inp, file)
+	if err != nil {
+		log.Error("failed to decode import file: " + err.Error())
+
+		return domain.ImportJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Request might have been cancelled while file was read.
+	if err = ctx.Err(); err != nil {
+		return domain.ImportJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	job := s.newJob(inp, rows.total)
+
+	log.Info("starting import job", slog.String("job_id", job.ID.String()))
+
+	err = s.pool.Submit(func() {
+		// Job must outlive the request it was started from.
+		s.run(context.WithoutCancel(ctx), job.ID, inp, rows)
+	})
+	if err != nil {
+		s.deleteJob(job.ID)
+
+		log.Warn("import job rejected: " + err.Error())
+
+		return domain.ImportJob{}, fmt.Errorf("%s: %w", op, domain.ErrTooManyImports)
+	}
+
+	return job, nil
+}
+
+// Run imports file synchronously and returns finished job.
+func (s *ImportsService) Run(ctx context.Context, inp dtos.ImportInput, file io.Reader) (domain.ImportJob, error) {
+	const op = "service.Imports.Run"
+
+	inp = inp.WithDefaults()
+	if err := inp.Validate(); err != nil {
+		return domain.ImportJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := decodeImport(inp, file)
+	if err != nil {
+		s.log.Error("failed to decode import file: " + err.Error())
+
+		return domain.ImportJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	job := s.newJob(inp, rows.total)
+	s.run(ctx, job.ID, inp, rows)
+
+	return s.GetJob(job.ID)
+}
+
+func (s *ImportsService) GetJob(id uuid.UUID) (domain.ImportJob, error) {
+	const op = "service.Imports.GetJob"
+
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok || s.expired(job, time.Now()) {
+		return domain.ImportJob{}, fmt.Errorf("%s: %w", op, domain.ErrImportJobNotFound)
+	}
+
+	resp := *job
+	resp.Errors = append([]domain.ImportRowError(nil), job.Errors...)
+
+	return resp, nil
+}
+
+func (s *ImportsService) newJob(inp dtos.ImportInput, total int) domain.ImportJob {
+	job := &domain.ImportJob{
+		ID:        uuid.New(),
+		Entity:    inp.Entity,
+		Mode:      inp.Mode,
+		DryRun:    inp.DryRun,
+		Status:    domain.ImportStatusPending,
+		Total:     total,
+		CreatedAt: time.Now(),
+	}
+
+	s.jobsMu.Lock()
+	s.evictJobs(job.CreatedAt)
+	s.jobs[job.ID] = job
+	s.jobsMu.Unlock()
+
+	return *job
+}
+
+// evictJobs drops expired jobs, so that they don't pile up as new ones start. jobsMu must be held.
+func (s *ImportsService) evictJobs(now time.Time) {
+	for id, job := range s.jobs {
+		if s.expired(job, now) {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// expired reports whether job finished more than jobTTL ago.
+func (s *ImportsService) expired(job *domain.ImportJob, now time.Time) bool {
+	return job.FinishedAt != nil && now.Sub(*job.FinishedAt) > s.jobTTL
+}
+
+func (s *ImportsService) deleteJob(id uuid.UUID) {
+	s.jobsMu.Lock()
+	delete(s.jobs, id)
+	s.jobsMu.Unlock()
+}
+
+func (s *ImportsService) updateJob(id uuid.UUID, fn func(job *domain.ImportJob)) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+
+	fn(s.jobs[id])
+}
+
+func (s *ImportsService) run(ctx context.Context, jobId uuid.UUID, inp dtos.ImportInput, rows importRows) {
+	const op = "service.Imports.run"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("job_id", jobId.String()),
+	)
+
+	s.updateJob(jobId, func(job *domain.ImportJob) {
+		job.Status = domain.ImportStatusRunning
+		job.Failed = len(rows.errors)
+		job.Errors = append(job.Errors, rows.errors...)
+	})
+
+	valid := len(rows.numbers)
+
+	// In transaction mode a single invalid row cancels whole import,
+	// but valid rows are still checked against database to report all problems at once.
+	atomic := inp.Mode == domain.ImportModeTransaction
+	dryRun := inp.DryRun || (atomic && len(rows.errors) > 0)
+
+	chunkSize := inp.ChunkSize
+	if atomic || chunkSize <= 0 {
+		chunkSize = valid
+	}
+
+	var err error
+	for from := 0; from < valid; from += chunkSize {
+		to := min(from+chunkSize, valid)
+
+		var errs []error
+		switch inp.Entity {
+		case domain.ImportEntityHouses:
+			errs, err = s.housesRepo.CreateBatch(ctx, rows.houses[from:to], atomic, dryRun)
+		case domain.ImportEntityFlats:
+			errs, err = s.flatsRepo.CreateBatch(ctx, rows.flats[from:to], atomic, dryRun)
+		}
+		if err != nil {
+			break
+		}
+
+		var rowErrs []domain.ImportRowError
+		for i, rowErr := range errs {
+			if rowErr != nil {
+				rowErrs = append(rowErrs, domain.ImportRowError{Row: rows.numbers[from+i], Error: importErrMessage(rowErr)})
+			}
+		}
+
+		// Atomic batch with a failed row is rolled back entirely.
+		succeeded := to - from - len(rowErrs)
+		if atomic && (len(rowErrs) > 0 || len(rows.errors) > 0) {
+			succeeded = 0
+		}
+
+		s.updateJob(jobId, func(job *domain.ImportJob) {
+			job.Succeeded += succeeded
+			job.Failed += len(rowErrs)
+			job.Errors = append(job.Errors, rowErrs...)
+		})
+	}
+
+	s.updateJob(jobId, func(job *domain.ImportJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Status = domain.ImportStatusCompleted
+
+		if err != nil {
+			job.Status = domain.ImportStatusFailed
+			job.Error = "internal error"
+		}
+	})
+
+	if err != nil {
+		log.Error("import job failed: " + err.Error())
+
+		return
+	}
+
+	log.Info("import job completed")
+}
+
+// importErrMessage hides internal errors from import report.
+func importErrMessage(err error) string {
+	switch {
+	case errors.Is(err, repository.ErrFlatAlreadyExists):
+		return domain.ErrFlatAlreadyExists.Error()
+	case errors.Is(err, repository.ErrHouseNotFound):
+		return domain.ErrHouseNotFound.Error()
+	case errors.Is(err, repository.ErrHouseAlreadyExists):
+		return domain.ErrHouseAlreadyExists.Error()
+	}
+
+	return "internal error"
+}
+
+func decodeImport(inp dtos.ImportInput, file io.Reader) (importRows, error) {
+	var records []map[string]string
+	var err error
+
+	switch inp.Format {
+	case domain.ImportFormatCSV:
+		records, err = decodeCSV(file)
+	case domain.ImportFormatJSON:
+		records, err = decodeJSON(file)
+	default:
+		err = domain.ErrInvalidImportFile
+	}
+	if err != nil {
+		return importRows{}, err
+	}
+
+	rows := importRows{total: len(records)}
+
+	for i, record := range records {
+		number := i + 1
+
+		switch inp.Entity {
+		case domain.ImportEntityHouses:
+			house, err := houseFromRecord(record)
+			if err != nil {
+				rows.errors = append(rows.errors, domain.ImportRowError{Row: number, Error: err.Error()})
+				continue
+			}
+
+			rows.houses = append(rows.houses, house)
+		case domain.ImportEntityFlats:
+			flat, err := flatFromRecord(record)
+			if err != nil {
+				rows.errors = append(rows.errors, domain.ImportRowError{Row: number, Error: err.Error()})
+				continue
+			}
+
+			rows.flats = append(rows.flats, flat)
+		}
+
+		rows.numbers = append(rows.numbers, number)
+	}
+
+	return rows, nil
+}
+
+// decodeCSV reads CSV file with a header row naming columns the same way as JSON input fields.
+func decodeCSV(file io.Reader) ([]map[string]string, error) {
+	r := csv.NewReader(file)
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header", domain.ErrInvalidImportFile)
+	}
+
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var records []map[string]string
+	for {
+		line, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidImportFile, err.Error())
+		}
+
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(line) {
+				record[column] = strings.TrimSpace(line[i])
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// decodeJSON reads JSON array of objects.
+func decodeJSON(file io.Reader) ([]map[string]string, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.NewDecoder(file).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidImportFile, err.Error())
+	}
+
+	records := make([]map[string]string, 0, len(raw))
+	for _, object := range raw {
+		record := make(map[string]string, len(object))
+		for key, value := range object {
+			var str string
+			if err := json.Unmarshal(value, &str); err != nil {
+				str = string(value)
+			}
+
+			record[key] = str
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func flatFromRecord(record map[string]string) (domain.ImportFlat, error) {
+	var inp dtos.FlatCreateInput
+	var err error
+
+	if inp.FlatNumber, err = intField(record, "flat_number"); err != nil {
+		return domain.ImportFlat{}, err
+	}
+	if inp.HouseId, err = intField(record, "house_id"); err != nil {
+		return domain.ImportFlat{}, err
+	}
+	if inp.Price, err = intField(record, "price"); err != nil {
+		return domain.ImportFlat{}, err
+	}
+	if inp.Rooms, err = intField(record, "rooms"); err != nil {
+		return domain.ImportFlat{}, err
+	}
+
+	if err = inp.Validate(); err != nil {
+		return domain.ImportFlat{}, err
+	}
+
+	return domain.ImportFlat{
+		HouseID: inp.HouseId,
+		Flat: domain.Flat{
+			FlatNumber: inp.FlatNumber,
+			Price:      inp.Price,
+			Rooms:      inp.Rooms,
+			Status:     domain.StatusCreated,
+		},
+	}, nil
+}
+
+func houseFromRecord(record map[string]string) (domain.House, error) {
+	inp := dtos.HouseCreateInput{
+		Address:   record["address"],
+		Developer: record["developer"],
+	}
+
+	var err error
+	if inp.Year, err = intField(record, "year"); err != nil {
+		return domain.House{}, err
+	}
+
+	if err = inp.Validate(); err != nil {
+		return domain.House{}, err
+	}
+
+	return domain.House{
+		Address:   inp.Address,
+		Year:      inp.Year,
+		Developer: inp.Developer,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// intField parses integer field, leaving empty one zero so that binding rules can reject it.
+func intField(record map[string]string, name string) (int, error) {
+	value := record[name]
+	if value == "" || value == "null" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+
+	return n, nil
+}
@@ -6,11 +6,14 @@ package service
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/dzhordano/avito-bootcamp2024/internal/domain"
 	dtos "github.com/dzhordano/avito-bootcamp2024/internal/dtos"
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 )
 
 // MockHouses is a mock of Houses interface.
@@ -51,6 +54,34 @@ func (mr *MockHousesMockRecorder) Create(ctx, house interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockHouses)(nil).Create), ctx, house)
 }
 
+// ExportFlats mocks base method.
+func (m *MockHouses) ExportFlats(ctx context.Context, id int, fn func(domain.Flat) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportFlats", ctx, id, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportFlats indicates an expected call of ExportFlats.
+func (mr *MockHousesMockRecorder) ExportFlats(ctx, id, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportFlats", reflect.TypeOf((*MockHouses)(nil).ExportFlats), ctx, id, fn)
+}
+
+// ExportHouses mocks base method.
+func (m *MockHouses) ExportHouses(ctx context.Context, fn func(domain.House) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportHouses", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportHouses indicates an expected call of ExportHouses.
+func (mr *MockHousesMockRecorder) ExportHouses(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportHouses", reflect.TypeOf((*MockHouses)(nil).ExportHouses), ctx, fn)
+}
+
 // GetById mocks base method.
 func (m *MockHouses) GetById(ctx context.Context, id int) ([]domain.Flat, error) {
 	m.ctrl.T.Helper()
@@ -66,18 +97,77 @@ func (mr *MockHousesMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockHouses)(nil).GetById), ctx, id)
 }
 
+// LastModified mocks base method.
+func (m *MockHouses) LastModified(ctx context.Context, id int) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastModified", ctx, id)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LastModified indicates an expected call of LastModified.
+func (mr *MockHousesMockRecorder) LastModified(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastModified", reflect.TypeOf((*MockHouses)(nil).LastModified), ctx, id)
+}
+
 // Subscribe mocks base method.
-func (m *MockHouses) Subscribe(ctx context.Context, houseId int, email string) error {
+func (m *MockHouses) Subscribe(ctx context.Context, houseId int, inp dtos.HouseSubscribeInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Subscribe", ctx, houseId, email)
+	ret := m.ctrl.Call(m, "Subscribe", ctx, houseId, inp)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Subscribe indicates an expected call of Subscribe.
-func (mr *MockHousesMockRecorder) Subscribe(ctx, houseId, email interface{}) *gomock.Call {
+func (mr *MockHousesMockRecorder) Subscribe(ctx, houseId, inp interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockHouses)(nil).Subscribe), ctx, houseId, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockHouses)(nil).Subscribe), ctx, houseId, inp)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockHouses) Unsubscribe(ctx context.Context, houseId int, userId uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, houseId, userId, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockHousesMockRecorder) Unsubscribe(ctx, houseId, userId, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockHouses)(nil).Unsubscribe), ctx, houseId, userId, email)
+}
+
+// UnsubscribeByToken mocks base method.
+func (m *MockHouses) UnsubscribeByToken(ctx context.Context, token string) (domain.Unsubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsubscribeByToken", ctx, token)
+	ret0, _ := ret[0].(domain.Unsubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnsubscribeByToken indicates an expected call of UnsubscribeByToken.
+func (mr *MockHousesMockRecorder) UnsubscribeByToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeByToken", reflect.TypeOf((*MockHouses)(nil).UnsubscribeByToken), ctx, token)
+}
+
+// VerifyUnsubscribe mocks base method.
+func (m *MockHouses) VerifyUnsubscribe(ctx context.Context, token string) (domain.Unsubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUnsubscribe", ctx, token)
+	ret0, _ := ret[0].(domain.Unsubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyUnsubscribe indicates an expected call of VerifyUnsubscribe.
+func (mr *MockHousesMockRecorder) VerifyUnsubscribe(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUnsubscribe", reflect.TypeOf((*MockHouses)(nil).VerifyUnsubscribe), ctx, token)
 }
 
 // MockFlats is a mock of Flats interface.
@@ -104,33 +194,232 @@ func (m *MockFlats) EXPECT() *MockFlatsMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockFlats) Create(ctx context.Context, flat dtos.FlatCreateInput) (domain.Flat, error) {
+func (m *MockFlats) Create(ctx context.Context, userId uuid.UUID, flat dtos.FlatCreateInput) (domain.Flat, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, flat)
+	ret := m.ctrl.Call(m, "Create", ctx, userId, flat)
 	ret0, _ := ret[0].(domain.Flat)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockFlatsMockRecorder) Create(ctx, flat interface{}) *gomock.Call {
+func (mr *MockFlatsMockRecorder) Create(ctx, userId, flat interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFlats)(nil).Create), ctx, userId, flat)
+}
+
+// Edit mocks base method.
+func (m *MockFlats) Edit(ctx context.Context, flatId int, userId uuid.UUID, userType domain.UserType, inp dtos.FlatEditInput) (domain.Flat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Edit", ctx, flatId, userId, userType, inp)
+	ret0, _ := ret[0].(domain.Flat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Edit indicates an expected call of Edit.
+func (mr *MockFlatsMockRecorder) Edit(ctx, flatId, userId, userType, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edit", reflect.TypeOf((*MockFlats)(nil).Edit), ctx, flatId, userId, userType, inp)
+}
+
+// GetById mocks base method.
+func (m *MockFlats) GetById(ctx context.Context, flatId int, userId uuid.UUID, userType domain.UserType) (domain.FlatDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, flatId, userId, userType)
+	ret0, _ := ret[0].(domain.FlatDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockFlatsMockRecorder) GetById(ctx, flatId, userId, userType interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFlats)(nil).Create), ctx, flat)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockFlats)(nil).GetById), ctx, flatId, userId, userType)
+}
+
+// History mocks base method.
+func (m *MockFlats) History(ctx context.Context, flatId int) ([]domain.FlatStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, flatId)
+	ret0, _ := ret[0].([]domain.FlatStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockFlatsMockRecorder) History(ctx, flatId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockFlats)(nil).History), ctx, flatId)
+}
+
+// Transitions mocks base method.
+func (m *MockFlats) Transitions(ctx context.Context, flatId int) (domain.FlatTransitions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transitions", ctx, flatId)
+	ret0, _ := ret[0].(domain.FlatTransitions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transitions indicates an expected call of Transitions.
+func (mr *MockFlatsMockRecorder) Transitions(ctx, flatId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transitions", reflect.TypeOf((*MockFlats)(nil).Transitions), ctx, flatId)
 }
 
 // Update mocks base method.
-func (m *MockFlats) Update(ctx context.Context, flatId int, status domain.Status) (domain.Flat, error) {
+func (m *MockFlats) Update(ctx context.Context, moderatorId uuid.UUID, inp dtos.FlatUpdateInput) (domain.Flat, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, flatId, status)
+	ret := m.ctrl.Call(m, "Update", ctx, moderatorId, inp)
 	ret0, _ := ret[0].(domain.Flat)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockFlatsMockRecorder) Update(ctx, flatId, status interface{}) *gomock.Call {
+func (mr *MockFlatsMockRecorder) Update(ctx, moderatorId, inp interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFlats)(nil).Update), ctx, flatId, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFlats)(nil).Update), ctx, moderatorId, inp)
+}
+
+// UserFlats mocks base method.
+func (m *MockFlats) UserFlats(ctx context.Context, userId uuid.UUID) ([]domain.FlatDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserFlats", ctx, userId)
+	ret0, _ := ret[0].([]domain.FlatDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserFlats indicates an expected call of UserFlats.
+func (mr *MockFlatsMockRecorder) UserFlats(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserFlats", reflect.TypeOf((*MockFlats)(nil).UserFlats), ctx, userId)
+}
+
+// MockModeration is a mock of Moderation interface.
+type MockModeration struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationMockRecorder
+}
+
+// MockModerationMockRecorder is the mock recorder for MockModeration.
+type MockModerationMockRecorder struct {
+	mock *MockModeration
+}
+
+// NewMockModeration creates a new mock instance.
+func NewMockModeration(ctrl *gomock.Controller) *MockModeration {
+	mock := &MockModeration{ctrl: ctrl}
+	mock.recorder = &MockModerationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModeration) EXPECT() *MockModerationMockRecorder {
+	return m.recorder
+}
+
+// ArchiveDeclineReason mocks base method.
+func (m *MockModeration) ArchiveDeclineReason(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveDeclineReason", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveDeclineReason indicates an expected call of ArchiveDeclineReason.
+func (mr *MockModerationMockRecorder) ArchiveDeclineReason(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveDeclineReason", reflect.TypeOf((*MockModeration)(nil).ArchiveDeclineReason), ctx, code)
+}
+
+// Claim mocks base method.
+func (m *MockModeration) Claim(ctx context.Context, flatId int, moderatorId uuid.UUID) (domain.FlatClaim, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, flatId, moderatorId)
+	ret0, _ := ret[0].(domain.FlatClaim)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockModerationMockRecorder) Claim(ctx, flatId, moderatorId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockModeration)(nil).Claim), ctx, flatId, moderatorId)
+}
+
+// DeclineReasons mocks base method.
+func (m *MockModeration) DeclineReasons(ctx context.Context, withArchived bool) ([]domain.DeclineReason, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineReasons", ctx, withArchived)
+	ret0, _ := ret[0].([]domain.DeclineReason)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeclineReasons indicates an expected call of DeclineReasons.
+func (mr *MockModerationMockRecorder) DeclineReasons(ctx, withArchived interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineReasons", reflect.TypeOf((*MockModeration)(nil).DeclineReasons), ctx, withArchived)
+}
+
+// Queue mocks base method.
+func (m *MockModeration) Queue(ctx context.Context, moderatorId uuid.UUID, inp dtos.ModerationQueueInput) ([]domain.QueuedFlat, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queue", ctx, moderatorId, inp)
+	ret0, _ := ret[0].([]domain.QueuedFlat)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queue indicates an expected call of Queue.
+func (mr *MockModerationMockRecorder) Queue(ctx, moderatorId, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockModeration)(nil).Queue), ctx, moderatorId, inp)
+}
+
+// ReleaseExpired mocks base method.
+func (m *MockModeration) ReleaseExpired(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseExpired", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseExpired indicates an expected call of ReleaseExpired.
+func (mr *MockModerationMockRecorder) ReleaseExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseExpired", reflect.TypeOf((*MockModeration)(nil).ReleaseExpired), ctx)
+}
+
+// Run mocks base method.
+func (m *MockModeration) Run(ctx context.Context, interval time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx, interval)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockModerationMockRecorder) Run(ctx, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockModeration)(nil).Run), ctx, interval)
+}
+
+// SetDeclineReason mocks base method.
+func (m *MockModeration) SetDeclineReason(ctx context.Context, inp dtos.DeclineReasonInput) (domain.DeclineReason, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDeclineReason", ctx, inp)
+	ret0, _ := ret[0].(domain.DeclineReason)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetDeclineReason indicates an expected call of SetDeclineReason.
+func (mr *MockModerationMockRecorder) SetDeclineReason(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeclineReason", reflect.TypeOf((*MockModeration)(nil).SetDeclineReason), ctx, inp)
 }
 
 // MockUsers is a mock of Users interface.
@@ -171,6 +460,21 @@ func (mr *MockUsersMockRecorder) DummyLogin(userType interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DummyLogin", reflect.TypeOf((*MockUsers)(nil).DummyLogin), userType)
 }
 
+// Export mocks base method.
+func (m *MockUsers) Export(ctx context.Context, userId uuid.UUID) (domain.AccountExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, userId)
+	ret0, _ := ret[0].(domain.AccountExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUsersMockRecorder) Export(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUsers)(nil).Export), ctx, userId)
+}
+
 // Login mocks base method.
 func (m *MockUsers) Login(ctx context.Context, user dtos.UserLoginInput) (string, error) {
 	m.ctrl.T.Helper()
@@ -186,6 +490,21 @@ func (mr *MockUsersMockRecorder) Login(ctx, user interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUsers)(nil).Login), ctx, user)
 }
 
+// NotificationSettings mocks base method.
+func (m *MockUsers) NotificationSettings(ctx context.Context, userId uuid.UUID) (domain.NotificationSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotificationSettings", ctx, userId)
+	ret0, _ := ret[0].(domain.NotificationSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotificationSettings indicates an expected call of NotificationSettings.
+func (mr *MockUsersMockRecorder) NotificationSettings(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotificationSettings", reflect.TypeOf((*MockUsers)(nil).NotificationSettings), ctx, userId)
+}
+
 // Register mocks base method.
 func (m *MockUsers) Register(ctx context.Context, user dtos.UserRegisterInput) (string, error) {
 	m.ctrl.T.Helper()
@@ -200,3 +519,464 @@ func (mr *MockUsersMockRecorder) Register(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUsers)(nil).Register), ctx, user)
 }
+
+// SetLanguage mocks base method.
+func (m *MockUsers) SetLanguage(ctx context.Context, userId uuid.UUID, language domain.Language) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLanguage", ctx, userId, language)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLanguage indicates an expected call of SetLanguage.
+func (mr *MockUsersMockRecorder) SetLanguage(ctx, userId, language interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLanguage", reflect.TypeOf((*MockUsers)(nil).SetLanguage), ctx, userId, language)
+}
+
+// SetNotificationSettings mocks base method.
+func (m *MockUsers) SetNotificationSettings(ctx context.Context, userId uuid.UUID, inp dtos.NotificationSettingsInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationSettings", ctx, userId, inp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotificationSettings indicates an expected call of SetNotificationSettings.
+func (mr *MockUsersMockRecorder) SetNotificationSettings(ctx, userId, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationSettings", reflect.TypeOf((*MockUsers)(nil).SetNotificationSettings), ctx, userId, inp)
+}
+
+// SetSubscriptionDelivery mocks base method.
+func (m *MockUsers) SetSubscriptionDelivery(ctx context.Context, userId uuid.UUID, houseId int, delivery domain.Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSubscriptionDelivery", ctx, userId, houseId, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSubscriptionDelivery indicates an expected call of SetSubscriptionDelivery.
+func (mr *MockUsersMockRecorder) SetSubscriptionDelivery(ctx, userId, houseId, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSubscriptionDelivery", reflect.TypeOf((*MockUsers)(nil).SetSubscriptionDelivery), ctx, userId, houseId, delivery)
+}
+
+// Subscriptions mocks base method.
+func (m *MockUsers) Subscriptions(ctx context.Context, userId uuid.UUID) ([]domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscriptions", ctx, userId)
+	ret0, _ := ret[0].([]domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscriptions indicates an expected call of Subscriptions.
+func (mr *MockUsersMockRecorder) Subscriptions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscriptions", reflect.TypeOf((*MockUsers)(nil).Subscriptions), ctx, userId)
+}
+
+// MockImports is a mock of Imports interface.
+type MockImports struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportsMockRecorder
+}
+
+// MockImportsMockRecorder is the mock recorder for MockImports.
+type MockImportsMockRecorder struct {
+	mock *MockImports
+}
+
+// NewMockImports creates a new mock instance.
+func NewMockImports(ctrl *gomock.Controller) *MockImports {
+	mock := &MockImports{ctrl: ctrl}
+	mock.recorder = &MockImportsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImports) EXPECT() *MockImportsMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockImports) GetJob(id uuid.UUID) (domain.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", id)
+	ret0, _ := ret[0].(domain.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockImportsMockRecorder) GetJob(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockImports)(nil).GetJob), id)
+}
+
+// Run mocks base method.
+func (m *MockImports) Run(ctx context.Context, inp dtos.ImportInput, file io.Reader) (domain.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx, inp, file)
+	ret0, _ := ret[0].(domain.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockImportsMockRecorder) Run(ctx, inp, file interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockImports)(nil).Run), ctx, inp, file)
+}
+
+// Start mocks base method.
+func (m *MockImports) Start(ctx context.Context, inp dtos.ImportInput, file io.Reader) (domain.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx, inp, file)
+	ret0, _ := ret[0].(domain.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockImportsMockRecorder) Start(ctx, inp, file interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockImports)(nil).Start), ctx, inp, file)
+}
+
+// MockOutbox is a mock of Outbox interface.
+type MockOutbox struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxMockRecorder
+}
+
+// MockOutboxMockRecorder is the mock recorder for MockOutbox.
+type MockOutboxMockRecorder struct {
+	mock *MockOutbox
+}
+
+// NewMockOutbox creates a new mock instance.
+func NewMockOutbox(ctrl *gomock.Controller) *MockOutbox {
+	mock := &MockOutbox{ctrl: ctrl}
+	mock.recorder = &MockOutboxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutbox) EXPECT() *MockOutboxMockRecorder {
+	return m.recorder
+}
+
+// DeadLetters mocks base method.
+func (m *MockOutbox) DeadLetters(ctx context.Context, inp dtos.PageInput) ([]domain.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadLetters", ctx, inp)
+	ret0, _ := ret[0].([]domain.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeadLetters indicates an expected call of DeadLetters.
+func (mr *MockOutboxMockRecorder) DeadLetters(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadLetters", reflect.TypeOf((*MockOutbox)(nil).DeadLetters), ctx, inp)
+}
+
+// Dispatch mocks base method.
+func (m *MockOutbox) Dispatch(ctx context.Context, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dispatch", ctx, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dispatch indicates an expected call of Dispatch.
+func (mr *MockOutboxMockRecorder) Dispatch(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dispatch", reflect.TypeOf((*MockOutbox)(nil).Dispatch), ctx, limit)
+}
+
+// Preview mocks base method.
+func (m *MockOutbox) Preview(eventType domain.EventType, locale string) (domain.NotificationPreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", eventType, locale)
+	ret0, _ := ret[0].(domain.NotificationPreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockOutboxMockRecorder) Preview(eventType, locale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockOutbox)(nil).Preview), eventType, locale)
+}
+
+// Redrive mocks base method.
+func (m *MockOutbox) Redrive(ctx context.Context, id int64) (domain.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redrive", ctx, id)
+	ret0, _ := ret[0].(domain.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redrive indicates an expected call of Redrive.
+func (mr *MockOutboxMockRecorder) Redrive(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redrive", reflect.TypeOf((*MockOutbox)(nil).Redrive), ctx, id)
+}
+
+// Run mocks base method.
+func (m *MockOutbox) Run(ctx context.Context, interval time.Duration, batchSize int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx, interval, batchSize)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockOutboxMockRecorder) Run(ctx, interval, batchSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockOutbox)(nil).Run), ctx, interval, batchSize)
+}
+
+// Templates mocks base method.
+func (m *MockOutbox) Templates() []domain.NotificationTemplate {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Templates")
+	ret0, _ := ret[0].([]domain.NotificationTemplate)
+	return ret0
+}
+
+// Templates indicates an expected call of Templates.
+func (mr *MockOutboxMockRecorder) Templates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Templates", reflect.TypeOf((*MockOutbox)(nil).Templates))
+}
+
+// MockDigests is a mock of Digests interface.
+type MockDigests struct {
+	ctrl     *gomock.Controller
+	recorder *MockDigestsMockRecorder
+}
+
+// MockDigestsMockRecorder is the mock recorder for MockDigests.
+type MockDigestsMockRecorder struct {
+	mock *MockDigests
+}
+
+// NewMockDigests creates a new mock instance.
+func NewMockDigests(ctrl *gomock.Controller) *MockDigests {
+	mock := &MockDigests{ctrl: ctrl}
+	mock.recorder = &MockDigestsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDigests) EXPECT() *MockDigestsMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MockDigests) Flush(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockDigestsMockRecorder) Flush(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockDigests)(nil).Flush), ctx)
+}
+
+// Run mocks base method.
+func (m *MockDigests) Run(ctx context.Context, interval time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx, interval)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockDigestsMockRecorder) Run(ctx, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockDigests)(nil).Run), ctx, interval)
+}
+
+// MockWebhooks is a mock of Webhooks interface.
+type MockWebhooks struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksMockRecorder
+}
+
+// MockWebhooksMockRecorder is the mock recorder for MockWebhooks.
+type MockWebhooksMockRecorder struct {
+	mock *MockWebhooks
+}
+
+// NewMockWebhooks creates a new mock instance.
+func NewMockWebhooks(ctrl *gomock.Controller) *MockWebhooks {
+	mock := &MockWebhooks{ctrl: ctrl}
+	mock.recorder = &MockWebhooksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhooks) EXPECT() *MockWebhooksMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhooks) Create(ctx context.Context, inp dtos.WebhookCreateInput) (domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, inp)
+	ret0, _ := ret[0].(domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhooksMockRecorder) Create(ctx, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhooks)(nil).Create), ctx, inp)
+}
+
+// Delete mocks base method.
+func (m *MockWebhooks) Delete(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhooksMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhooks)(nil).Delete), ctx, id)
+}
+
+// Deliver mocks base method.
+func (m *MockWebhooks) Deliver(ctx context.Context, msg domain.OutboxMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliver", ctx, msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deliver indicates an expected call of Deliver.
+func (mr *MockWebhooksMockRecorder) Deliver(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliver", reflect.TypeOf((*MockWebhooks)(nil).Deliver), ctx, msg)
+}
+
+// Deliveries mocks base method.
+func (m *MockWebhooks) Deliveries(ctx context.Context, id int64, inp dtos.PageInput) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", ctx, id, inp)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *MockWebhooksMockRecorder) Deliveries(ctx, id, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*MockWebhooks)(nil).Deliveries), ctx, id, inp)
+}
+
+// Enable mocks base method.
+func (m *MockWebhooks) Enable(ctx context.Context, id int64) (domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, id)
+	ret0, _ := ret[0].(domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockWebhooksMockRecorder) Enable(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockWebhooks)(nil).Enable), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockWebhooks) GetAll(ctx context.Context) ([]domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockWebhooksMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockWebhooks)(nil).GetAll), ctx)
+}
+
+// MockNotifications is a mock of Notifications interface.
+type MockNotifications struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationsMockRecorder
+}
+
+// MockNotificationsMockRecorder is the mock recorder for MockNotifications.
+type MockNotificationsMockRecorder struct {
+	mock *MockNotifications
+}
+
+// NewMockNotifications creates a new mock instance.
+func NewMockNotifications(ctrl *gomock.Controller) *MockNotifications {
+	mock := &MockNotifications{ctrl: ctrl}
+	mock.recorder = &MockNotificationsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifications) EXPECT() *MockNotificationsMockRecorder {
+	return m.recorder
+}
+
+// GetAll mocks base method.
+func (m *MockNotifications) GetAll(ctx context.Context, userId uuid.UUID, inp dtos.NotificationsInput) ([]domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, inp)
+	ret0, _ := ret[0].([]domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockNotificationsMockRecorder) GetAll(ctx, userId, inp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockNotifications)(nil).GetAll), ctx, userId, inp)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotifications) MarkAllRead(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationsMockRecorder) MarkAllRead(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotifications)(nil).MarkAllRead), ctx, userId)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotifications) MarkRead(ctx context.Context, userId uuid.UUID, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, userId, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationsMockRecorder) MarkRead(ctx, userId, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotifications)(nil).MarkRead), ctx, userId, id)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+// ModerationService hands flats awaiting moderation out to moderators. Moderator claims flat
+// for lease before deciding on it, so that moderators don't review the same flat.
+type ModerationService struct {
+	repo  repository.Moderation
+	lease time.Duration
+	log   *slog.Logger
+}
+
+func NewModerationService(repo repository.Moderation, lease time.Duration, log *slog.Logger) *ModerationService {
+	return &ModerationService{
+		repo:  repo,
+		lease: lease,
+		log:   log,
+	}
+}
+
+// Queue returns flats awaiting moderation available to moderator, oldest first.
+func (s *ModerationService) Queue(ctx context.Context, moderatorId uuid.UUID, inp dtos.ModerationQueueInput) ([]domain.QueuedFlat, error) {
+	const op = "service.Moderation.Queue"
+
+	resp, err := s.repo.GetQueue(ctx, moderatorId, inp.HouseId, inp.Limit, inp.Offset)
+	if err != nil {
+		s.log.Error("failed to get moderation queue: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Claim assigns flat to moderator for lease, claiming flat again extends the lease.
+func (s *ModerationService) Claim(ctx context.Context, flatId int, moderatorId uuid.UUID) (domain.FlatClaim, error) {
+	const op = "service.Moderation.Claim"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("flatId", flatId),
+		slog.String("moderatorId", moderatorId.String()),
+	)
+
+	log.Info("claiming flat")
+
+	resp, err := s.repo.Claim(ctx, flatId, moderatorId, s.lease)
+	if err != nil {
+		if errors.Is(err, repository.ErrFlatNotFound) {
+			return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotFound)
+		}
+
+		if errors.Is(err, repository.ErrFlatNotQueued) {
+			return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, domain.ErrFlatNotQueued)
+		}
+
+		if errors.Is(err, repository.ErrFlatClaimed) {
+			return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, domain.ErrFlatClaimed)
+		}
+
+		s.log.Error("failed to claim flat: " + err.Error())
+
+		return domain.FlatClaim{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Run releases expired claims every interval until ctx is done.
+func (s *ModerationService) Run(ctx context.Context, interval time.Duration) {
+	const op = "service.Moderation.Run"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("starting expired claims release")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ReleaseExpired(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("failed to release expired claims: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("expired claims release stopped")
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReleaseExpired returns flats whose claims expired to the queue and returns their number.
+func (s *ModerationService) ReleaseExpired(ctx context.Context) (int64, error) {
+	const op = "service.Moderation.ReleaseExpired"
+
+	n, err := s.repo.ReleaseExpiredClaims(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n > 0 {
+		s.log.Info("expired claims released", slog.String("op", op), slog.Int64("count", n))
+	}
+
+	return n, nil
+}
+
+// DeclineReasons returns catalogue of decline reasons, archived ones are included on request.
+func (s *ModerationService) DeclineReasons(ctx context.Context, withArchived bool) ([]domain.DeclineReason, error) {
+	const op = "service.Moderation.DeclineReasons"
+
+	resp, err := s.repo.GetDeclineReasons(ctx, withArchived)
+	if err != nil {
+		s.log.Error("failed to get decline reasons: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// SetDeclineReason adds decline reason to the catalogue or replaces existing one.
+// Flats already declined with the reason show its new title and checklist.
+func (s *ModerationService) SetDeclineReason(ctx context.Context, inp dtos.DeclineReasonInput) (domain.DeclineReason, error) {
+	const op = "service.Moderation.SetDeclineReason"
+
+	reason := domain.DeclineReason{
+		Code:      inp.Code,
+		Title:     inp.Title,
+		Checklist: append([]string{}, inp.Checklist...),
+	}
+
+	if err := s.repo.SetDeclineReason(ctx, reason); err != nil {
+		s.log.Error("failed to set decline reason: " + err.Error())
+
+		return domain.DeclineReason{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reason, nil
+}
+
+// ArchiveDeclineReason withdraws decline reason from use, flats declined with it keep it.
+func (s *ModerationService) ArchiveDeclineReason(ctx context.Context, code string) error {
+	const op = "service.Moderation.ArchiveDeclineReason"
+
+	if err := s.repo.ArchiveDeclineReason(ctx, code); err != nil {
+		if errors.Is(err, repository.ErrDeclineReasonNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrDeclineReasonNotFound)
+		}
+
+		s.log.Error("failed to archive decline reason: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/google/uuid"
+	"log/slog"
+)
+
+// NotificationsService manages user in-app inbox, filled by outbox dispatcher.
+type NotificationsService struct {
+	repo repository.Notifications
+	log  *slog.Logger
+}
+
+func NewNotificationsService(repo repository.Notifications, log *slog.Logger) *NotificationsService {
+	return &NotificationsService{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// GetAll returns user notifications, most recent first.
+func (s *NotificationsService) GetAll(ctx context.Context, userId uuid.UUID, inp dtos.NotificationsInput) ([]domain.Notification, error) {
+	const op = "service.Notifications.GetAll"
+
+	resp, err := s.repo.GetByUser(ctx, userId, inp.Unread, inp.Limit, inp.Offset)
+	if err != nil {
+		s.log.Error("failed to get notifications: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+func (s *NotificationsService) MarkRead(ctx context.Context, userId uuid.UUID, id int64) error {
+	const op = "service.Notifications.MarkRead"
+
+	if err := s.repo.MarkRead(ctx, userId, id); err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrNotificationNotFound)
+		}
+
+		s.log.Error("failed to mark notification read: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *NotificationsService) MarkAllRead(ctx context.Context, userId uuid.UUID) error {
+	const op = "service.Notifications.MarkAllRead"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("user_id", userId.String()),
+	)
+
+	n, err := s.repo.MarkAllRead(ctx, userId)
+	if err != nil {
+		s.log.Error("failed to mark notifications read: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("marked notifications read", slog.Int64("count", n))
+
+	return nil
+}
@@ -0,0 +1,524 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/backoff"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/sender"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/templates"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/unsubscribe"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/workerpool"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutboxService delivers notifications stored in outbox.
+// Message is marked sent only after successful delivery, so it is sent at least once
+// even if process stops in between, and several instances may dispatch concurrently.
+// Messages of a batch are delivered concurrently on workers of the pool, outside of any transaction.
+// Each dispatch makes a single delivery attempt, failed messages wait for the next one in outbox.
+type OutboxService struct {
+	repo        repository.Outbox
+	users       repository.Users
+	inbox       repository.Notifications
+	senders     Senders
+	templates   *templates.Renderer
+	webhooks    Webhooks
+	router      *Router
+	pool        *workerpool.Pool
+	unsubscribe *unsubscribe.Signer
+	baseURL     string
+	cfg         OutboxConfig
+	log         *slog.Logger
+}
+
+type OutboxConfig struct {
+	// Lease is how long claimed messages are hidden from other dispatchers.
+	// It must exceed delivery time of a batch, otherwise messages may be sent twice.
+	Lease time.Duration
+	// Retry is retry policy of messages to users, Webhooks is the one of messages to webhooks.
+	Retry    RetryPolicy
+	Webhooks RetryPolicy
+}
+
+// retryPolicy returns retry policy of messages to channel.
+func (c OutboxConfig) retryPolicy(channel domain.Channel) RetryPolicy {
+	if channel == domain.ChannelWebhook {
+		return c.Webhooks
+	}
+
+	return c.Retry
+}
+
+// RetryPolicy limits delivery attempts of message and spaces them with jittered exponential back-off.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// retryAt returns when message failed in attempt is tried again, ok is false if attempts are exhausted.
+func (p RetryPolicy) retryAt(attempt int, now time.Time) (time.Time, bool) {
+	if attempt >= p.MaxAttempts {
+		return time.Time{}, false
+	}
+
+	return now.Add(backoff.FullJitter(attempt, p.BaseDelay, p.MaxDelay)), true
+}
+
+// Senders deliver messages of external user channels, in-app ones are stored in user inbox.
+type Senders struct {
+	Email sender.Sender
+	SMS   sender.SMSSender
+	Push  sender.PushSender
+}
+
+func NewOutboxService(repo repository.Outbox, users repository.Users, inbox repository.Notifications, senders Senders,
+	templates *templates.Renderer, webhooks Webhooks, pool *workerpool.Pool, unsubscribe *unsubscribe.Signer, baseURL string,
+	cfg OutboxConfig, log *slog.Logger) *OutboxService {
+	return &OutboxService{
+		repo:        repo,
+		users:       users,
+		inbox:       inbox,
+		senders:     senders,
+		templates:   templates,
+		webhooks:    webhooks,
+		router:      NewRouter(DefaultChannels),
+		pool:        pool,
+		unsubscribe: unsubscribe,
+		baseURL:     baseURL,
+		cfg:         cfg,
+		log:         log,
+	}
+}
+
+// Run dispatches outbox every interval until ctx is done.
+// Full batches are dispatched one after another without waiting, so backlog is drained quickly.
+func (s *OutboxService) Run(ctx context.Context, interval time.Duration, batchSize int) {
+	const op = "service.Outbox.Run"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("starting outbox dispatcher")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.Dispatch(ctx, batchSize)
+		if err != nil && ctx.Err() == nil {
+			s.log.Error("failed to dispatch outbox: " + err.Error())
+		}
+
+		if err == nil && n == batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("outbox dispatcher stopped")
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends up to limit pending messages and returns number of delivered ones.
+// Delivered messages are marked processed, failed ones are retried after back-off
+// until attempts are exhausted, and then, as well as undeliverable ones, moved to dead letters.
+func (s *OutboxService) Dispatch(ctx context.Context, limit int) (int, error) {
+	const op = "service.Outbox.Dispatch"
+
+	msgs, err := s.repo.Claim(ctx, limit, s.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	errs := s.deliverAll(ctx, msgs)
+
+	// Results are recorded even if dispatch is stopped, unrecorded ones are retried once lease expires.
+	var (
+		delivered int
+		settleErr error
+	)
+	for i, msg := range msgs {
+		if errs[i] == nil {
+			delivered++
+		}
+
+		if err := s.settle(context.WithoutCancel(ctx), msg, errs[i]); err != nil {
+			settleErr = errors.Join(settleErr, err)
+		}
+	}
+	if settleErr != nil {
+		return delivered, fmt.Errorf("%s: %w", op, settleErr)
+	}
+
+	return delivered, nil
+}
+
+// settle records delivery result of message.
+func (s *OutboxService) settle(ctx context.Context, msg domain.OutboxMessage, sendErr error) error {
+	if sendErr == nil {
+		return s.repo.MarkProcessed(ctx, msg.ID)
+	}
+
+	if !errors.Is(sendErr, domain.ErrUndeliverable) {
+		if retryAt, ok := s.cfg.retryPolicy(msg.Channel).retryAt(msg.Attempts, time.Now()); ok {
+			return s.repo.MarkFailed(ctx, msg.ID, sendErr, retryAt)
+		}
+	}
+
+	return s.repo.MoveToDeadLetters(ctx, msg, sendErr)
+}
+
+// deliverAll delivers messages concurrently and returns error of each of them. Messages that
+// can't be put to the pool, e.g. because dispatch is stopped, fail and are retried later.
+func (s *OutboxService) deliverAll(ctx context.Context, msgs []domain.OutboxMessage) []error {
+	errs := make([]error, len(msgs))
+
+	var wg sync.WaitGroup
+	for i, msg := range msgs {
+		wg.Add(1)
+
+		err := s.pool.SubmitWait(ctx, func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("delivery panicked: %v", r)
+				}
+			}()
+
+			errs[i] = s.deliver(ctx, msg)
+		})
+		if err != nil {
+			wg.Done()
+			errs[i] = err
+		}
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// deliver sends message through its channel. Messages that can't be rendered are reported
+// undeliverable to be moved to dead letters right away.
+func (s *OutboxService) deliver(ctx context.Context, msg domain.OutboxMessage) error {
+	switch msg.Channel {
+	case domain.ChannelWebhook:
+		return s.webhooks.Deliver(ctx, msg)
+	case domain.ChannelUser:
+		return s.route(ctx, msg)
+	}
+
+	// Only emails are sent to subscriber address, so that unsubscribe link is signed for it.
+	var email string
+	if msg.Channel == domain.ChannelEmail {
+		email = msg.Recipient
+	}
+
+	rendered, unsubscribeLink, err := s.renderMessage(msg.EventType, string(msg.Language), msg.Payload, email)
+	if err != nil {
+		s.log.Error("failed to render message: " + err.Error())
+
+		return fmt.Errorf("%w: %w", domain.ErrUndeliverable, err)
+	}
+
+	if err = s.send(ctx, msg, rendered, unsubscribeLink); err != nil {
+		s.log.Error(fmt.Sprintf("failed to send %s message: %s", msg.Channel, err.Error()))
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *OutboxService) send(ctx context.Context, msg domain.OutboxMessage, rendered templates.Message, unsubscribeLink string) error {
+	switch msg.Channel {
+	case domain.ChannelEmail:
+		return s.senders.Email.SendEmail(ctx, sender.Email{
+			To:          msg.Recipient,
+			Subject:     rendered.Subject,
+			Text:        rendered.Text,
+			HTML:        rendered.HTML,
+			Unsubscribe: unsubscribeLink,
+		})
+	case domain.ChannelSMS:
+		return s.senders.SMS.SendSMS(ctx, sender.SMS{
+			To:   msg.Recipient,
+			Text: rendered.Text,
+		})
+	case domain.ChannelPush:
+		return s.senders.Push.SendPush(ctx, sender.Push{
+			Token: msg.Recipient,
+			Title: rendered.Subject,
+			Body:  rendered.Text,
+		})
+	case domain.ChannelInApp:
+		return s.store(ctx, msg, rendered)
+	}
+
+	return fmt.Errorf("%w: unknown channel '%s'", domain.ErrUndeliverable, msg.Channel)
+}
+
+// store puts in-app message to inbox of user it's addressed to. Messages to deleted users are dropped.
+func (s *OutboxService) store(ctx context.Context, msg domain.OutboxMessage, rendered templates.Message) error {
+	userId, err := uuid.Parse(msg.Recipient)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user id: %w", domain.ErrUndeliverable, err)
+	}
+
+	err = s.inbox.Create(ctx, domain.Notification{
+		UserID:    userId,
+		OutboxID:  msg.ID,
+		EventType: msg.EventType,
+		Title:     rendered.Subject,
+		Body:      rendered.Text,
+		Payload:   msg.Payload,
+	})
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// route replaces message to user with messages to channels chosen by the user.
+// Routed messages are dispatched separately, so failure of one channel doesn't repeat the others.
+func (s *OutboxService) route(ctx context.Context, msg domain.OutboxMessage) error {
+	const op = "service.Outbox.route"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("outbox_id", msg.ID),
+	)
+
+	user, err := s.users.GetByEmail(ctx, msg.Recipient)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			log.Info("recipient isn't registered, dropping message")
+
+			return nil
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	settings, err := s.users.GetNotificationSettings(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = s.repo.Enqueue(ctx, s.router.Route(msg, user, settings, time.Now())); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *OutboxService) DeadLetters(ctx context.Context, inp dtos.PageInput) ([]domain.DeadLetter, error) {
+	const op = "service.Outbox.DeadLetters"
+
+	resp, err := s.repo.GetDeadLetters(ctx, inp.Limit, inp.Offset)
+	if err != nil {
+		s.log.Error("failed to get dead letters: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Redrive puts dead letter back to outbox to be sent again.
+func (s *OutboxService) Redrive(ctx context.Context, id int64) (domain.OutboxMessage, error) {
+	const op = "service.Outbox.Redrive"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("dead_letter_id", id),
+	)
+
+	log.Info("re-driving dead letter")
+
+	resp, err := s.repo.RedriveDeadLetter(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeadLetterNotFound) {
+			return domain.OutboxMessage{}, fmt.Errorf("%s: %w", op, domain.ErrDeadLetterNotFound)
+		}
+
+		s.log.Error("failed to re-drive dead letter: " + err.Error())
+
+		return domain.OutboxMessage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Templates returns event types notifications are sent for with locales of their templates.
+func (s *OutboxService) Templates() []domain.NotificationTemplate {
+	resp := make([]domain.NotificationTemplate, 0, len(domain.EventTypes))
+	for _, eventType := range domain.EventTypes {
+		resp = append(resp, domain.NotificationTemplate{
+			EventType: eventType,
+			Locales:   s.templates.Locales(string(eventType)),
+		})
+	}
+
+	return resp
+}
+
+// previewPayloads holds sample payloads templates are previewed with.
+var previewPayloads = map[domain.EventType]any{
+	domain.EventFlatApproved: domain.FlatApprovedPayload{
+		HouseID:    1,
+		FlatID:     1,
+		FlatNumber: 42,
+		Rooms:      2,
+		Price:      10000000,
+	},
+	domain.EventFlatsDigest: domain.FlatsDigestPayload{
+		Delivery: domain.DeliveryDaily,
+		Flats: []domain.FlatApprovedPayload{
+			{HouseID: 1, FlatID: 1, FlatNumber: 42, Rooms: 2, Price: 10000000},
+			{HouseID: 2, FlatID: 7, FlatNumber: 15, Rooms: 1, Price: 6500000},
+		},
+	},
+}
+
+// previewEmail is sample recipient unsubscribe link of previewed templates is signed for.
+const previewEmail = "user@example.com"
+
+// Preview renders event template for locale, default one if empty, with sample data.
+// Unlike sending, it doesn't fall back to default locale, so every variant can be checked.
+func (s *OutboxService) Preview(eventType domain.EventType, locale string) (domain.NotificationPreview, error) {
+	const op = "service.Outbox.Preview"
+
+	if locale == "" {
+		locale = templates.DefaultLocale
+	}
+
+	sample, ok := previewPayloads[eventType]
+	if !ok || !slices.Contains(s.templates.Locales(string(eventType)), locale) {
+		return domain.NotificationPreview{}, fmt.Errorf("%s: %w", op, domain.ErrTemplateNotFound)
+	}
+
+	payload, err := json.Marshal(sample)
+	if err != nil {
+		return domain.NotificationPreview{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	msg, _, err := s.renderMessage(eventType, locale, payload, previewEmail)
+	if err != nil {
+		if errors.Is(err, templates.ErrNotFound) {
+			return domain.NotificationPreview{}, fmt.Errorf("%s: %w", op, domain.ErrTemplateNotFound)
+		}
+
+		s.log.Error("failed to render preview: " + err.Error())
+
+		return domain.NotificationPreview{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return domain.NotificationPreview{
+		EventType: eventType,
+		Locale:    locale,
+		Subject:   msg.Subject,
+		Text:      msg.Text,
+		HTML:      msg.HTML,
+	}, nil
+}
+
+// renderMessage renders event template in locale with payload fields and links available in it.
+// Message to email gets link unsubscribing it from houses message is about, returned to be sent in headers too.
+func (s *OutboxService) renderMessage(eventType domain.EventType, locale string, payload json.RawMessage, email string) (templates.Message, string, error) {
+	var data map[string]any
+	var houseIds []int
+
+	switch eventType {
+	case domain.EventFlatApproved:
+		var p domain.FlatApprovedPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return templates.Message{}, "", err
+		}
+
+		data = map[string]any{
+			"HouseID":    p.HouseID,
+			"FlatID":     p.FlatID,
+			"FlatNumber": p.FlatNumber,
+			"Rooms":      p.Rooms,
+			"Price":      p.Price,
+			"HouseLink":  s.houseLink(p.HouseID),
+		}
+		houseIds = []int{p.HouseID}
+	case domain.EventFlatsDigest:
+		var p domain.FlatsDigestPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return templates.Message{}, "", err
+		}
+
+		flats := make([]map[string]any, 0, len(p.Flats))
+		for _, flat := range p.Flats {
+			flats = append(flats, map[string]any{
+				"HouseID":    flat.HouseID,
+				"FlatID":     flat.FlatID,
+				"FlatNumber": flat.FlatNumber,
+				"Rooms":      flat.Rooms,
+				"Price":      flat.Price,
+				"HouseLink":  s.houseLink(flat.HouseID),
+			})
+
+			if !slices.Contains(houseIds, flat.HouseID) {
+				houseIds = append(houseIds, flat.HouseID)
+			}
+		}
+
+		data = map[string]any{
+			"Delivery": string(p.Delivery),
+			"Count":    len(p.Flats),
+			"Flats":    flats,
+		}
+	default:
+		return templates.Message{}, "", fmt.Errorf("unknown event type '%s'", eventType)
+	}
+
+	var unsubscribeLink string
+	if email != "" && len(houseIds) > 0 {
+		var err error
+		if unsubscribeLink, err = s.unsubscribeLink(email, houseIds); err != nil {
+			return templates.Message{}, "", err
+		}
+	}
+	data["UnsubscribeLink"] = unsubscribeLink
+
+	msg, err := s.templates.Render(string(eventType), locale, data)
+	if err != nil {
+		return templates.Message{}, "", err
+	}
+
+	return msg, unsubscribeLink, nil
+}
+
+func (s *OutboxService) houseLink(houseId int) string {
+	return fmt.Sprintf("%s/api/house/%d", strings.TrimRight(s.baseURL, "/"), houseId)
+}
+
+// unsubscribeLink returns signed link unsubscribing email from houses.
+func (s *OutboxService) unsubscribeLink(email string, houseIds []int) (string, error) {
+	token, err := s.unsubscribe.Sign(email, houseIds)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/api/unsubscribe?token=%s", strings.TrimRight(s.baseURL, "/"), url.QueryEscape(token)), nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"slices"
+	"time"
+)
+
+// DefaultChannels are channels of event types user didn't choose channels for.
+var DefaultChannels = map[domain.EventType][]domain.Channel{
+	domain.EventFlatApproved: {domain.ChannelEmail},
+	domain.EventFlatsDigest:  {domain.ChannelEmail},
+}
+
+// quietChannels are delayed until the end of recipient quiet hours.
+var quietChannels = map[domain.Channel]bool{
+	domain.ChannelSMS:  true,
+	domain.ChannelPush: true,
+}
+
+// Router picks channels message to user is delivered through.
+type Router struct {
+	defaults map[domain.EventType][]domain.Channel
+}
+
+func NewRouter(defaults map[domain.EventType][]domain.Channel) *Router {
+	return &Router{
+		defaults: defaults,
+	}
+}
+
+// Route returns message addressed to each channel user chose for its event type.
+// In-app channel is always added, so that user inbox keeps every notification.
+// Channels user has no contact for are skipped, SMS and push ones are delayed
+// until the end of user quiet hours if now is within them.
+func (r *Router) Route(msg domain.OutboxMessage, user domain.User, settings domain.NotificationSettings, now time.Time) []domain.OutboxMessage {
+	channels, ok := settings.Channels[msg.EventType]
+	if !ok {
+		channels = r.defaults[msg.EventType]
+	}
+
+	if !slices.Contains(channels, domain.ChannelInApp) {
+		channels = append(slices.Clip(channels), domain.ChannelInApp)
+	}
+
+	var quietUntil time.Time
+	if settings.QuietHours != nil {
+		loc, err := time.LoadLocation(settings.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+
+		quietUntil, _ = settings.QuietHours.Until(now, loc)
+	}
+
+	routed := make([]domain.OutboxMessage, 0, len(channels))
+	for _, channel := range channels {
+		var recipient string
+		switch channel {
+		case domain.ChannelEmail:
+			recipient = user.Email
+		case domain.ChannelSMS:
+			recipient = deref(settings.Phone)
+		case domain.ChannelPush:
+			recipient = deref(settings.PushToken)
+		case domain.ChannelInApp:
+			recipient = user.ID.String()
+		}
+
+		if recipient == "" {
+			continue
+		}
+
+		userId := user.ID
+		availableAt := now
+		if quietChannels[channel] && !quietUntil.IsZero() {
+			availableAt = quietUntil
+		}
+
+		routed = append(routed, domain.OutboxMessage{
+			EventType:   msg.EventType,
+			Channel:     channel,
+			Recipient:   recipient,
+			UserID:      &userId,
+			Payload:     msg.Payload,
+			AvailableAt: availableAt,
+		})
+	}
+
+	return routed
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
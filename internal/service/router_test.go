@@ -0,0 +1,60 @@
+package service
+
+import (
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_RouterDefaultChannels(t *testing.T) {
+	user := domain.User{
+		ID:    uuid.New(),
+		Email: "user@mail.com",
+	}
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		eventType    domain.EventType
+		settings     domain.NotificationSettings
+		wantChannels []domain.Channel
+	}{
+		{
+			name:         "approved flat by default",
+			eventType:    domain.EventFlatApproved,
+			wantChannels: []domain.Channel{domain.ChannelEmail, domain.ChannelInApp},
+		},
+		{
+			name:         "flats digest by default",
+			eventType:    domain.EventFlatsDigest,
+			wantChannels: []domain.Channel{domain.ChannelEmail, domain.ChannelInApp},
+		},
+		{
+			name:      "flats digest by user choice",
+			eventType: domain.EventFlatsDigest,
+			settings: domain.NotificationSettings{
+				Channels: map[domain.EventType][]domain.Channel{
+					domain.EventFlatsDigest: {},
+				},
+			},
+			wantChannels: []domain.Channel{domain.ChannelInApp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter(DefaultChannels)
+
+			routed := r.Route(domain.OutboxMessage{EventType: tt.eventType}, user, tt.settings, now)
+
+			channels := make([]domain.Channel, 0, len(routed))
+			for _, msg := range routed {
+				assert.Equal(t, tt.eventType, msg.EventType)
+				channels = append(channels, msg.Channel)
+			}
+			assert.Equal(t, tt.wantChannels, channels)
+		})
+	}
+}
@@ -6,54 +6,149 @@ import (
 	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
 	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
 	"github.com/dzhordano/avito-bootcamp2024/pkg/auth"
-	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/sender"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/templates"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/unsubscribe"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/webhooks"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/workerpool"
+	"github.com/google/uuid"
+	"io"
 	"log/slog"
-	"sync"
+	"time"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mocks.go -package=service
 
 type Houses interface {
 	GetById(ctx context.Context, id int) ([]domain.Flat, error)
+	LastModified(ctx context.Context, id int) (time.Time, error)
+	ExportFlats(ctx context.Context, id int, fn func(flat domain.Flat) error) error
+	ExportHouses(ctx context.Context, fn func(house domain.House) error) error
 	Create(ctx context.Context, house dtos.HouseCreateInput) (domain.House, error)
 
-	Subscribe(ctx context.Context, houseId int, email string) error
+	Subscribe(ctx context.Context, houseId int, inp dtos.HouseSubscribeInput) error
+	Unsubscribe(ctx context.Context, houseId int, userId uuid.UUID, email string) error
+	VerifyUnsubscribe(ctx context.Context, token string) (domain.Unsubscription, error)
+	UnsubscribeByToken(ctx context.Context, token string) (domain.Unsubscription, error)
 }
 
 type Flats interface {
-	Create(ctx context.Context, flat dtos.FlatCreateInput) (domain.Flat, error)
+	Create(ctx context.Context, userId uuid.UUID, flat dtos.FlatCreateInput) (domain.Flat, error)
+	GetById(ctx context.Context, flatId int, userId uuid.UUID, userType domain.UserType) (domain.FlatDetails, error)
+	UserFlats(ctx context.Context, userId uuid.UUID) ([]domain.FlatDetails, error)
 
-	Update(ctx context.Context, flatId int, status domain.Status) (domain.Flat, error)
+	Update(ctx context.Context, moderatorId uuid.UUID, inp dtos.FlatUpdateInput) (domain.Flat, error)
+	Transitions(ctx context.Context, flatId int) (domain.FlatTransitions, error)
+	History(ctx context.Context, flatId int) ([]domain.FlatStatusChange, error)
+	Edit(ctx context.Context, flatId int, userId uuid.UUID, userType domain.UserType, inp dtos.FlatEditInput) (domain.Flat, error)
+}
+
+type Moderation interface {
+	Queue(ctx context.Context, moderatorId uuid.UUID, inp dtos.ModerationQueueInput) ([]domain.QueuedFlat, error)
+	Claim(ctx context.Context, flatId int, moderatorId uuid.UUID) (domain.FlatClaim, error)
+	Run(ctx context.Context, interval time.Duration)
+	ReleaseExpired(ctx context.Context) (int64, error)
+
+	DeclineReasons(ctx context.Context, withArchived bool) ([]domain.DeclineReason, error)
+	SetDeclineReason(ctx context.Context, inp dtos.DeclineReasonInput) (domain.DeclineReason, error)
+	ArchiveDeclineReason(ctx context.Context, code string) error
 }
 
 type Users interface {
 	DummyLogin(userType string) (string, error)
 	Register(ctx context.Context, user dtos.UserRegisterInput) (string, error)
 	Login(ctx context.Context, user dtos.UserLoginInput) (string, error)
+	Subscriptions(ctx context.Context, userId uuid.UUID) ([]domain.Subscription, error)
+	SetSubscriptionDelivery(ctx context.Context, userId uuid.UUID, houseId int, delivery domain.Delivery) error
+	SetLanguage(ctx context.Context, userId uuid.UUID, language domain.Language) error
+	NotificationSettings(ctx context.Context, userId uuid.UUID) (domain.NotificationSettings, error)
+	SetNotificationSettings(ctx context.Context, userId uuid.UUID, inp dtos.NotificationSettingsInput) error
+	Export(ctx context.Context, userId uuid.UUID) (domain.AccountExport, error)
+}
+
+type Imports interface {
+	Start(ctx context.Context, inp dtos.ImportInput, file io.Reader) (domain.ImportJob, error)
+	Run(ctx context.Context, inp dtos.ImportInput, file io.Reader) (domain.ImportJob, error)
+	GetJob(id uuid.UUID) (domain.ImportJob, error)
+}
+
+type Outbox interface {
+	Run(ctx context.Context, interval time.Duration, batchSize int)
+	Dispatch(ctx context.Context, limit int) (int, error)
+	DeadLetters(ctx context.Context, inp dtos.PageInput) ([]domain.DeadLetter, error)
+	Redrive(ctx context.Context, id int64) (domain.OutboxMessage, error)
+	Templates() []domain.NotificationTemplate
+	Preview(eventType domain.EventType, locale string) (domain.NotificationPreview, error)
+}
+
+type Digests interface {
+	Run(ctx context.Context, interval time.Duration)
+	Flush(ctx context.Context) (int64, error)
+}
+
+type Webhooks interface {
+	Create(ctx context.Context, inp dtos.WebhookCreateInput) (domain.Webhook, error)
+	GetAll(ctx context.Context) ([]domain.Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	Enable(ctx context.Context, id int64) (domain.Webhook, error)
+	Deliveries(ctx context.Context, id int64, inp dtos.PageInput) ([]domain.WebhookDelivery, error)
+	Deliver(ctx context.Context, msg domain.OutboxMessage) error
+}
+
+type Notifications interface {
+	GetAll(ctx context.Context, userId uuid.UUID, inp dtos.NotificationsInput) ([]domain.Notification, error)
+	MarkRead(ctx context.Context, userId uuid.UUID, id int64) error
+	MarkAllRead(ctx context.Context, userId uuid.UUID) error
 }
 
 type Services struct {
-	Houses Houses
-	Flats  Flats
-	Users  Users
+	Houses        Houses
+	Flats         Flats
+	Users         Users
+	Imports       Imports
+	Outbox        Outbox
+	Digests       Digests
+	Webhooks      Webhooks
+	Notifications Notifications
+	Moderation    Moderation
 }
 
 type Deps struct {
-	Repos         *repository.Repository
-	TokensManager auth.TokensManager
-	Notifications sender.Sender
-	WaitGroup     *sync.WaitGroup
-	Logger        *slog.Logger
+	Repos               *repository.Repository
+	TokensManager       auth.TokensManager
+	Notifications       Senders
+	Templates           *templates.Renderer
+	Unsubscribe         *unsubscribe.Signer
+	BaseURL             string
+	Outbox              OutboxConfig
+	Webhooks            *webhooks.Client
+	WebhookDisableAfter int
+	NotificationsPool   *workerpool.Pool
+	ImportsPool         *workerpool.Pool
+	ImportJobTTL        time.Duration
+	ModerationLease     time.Duration
+	Logger              *slog.Logger
 }
 
 func New(deps Deps) *Services {
-	users := NewUsersService(deps.Repos.Users, deps.TokensManager, deps.Logger)
-	flats := NewFlatsService(deps.Repos.Flats, deps.Repos.Houses, deps.Notifications, deps.WaitGroup, deps.Logger)
-	houses := NewHousesService(deps.Repos.Houses, deps.Logger)
+	users := NewUsersService(deps.Repos.Users, deps.Repos.Houses, deps.Repos.Flats, deps.TokensManager, deps.Logger)
+	flats := NewFlatsService(deps.Repos.Flats, deps.Repos.Moderation, deps.Logger)
+	houses := NewHousesService(deps.Repos.Houses, deps.Repos.Users, deps.Unsubscribe, deps.Logger)
+	imports := NewImportsService(deps.Repos.Flats, deps.Repos.Houses, deps.ImportsPool, deps.ImportJobTTL, deps.Logger)
+	hooks := NewWebhooksService(deps.Repos.Webhooks, deps.Webhooks, deps.WebhookDisableAfter, deps.Logger)
+	notifications := NewNotificationsService(deps.Repos.Notifications, deps.Logger)
+	digests := NewDigestsService(deps.Repos.Outbox, deps.Logger)
+	moderation := NewModerationService(deps.Repos.Moderation, deps.ModerationLease, deps.Logger)
+	outbox := NewOutboxService(deps.Repos.Outbox, deps.Repos.Users, deps.Repos.Notifications, deps.Notifications, deps.Templates, hooks, deps.NotificationsPool, deps.Unsubscribe, deps.BaseURL, deps.Outbox, deps.Logger)
 
 	return &Services{
-		Users:  users,
-		Flats:  flats,
-		Houses: houses,
+		Users:         users,
+		Flats:         flats,
+		Houses:        houses,
+		Imports:       imports,
+		Outbox:        outbox,
+		Digests:       digests,
+		Webhooks:      hooks,
+		Notifications: notifications,
+		Moderation:    moderation,
 	}
 }
@@ -15,13 +15,17 @@ import (
 
 type UsersService struct {
 	repo          repository.Users
+	housesRepo    repository.Houses
+	flatsRepo     repository.Flats
 	tokensManager auth.TokensManager
 	log           *slog.Logger
 }
 
-func NewUsersService(repo repository.Users, tokenManager auth.TokensManager, log *slog.Logger) *UsersService {
+func NewUsersService(repo repository.Users, housesRepo repository.Houses, flatsRepo repository.Flats, tokenManager auth.TokensManager, log *slog.Logger) *UsersService {
 	return &UsersService{
 		repo:          repo,
+		housesRepo:    housesRepo,
+		flatsRepo:     flatsRepo,
 		tokensManager: tokenManager,
 		log:           log,
 	}
@@ -37,7 +41,8 @@ func (s *UsersService) DummyLogin(userType string) (string, error) {
 
 	log.Info("generating auth token")
 
-	token, err := s.tokensManager.GenerateJWT(userType)
+	// Dummy users are not stored, so each of them gets a fresh identity.
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), userType)
 	if err != nil {
 		s.log.Error("failed to generate token: " + err.Error())
 
@@ -65,11 +70,16 @@ func (s *UsersService) Register(ctx context.Context, user dtos.UserRegisterInput
 	passwordHash := sha1.Sum([]byte(user.Password))
 	user.Password = fmt.Sprintf("%x", passwordHash)
 
+	if user.Language == "" {
+		user.Language = domain.DefaultLanguage
+	}
+
 	inpUser := domain.User{
 		ID:       userId,
 		Email:    user.Email,
 		Password: user.Password,
 		UserType: user.UserType,
+		Language: user.Language,
 	}
 
 	log.Info("registering user")
@@ -116,7 +126,7 @@ func (s *UsersService) Login(ctx context.Context, user dtos.UserLoginInput) (str
 
 	log.Info("generating auth token")
 
-	token, err := s.tokensManager.GenerateJWT(string(respUser.UserType))
+	token, err := s.tokensManager.GenerateJWT(respUser.ID.String(), string(respUser.UserType))
 	if err != nil {
 		s.log.Error("failed to generate token: " + err.Error())
 
@@ -125,3 +135,201 @@ func (s *UsersService) Login(ctx context.Context, user dtos.UserLoginInput) (str
 
 	return token, nil
 }
+
+// Subscriptions returns house subscriptions of the user.
+func (s *UsersService) Subscriptions(ctx context.Context, userId uuid.UUID) ([]domain.Subscription, error) {
+	const op = "service.Users.Subscriptions"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("user_id", userId.String()),
+	)
+
+	log.Info("getting user subscriptions")
+
+	user, err := s.repo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+		}
+
+		s.log.Error("failed to get user: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := s.housesRepo.GetUserSubscriptions(ctx, user.Email)
+	if err != nil {
+		s.log.Error("failed to get subscriptions: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// SetSubscriptionDelivery sets how user is notified about new flats of the house.
+func (s *UsersService) SetSubscriptionDelivery(ctx context.Context, userId uuid.UUID, houseId int, delivery domain.Delivery) error {
+	const op = "service.Users.SetSubscriptionDelivery"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("user_id", userId.String()),
+		slog.Int("house_id", houseId),
+		slog.String("delivery", string(delivery)),
+	)
+
+	log.Info("setting subscription delivery")
+
+	user, err := s.repo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+		}
+
+		s.log.Error("failed to get user: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = s.housesRepo.UpdateSubscriptionDelivery(ctx, houseId, user.Email, delivery); err != nil {
+		if errors.Is(err, repository.ErrSubscriptionNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrSubscriptionNotFound)
+		}
+
+		s.log.Error("failed to update subscription delivery: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetLanguage sets language the user receives notifications in.
+func (s *UsersService) SetLanguage(ctx context.Context, userId uuid.UUID, language domain.Language) error {
+	const op = "service.Users.SetLanguage"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("user_id", userId.String()),
+		slog.String("language", string(language)),
+	)
+
+	log.Info("setting user language")
+
+	if err := s.repo.UpdateLanguage(ctx, userId, language); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+		}
+
+		s.log.Error("failed to update user language: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *UsersService) NotificationSettings(ctx context.Context, userId uuid.UUID) (domain.NotificationSettings, error) {
+	const op = "service.Users.NotificationSettings"
+
+	if _, err := s.repo.GetById(ctx, userId); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+		}
+
+		s.log.Error("failed to get user: " + err.Error())
+
+		return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := s.repo.GetNotificationSettings(ctx, userId)
+	if err != nil {
+		s.log.Error("failed to get notification settings: " + err.Error())
+
+		return domain.NotificationSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// SetNotificationSettings replaces contacts, quiet hours and channels the user is notified through.
+func (s *UsersService) SetNotificationSettings(ctx context.Context, userId uuid.UUID, inp dtos.NotificationSettingsInput) error {
+	const op = "service.Users.SetNotificationSettings"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("user_id", userId.String()),
+	)
+
+	log.Info("setting notification settings")
+
+	if err := s.repo.UpdateNotificationSettings(ctx, userId, inp.Settings()); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+		}
+
+		s.log.Error("failed to update notification settings: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Export returns all data stored about user: account, notification settings, subscriptions,
+// flats user created and status history of them along with status changes user made.
+func (s *UsersService) Export(ctx context.Context, userId uuid.UUID) (domain.AccountExport, error) {
+	const op = "service.Users.Export"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("user_id", userId.String()),
+	)
+
+	log.Info("exporting user data")
+
+	user, err := s.repo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return domain.AccountExport{}, fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+		}
+
+		s.log.Error("failed to get user: " + err.Error())
+
+		return domain.AccountExport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp := domain.AccountExport{
+		ID:       user.ID,
+		Email:    user.Email,
+		UserType: user.UserType,
+		Language: user.Language,
+	}
+
+	if resp.NotificationSettings, err = s.repo.GetNotificationSettings(ctx, userId); err != nil {
+		s.log.Error("failed to get notification settings: " + err.Error())
+
+		return domain.AccountExport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if resp.Subscriptions, err = s.housesRepo.GetUserSubscriptions(ctx, user.Email); err != nil {
+		s.log.Error("failed to get subscriptions: " + err.Error())
+
+		return domain.AccountExport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if resp.Flats, err = s.flatsRepo.GetByCreator(ctx, userId); err != nil {
+		s.log.Error("failed to get user flats: " + err.Error())
+
+		return domain.AccountExport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if resp.StatusHistory, err = s.flatsRepo.GetUserHistory(ctx, userId); err != nil {
+		s.log.Error("failed to get flat status history: " + err.Error())
+
+		return domain.AccountExport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
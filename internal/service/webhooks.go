@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/webhooks"
+	"log/slog"
+	"strconv"
+)
+
+// WebhooksService manages partner webhooks and posts outbox events to them.
+type WebhooksService struct {
+	repo         repository.Webhooks
+	client       *webhooks.Client
+	disableAfter int
+	log          *slog.Logger
+}
+
+func NewWebhooksService(repo repository.Webhooks, client *webhooks.Client, disableAfter int, log *slog.Logger) *WebhooksService {
+	return &WebhooksService{
+		repo:         repo,
+		client:       client,
+		disableAfter: disableAfter,
+		log:          log,
+	}
+}
+
+// Create registers webhook, generating its secret if input has none.
+// Webhook URL host must resolve to public addresses only, so that internal services aren't reached.
+// Returned webhook is the only place secret is shown.
+func (s *WebhooksService) Create(ctx context.Context, inp dtos.WebhookCreateInput) (domain.Webhook, error) {
+	const op = "service.Webhooks.Create"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("url", inp.URL),
+	)
+
+	log.Info("creating webhook")
+
+	if err := s.client.CheckURL(ctx, inp.URL); err != nil {
+		log.Info("webhook url rejected: " + err.Error())
+
+		return domain.Webhook{}, fmt.Errorf("%s: %w: %w", op, domain.ErrWebhookURLForbidden, err)
+	}
+
+	if inp.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		inp.Secret = hex.EncodeToString(secret)
+	}
+
+	resp, err := s.repo.Create(ctx, domain.Webhook{
+		URL:        inp.URL,
+		EventTypes: inp.EventTypes,
+		Secret:     inp.Secret,
+	})
+	if err != nil {
+		s.log.Error("failed to create webhook: " + err.Error())
+
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+func (s *WebhooksService) GetAll(ctx context.Context) ([]domain.Webhook, error) {
+	const op = "service.Webhooks.GetAll"
+
+	resp, err := s.repo.GetAll(ctx)
+	if err != nil {
+		s.log.Error("failed to get webhooks: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+func (s *WebhooksService) Delete(ctx context.Context, id int64) error {
+	const op = "service.Webhooks.Delete"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("webhook_id", id),
+	)
+
+	log.Info("deleting webhook")
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return fmt.Errorf("%s: %w", op, domain.ErrWebhookNotFound)
+		}
+
+		s.log.Error("failed to delete webhook: " + err.Error())
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Enable re-enables webhook disabled after failed deliveries.
+func (s *WebhooksService) Enable(ctx context.Context, id int64) (domain.Webhook, error) {
+	const op = "service.Webhooks.Enable"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("webhook_id", id),
+	)
+
+	log.Info("enabling webhook")
+
+	resp, err := s.repo.Enable(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return domain.Webhook{}, fmt.Errorf("%s: %w", op, domain.ErrWebhookNotFound)
+		}
+
+		s.log.Error("failed to enable webhook: " + err.Error())
+
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Deliveries returns delivery logs of webhook, most recent first.
+func (s *WebhooksService) Deliveries(ctx context.Context, id int64, inp dtos.PageInput) ([]domain.WebhookDelivery, error) {
+	const op = "service.Webhooks.Deliveries"
+
+	if _, err := s.repo.GetById(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, domain.ErrWebhookNotFound)
+		}
+
+		s.log.Error("failed to get webhook: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp, err := s.repo.GetDeliveries(ctx, id, inp.Limit, inp.Offset)
+	if err != nil {
+		s.log.Error("failed to get webhook deliveries: " + err.Error())
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp, nil
+}
+
+// Deliver makes an attempt to post outbox message to webhook it's addressed to and logs it.
+// Messages to deleted or disabled webhooks are dropped. Failed messages are retried by outbox,
+// except for ones rejected by webhook, which are reported undeliverable to be moved to dead letters.
+func (s *WebhooksService) Deliver(ctx context.Context, msg domain.OutboxMessage) error {
+	const op = "service.Webhooks.Deliver"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("outbox_id", msg.ID),
+		slog.String("webhook_id", msg.Recipient),
+	)
+
+	id, err := strconv.ParseInt(msg.Recipient, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook id: %w", domain.ErrUndeliverable, err)
+	}
+
+	webhook, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			log.Info("webhook deleted, dropping message")
+
+			return nil
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !webhook.Active {
+		log.Info("webhook disabled, dropping message")
+
+		return nil
+	}
+
+	body, err := json.Marshal(domain.WebhookEvent{
+		ID:        msg.ID,
+		Event:     msg.EventType,
+		CreatedAt: msg.CreatedAt,
+		Data:      msg.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrUndeliverable, err)
+	}
+
+	res, sendErr := s.client.Deliver(ctx, webhooks.Request{
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		Event:      string(msg.EventType),
+		DeliveryID: strconv.FormatInt(msg.ID, 10),
+		Body:       body,
+	})
+	// Interrupted delivery is retried after restart, so it's neither logged nor counted as failure.
+	if sendErr != nil && ctx.Err() != nil {
+		return sendErr
+	}
+
+	delivery := domain.WebhookDelivery{
+		WebhookID:  webhook.ID,
+		OutboxID:   msg.ID,
+		EventType:  msg.EventType,
+		Success:    sendErr == nil,
+		Attempts:   msg.Attempts,
+		DurationMs: int(res.Duration.Milliseconds()),
+	}
+	if res.StatusCode != 0 {
+		delivery.StatusCode = &res.StatusCode
+	}
+	if sendErr != nil {
+		errMsg := sendErr.Error()
+		delivery.Error = &errMsg
+	}
+
+	disabled, err := s.repo.RecordDelivery(ctx, delivery, s.disableAfter)
+	if err != nil && !errors.Is(err, repository.ErrWebhookNotFound) {
+		s.log.Error("failed to record webhook delivery: " + err.Error())
+	}
+
+	if sendErr != nil {
+		s.log.Error("failed to deliver webhook: " + sendErr.Error())
+
+		if disabled {
+			log.Warn("webhook disabled after failed deliveries")
+		}
+
+		if errors.Is(sendErr, webhooks.ErrRejected) {
+			return fmt.Errorf("%w: %w", domain.ErrUndeliverable, sendErr)
+		}
+
+		return sendErr
+	}
+
+	return nil
+}
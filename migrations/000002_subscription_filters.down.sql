@@ -0,0 +1,6 @@
+ALTER TABLE house_subscriptions
+    DROP COLUMN min_rooms,
+    DROP COLUMN max_rooms,
+    DROP COLUMN min_price,
+    DROP COLUMN max_price,
+    DROP COLUMN created_at;
@@ -0,0 +1,6 @@
+ALTER TABLE house_subscriptions
+    ADD COLUMN min_rooms INTEGER,
+    ADD COLUMN max_rooms INTEGER,
+    ADD COLUMN min_price INTEGER,
+    ADD COLUMN max_price INTEGER,
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT now();
@@ -0,0 +1,12 @@
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    processed_at TIMESTAMP
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (id) WHERE processed_at IS NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    outbox_id BIGINT NOT NULL,
+    event_type TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    failed_at TIMESTAMP NOT NULL DEFAULT now()
+);
@@ -0,0 +1,5 @@
+ALTER TABLE dead_letters DROP COLUMN channel;
+ALTER TABLE outbox DROP COLUMN channel;
+
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
@@ -0,0 +1,6 @@
+ALTER TABLE dead_letters DROP COLUMN user_id;
+ALTER TABLE outbox DROP COLUMN available_at;
+ALTER TABLE outbox DROP COLUMN user_id;
+
+DROP TABLE notification_channels;
+DROP TABLE notification_settings;
@@ -0,0 +1,23 @@
+CREATE TABLE notification_settings (
+    user_id UUID PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    phone TEXT,
+    push_token TEXT,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    quiet_hours_start TEXT,
+    quiet_hours_end TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE notification_channels (
+    user_id UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    event_type TEXT NOT NULL,
+    channels TEXT[] NOT NULL,
+    PRIMARY KEY (user_id, event_type)
+);
+
+ALTER TABLE outbox ADD COLUMN user_id UUID;
+ALTER TABLE outbox ADD COLUMN available_at TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE dead_letters ADD COLUMN user_id UUID;
+
+UPDATE outbox o SET user_id = u.user_id FROM users u WHERE o.channel = 'email' AND u.email = o.recipient;
+UPDATE dead_letters d SET user_id = u.user_id FROM users u WHERE d.channel = 'email' AND u.email = d.recipient;
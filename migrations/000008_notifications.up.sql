@@ -0,0 +1,14 @@
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    outbox_id BIGINT UNIQUE NOT NULL,
+    event_type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, id DESC);
+CREATE INDEX notifications_unread_idx ON notifications (user_id, id DESC) WHERE read_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS digest_events;
+
+ALTER TABLE house_subscriptions DROP COLUMN delivery;
@@ -0,0 +1,13 @@
+ALTER TABLE house_subscriptions ADD COLUMN delivery TEXT NOT NULL DEFAULT 'immediate';
+
+CREATE TABLE digest_events (
+    user_email TEXT REFERENCES users (email) ON DELETE CASCADE NOT NULL,
+    delivery TEXT NOT NULL,
+    flat_id INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    due_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_email, delivery, flat_id)
+);
+
+CREATE INDEX digest_events_due_at_idx ON digest_events (due_at);
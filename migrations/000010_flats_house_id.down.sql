@@ -0,0 +1,14 @@
+-- Fails if different houses have flats with the same number.
+ALTER TABLE flats DROP CONSTRAINT flats_house_id_flat_number_key;
+ALTER TABLE flats ADD CONSTRAINT flats_flat_number_key UNIQUE (flat_number);
+
+CREATE TABLE house_flats(
+    house_id INTEGER REFERENCES houses (id) ON DELETE CASCADE NOT NULL,
+    flat_id INTEGER REFERENCES flats (id) ON DELETE CASCADE NOT NULL,
+    flat_number INTEGER REFERENCES flats (flat_number) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (house_id, flat_number)
+);
+
+INSERT INTO house_flats (house_id, flat_id, flat_number) SELECT house_id, id, flat_number FROM flats;
+
+ALTER TABLE flats DROP COLUMN house_id;
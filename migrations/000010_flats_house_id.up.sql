@@ -0,0 +1,14 @@
+ALTER TABLE flats ADD COLUMN house_id INTEGER REFERENCES houses (id) ON DELETE CASCADE;
+
+UPDATE flats f SET house_id = hf.house_id FROM house_flats hf WHERE hf.flat_id = f.id;
+
+-- Flats are added to house in the same transaction they are created in, so there should be none without house.
+DELETE FROM flats WHERE house_id IS NULL;
+
+ALTER TABLE flats ALTER COLUMN house_id SET NOT NULL;
+
+DROP TABLE house_flats;
+
+-- Flat numbers are unique within house only, the key also serves lookups of house flats.
+ALTER TABLE flats DROP CONSTRAINT flats_flat_number_key;
+ALTER TABLE flats ADD CONSTRAINT flats_house_id_flat_number_key UNIQUE (house_id, flat_number);
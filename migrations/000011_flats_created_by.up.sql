@@ -0,0 +1,3 @@
+-- Users logged in with dummy tokens aren't stored, so creator isn't a foreign key.
+-- Flats created before and imported ones have no creator.
+ALTER TABLE flats ADD COLUMN created_by UUID;
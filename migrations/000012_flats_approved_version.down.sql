@@ -0,0 +1,4 @@
+ALTER TABLE flats
+    DROP COLUMN approved_flat_number,
+    DROP COLUMN approved_price,
+    DROP COLUMN approved_rooms;
@@ -0,0 +1,8 @@
+-- Approved version of flat stays visible to clients while its later edit awaits moderation.
+ALTER TABLE flats
+    ADD COLUMN approved_flat_number INTEGER,
+    ADD COLUMN approved_price INTEGER,
+    ADD COLUMN approved_rooms INTEGER;
+
+UPDATE flats SET approved_flat_number = flat_number, approved_price = price, approved_rooms = rooms
+WHERE status = 'approved';
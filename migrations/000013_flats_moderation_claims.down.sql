@@ -0,0 +1,7 @@
+DROP INDEX flats_claim_expires_at_idx;
+DROP INDEX flats_moderation_queue_idx;
+
+ALTER TABLE flats
+    DROP COLUMN created_at,
+    DROP COLUMN claimed_by,
+    DROP COLUMN claim_expires_at;
@@ -0,0 +1,8 @@
+-- Flats created before are queued in order of their ids.
+ALTER TABLE flats
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT now(),
+    ADD COLUMN claimed_by UUID,
+    ADD COLUMN claim_expires_at TIMESTAMP;
+
+CREATE INDEX flats_moderation_queue_idx ON flats (created_at, id) WHERE status = 'created';
+CREATE INDEX flats_claim_expires_at_idx ON flats (claim_expires_at) WHERE claim_expires_at IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE flats
+    DROP COLUMN decline_reason,
+    DROP COLUMN decline_comment;
+
+DROP TABLE decline_reasons;
@@ -0,0 +1,18 @@
+-- Archived reasons are kept for flats declined with them, but aren't used for new declines.
+CREATE TABLE decline_reasons (
+    code TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    checklist TEXT[] NOT NULL DEFAULT '{}',
+    archived_at TIMESTAMP
+);
+
+INSERT INTO decline_reasons (code, title, checklist) VALUES
+    ('wrong_price', 'Price is wrong', '{"Price is the full price of the flat", "Price is in rubles"}'),
+    ('wrong_rooms', 'Number of rooms is wrong', '{"Rooms count excludes kitchen and bathrooms"}'),
+    ('wrong_flat_number', 'Flat number is wrong', '{"Flat number matches the number on the door"}'),
+    ('duplicate', 'Flat is already listed', '{"Flat isn''t listed in the house already"}'),
+    ('other', 'Other', '{}');
+
+ALTER TABLE flats
+    ADD COLUMN decline_reason TEXT REFERENCES decline_reasons (code),
+    ADD COLUMN decline_comment TEXT;
@@ -0,0 +1,3 @@
+DROP TABLE flat_status_history;
+
+DROP FUNCTION flat_status_history_append_only;
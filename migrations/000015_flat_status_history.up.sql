@@ -0,0 +1,33 @@
+-- History is append-only: rows are written along with status changes and never updated,
+-- they are deleted only along with their flat, e.g. when its house is deleted.
+-- Empty old_status means flat was created, empty actor means change made by the service itself.
+CREATE TABLE flat_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    flat_id INTEGER NOT NULL REFERENCES flats (id) ON DELETE CASCADE,
+    old_status TEXT,
+    new_status TEXT NOT NULL,
+    actor UUID,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX flat_status_history_flat_id_idx ON flat_status_history (flat_id, id);
+
+CREATE FUNCTION flat_status_history_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    -- Cascaded delete runs after the flat is gone.
+    IF TG_OP = 'DELETE' AND NOT EXISTS (SELECT 1 FROM flats WHERE id = OLD.flat_id) THEN
+        RETURN OLD;
+    END IF;
+
+    RAISE EXCEPTION 'flat_status_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER flat_status_history_append_only
+    BEFORE UPDATE OR DELETE ON flat_status_history
+    FOR EACH ROW EXECUTE FUNCTION flat_status_history_append_only();
+
+-- Flats created before have their current status recorded as the one they were created with.
+INSERT INTO flat_status_history (flat_id, new_status, actor, reason, created_at)
+SELECT id, status, created_by, decline_reason, created_at FROM flats ORDER BY id;
@@ -0,0 +1 @@
+-- Flats got back their status from before moderation, it's recorded in their history, nothing to revert.
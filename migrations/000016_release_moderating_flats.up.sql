@@ -0,0 +1,19 @@
+-- Flats are no longer left on moderation between transactions, so ones stuck there by an interrupted update
+-- get back the status they had before it, as recorded in their history. Flats moderated before history
+-- was recorded have no such record, and only created flats are awaiting moderation, so they are created.
+INSERT INTO flat_status_history (flat_id, old_status, new_status)
+SELECT f.id, f.status, coalesce((
+    SELECT h.old_status FROM flat_status_history h
+    WHERE h.flat_id = f.id AND h.new_status = 'moderating'
+    ORDER BY h.id DESC
+    LIMIT 1
+), 'created')
+FROM flats f WHERE f.status = 'moderating' ORDER BY f.id;
+
+UPDATE flats f SET status = (
+    SELECT h.new_status FROM flat_status_history h
+    WHERE h.flat_id = f.id
+    ORDER BY h.id DESC
+    LIMIT 1
+), claimed_by = NULL, claim_expires_at = NULL
+WHERE f.status = 'moderating';
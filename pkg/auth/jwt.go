@@ -7,8 +7,14 @@ import (
 )
 
 type TokensManager interface {
-	GenerateJWT(userType string) (string, error)
-	Parse(token string) (string, error)
+	GenerateJWT(userId, userType string) (string, error)
+	Parse(token string) (Claims, error)
+}
+
+// Claims identify user token was issued to.
+type Claims struct {
+	UserID   string
+	UserType string
 }
 
 type JWTManager struct {
@@ -23,9 +29,10 @@ func NewJWTManager(secretKey string, tokenTTL time.Duration) *JWTManager {
 	}
 }
 
-// GenerateJWT generates and returns JWT token from user type and sets expiration date.
-func (m *JWTManager) GenerateJWT(userType string) (string, error) {
+// GenerateJWT generates and returns JWT token from user id and type and sets expiration date.
+func (m *JWTManager) GenerateJWT(userId, userType string) (string, error) {
 	claims := jwt.MapClaims{
+		"userId":   userId,
 		"userType": userType,
 		"exp":      time.Now().Add(m.tokenTTL).Unix(),
 	}
@@ -35,8 +42,8 @@ func (m *JWTManager) GenerateJWT(userType string) (string, error) {
 	return token.SignedString([]byte(m.secretKey))
 }
 
-// Parse Returns user type from token if there is one.
-func (m *JWTManager) Parse(inpToken string) (string, error) {
+// Parse Returns user id and type from token if there are ones.
+func (m *JWTManager) Parse(inpToken string) (Claims, error) {
 	token, err := jwt.Parse(inpToken, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -45,13 +52,19 @@ func (m *JWTManager) Parse(inpToken string) (string, error) {
 		return []byte(m.secretKey), nil
 	})
 	if err != nil {
-		return "", err
+		return Claims{}, err
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", fmt.Errorf("error get user claims from token")
+		return Claims{}, fmt.Errorf("error get user claims from token")
+	}
+
+	userId, _ := claims["userId"].(string)
+	userType, ok := claims["userType"].(string)
+	if !ok {
+		return Claims{}, fmt.Errorf("error get user type from token")
 	}
 
-	return claims["userType"].(string), nil
+	return Claims{UserID: userId, UserType: userType}, nil
 }
@@ -0,0 +1,43 @@
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Ceiling returns longest delay before retry attempt: base delay doubled for each previous retry
+// and capped by max if it's positive. Attempts below the first are treated as the first one,
+// and uncapped delay stops growing at the longest duration instead of overflowing.
+func Ceiling(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	delay := base
+	for i := 1; i < attempt && (max <= 0 || delay < max); i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+
+			break
+		}
+
+		delay *= 2
+	}
+
+	if max > 0 && delay > max {
+		delay = max
+	}
+
+	return delay
+}
+
+// FullJitter returns random delay before retry attempt, from zero up to Ceiling ("full jitter").
+func FullJitter(attempt int, base, max time.Duration) time.Duration {
+	delay := Ceiling(attempt, base, max)
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay)))
+}
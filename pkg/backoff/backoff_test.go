@@ -0,0 +1,91 @@
+package backoff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCeiling(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		base    time.Duration
+		max     time.Duration
+		want    time.Duration
+	}{
+		{name: "First attempt", attempt: 1, base: time.Second, max: time.Minute, want: time.Second},
+		{name: "Doubled for each retry", attempt: 4, base: time.Second, max: time.Minute, want: 8 * time.Second},
+		{name: "Capped", attempt: 7, base: time.Second, max: time.Minute, want: time.Minute},
+		{name: "Zero attempt", attempt: 0, base: time.Second, max: time.Minute, want: time.Second},
+		{name: "Negative attempt", attempt: -3, base: time.Second, max: time.Minute, want: time.Second},
+		{name: "Large attempt", attempt: 1000, base: time.Second, max: time.Minute, want: time.Minute},
+		{name: "Largest attempt", attempt: math.MaxInt, base: time.Second, max: time.Minute, want: time.Minute},
+		{name: "Uncapped", attempt: 11, base: time.Millisecond, want: 1024 * time.Millisecond},
+		{name: "Uncapped large attempt", attempt: 1000, base: time.Second, want: math.MaxInt64},
+		{name: "Zero base", attempt: 5, max: time.Minute, want: 0},
+		{name: "Base above max", attempt: 1, base: time.Hour, max: time.Minute, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Ceiling(tt.attempt, tt.base, tt.max))
+		})
+	}
+}
+
+func TestCeiling_Monotonic(t *testing.T) {
+	tests := []struct {
+		name string
+		base time.Duration
+		max  time.Duration
+	}{
+		{name: "Capped", base: 100 * time.Millisecond, max: 10 * time.Minute},
+		{name: "Uncapped", base: time.Nanosecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := time.Duration(0)
+			for attempt := -1; attempt <= 200; attempt++ {
+				delay := Ceiling(attempt, tt.base, tt.max)
+
+				assert.GreaterOrEqual(t, delay, prev, "attempt %d", attempt)
+				if tt.max > 0 {
+					assert.LessOrEqual(t, delay, tt.max, "attempt %d", attempt)
+				}
+
+				prev = delay
+			}
+		})
+	}
+}
+
+func TestFullJitter(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		base    time.Duration
+		max     time.Duration
+	}{
+		{name: "First attempt", attempt: 1, base: 100 * time.Millisecond, max: time.Second},
+		{name: "Capped", attempt: 20, base: 100 * time.Millisecond, max: time.Second},
+		{name: "Zero attempt", attempt: 0, base: 100 * time.Millisecond, max: time.Second},
+		{name: "Uncapped large attempt", attempt: 1000, base: time.Second},
+		{name: "Zero base", attempt: 3, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := Ceiling(tt.attempt, tt.base, tt.max)
+
+			for range 100 {
+				delay := FullJitter(tt.attempt, tt.base, tt.max)
+
+				assert.GreaterOrEqual(t, delay, time.Duration(0))
+				assert.LessOrEqual(t, delay, limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[int, string](2, 0)
+
+	c.Set(1, "one")
+	c.Set(2, "two")
+
+	// Touch 1 so that 2 becomes the least recently used.
+	v, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "one", v)
+
+	c.Set(3, "three")
+
+	_, ok = c.Get(2)
+	assert.False(t, ok)
+
+	c.DeleteFunc(func(key int) bool { return key == 1 })
+
+	_, ok = c.Get(1)
+	assert.False(t, ok)
+
+	assert.Equal(t, Stats{Size: 1, Capacity: 2, Hits: 1, Misses: 2, Evictions: 1}, c.Stats())
+}
+
+func TestLRUExpiration(t *testing.T) {
+	c := NewLRU[int, string](2, time.Millisecond)
+
+	c.Set(1, "one")
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Stats().Size)
+}
@@ -0,0 +1,39 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func NewCSV(w io.Writer) Writer {
+	return &csvWriter{
+		w: csv.NewWriter(w),
+	}
+}
+
+func (c *csvWriter) Write(values []any) error {
+	c.record = c.record[:0]
+	for _, v := range values {
+		c.record = append(c.record, formatValue(v))
+	}
+
+	if err := c.w.Write(c.record); err != nil {
+		return err
+	}
+
+	// Flush every row so that client receives data as soon as it's read from database.
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+
+	return c.w.Error()
+}
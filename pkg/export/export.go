@@ -0,0 +1,66 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Writer streams table rows to the underlying io.Writer without buffering the whole table.
+type Writer interface {
+	Write(values []any) error
+	Close() error
+}
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// New returns Writer for the format.
+func New(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSV(w), nil
+	case FormatXLSX:
+		return NewXLSX(w)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// ContentType returns MIME type of files in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "application/octet-stream"
+}
+
+func (f Format) Validate() bool {
+	return f == FormatCSV || f == FormatXLSX
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,79 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type SMSSender interface {
+	SendSMS(ctx context.Context, sms SMS) error
+}
+
+type SMS struct {
+	To   string
+	Text string
+}
+
+type PushSender interface {
+	SendPush(ctx context.Context, push Push) error
+}
+
+type Push struct {
+	Token string
+	Title string
+	Body  string
+}
+
+// Stub is local stand-in for SMS and push senders.
+// It writes messages to w and remembers them instead of sending.
+type Stub struct {
+	w io.Writer
+
+	mu   sync.Mutex
+	sms  []SMS
+	push []Push
+}
+
+func NewStub(w io.Writer) *Stub {
+	return &Stub{
+		w: w,
+	}
+}
+
+func (s *Stub) SendSMS(ctx context.Context, sms SMS) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sms = append(s.sms, sms)
+	fmt.Fprintf(s.w, "send sms '%s' to '%s'\n", sms.Text, sms.To)
+
+	return nil
+}
+
+func (s *Stub) SendPush(ctx context.Context, push Push) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.push = append(s.push, push)
+	fmt.Fprintf(s.w, "send push '%s' to '%s'\n", push.Title, push.Token)
+
+	return nil
+}
+
+// SMS returns sent SMS.
+func (s *Stub) SMS() []SMS {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SMS(nil), s.sms...)
+}
+
+// Push returns sent push notifications.
+func (s *Stub) Push() []Push {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Push(nil), s.push...)
+}
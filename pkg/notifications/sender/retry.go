@@ -0,0 +1,68 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/backoff"
+	"time"
+)
+
+// ErrAttemptsExhausted is returned by retrying sender when message wasn't sent in all attempts.
+var ErrAttemptsExhausted = errors.New("send attempts exhausted")
+
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+type retrying struct {
+	next Sender
+	cfg  RetryConfig
+}
+
+// NewRetrying returns sender retrying failed sends of next with jittered exponential back-off.
+// Retries stop once ctx is done, so that they don't outlive the caller.
+func NewRetrying(next Sender, cfg RetryConfig) *retrying {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+
+	return &retrying{
+		next: next,
+		cfg:  cfg,
+	}
+}
+
+func (s *retrying) SendEmail(ctx context.Context, email Email) error {
+	var err error
+	for attempt := 0; attempt < s.cfg.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(s.backoff(attempt))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return fmt.Errorf("%w: last error: %w", ctx.Err(), err)
+			case <-timer.C:
+			}
+		}
+
+		if err = s.next.SendEmail(ctx, email); err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: last error: %w", ctx.Err(), err)
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrAttemptsExhausted, err)
+}
+
+// backoff returns random delay before attempt.
+func (s *retrying) backoff(attempt int) time.Duration {
+	return backoff.FullJitter(attempt, s.cfg.BaseDelay, s.cfg.MaxDelay)
+}
@@ -0,0 +1,75 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/backoff"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var testEmail = Email{To: "test@mail.ru", Subject: "subject", Text: "message"}
+
+// flaky fails first failures sends.
+type flaky struct {
+	failures int
+	calls    int
+}
+
+func (s *flaky) SendEmail(ctx context.Context, email Email) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("internal error")
+	}
+
+	return nil
+}
+
+func TestRetrying_SendEmail(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	t.Run("Succeeds after retries", func(t *testing.T) {
+		next := &flaky{failures: 2}
+
+		err := NewRetrying(next, cfg).SendEmail(context.Background(), testEmail)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, next.calls)
+	})
+
+	t.Run("Attempts exhausted", func(t *testing.T) {
+		next := &flaky{failures: 5}
+
+		err := NewRetrying(next, cfg).SendEmail(context.Background(), testEmail)
+
+		assert.ErrorIs(t, err, ErrAttemptsExhausted)
+		assert.Equal(t, 3, next.calls)
+	})
+
+	t.Run("Context cancelled", func(t *testing.T) {
+		next := &flaky{failures: 5}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := NewRetrying(next, RetryConfig{MaxAttempts: 3, BaseDelay: time.Hour}).SendEmail(ctx, testEmail)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, next.calls)
+	})
+}
+
+func TestRetrying_Backoff(t *testing.T) {
+	s := NewRetrying(nil, RetryConfig{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	for attempt := 1; attempt < 10; attempt++ {
+		limit := backoff.Ceiling(attempt, 100*time.Millisecond, time.Second)
+
+		for i := 0; i < 100; i++ {
+			delay := s.backoff(attempt)
+			assert.GreaterOrEqual(t, delay, time.Duration(0))
+			assert.LessOrEqual(t, delay, limit)
+		}
+	}
+}
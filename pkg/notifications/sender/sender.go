@@ -9,7 +9,17 @@ import (
 )
 
 type Sender interface {
-	SendEmail(ctx context.Context, recipient string, message string) error
+	SendEmail(ctx context.Context, email Email) error
+}
+
+// Email to a single recipient. HTML is optional, Text is always sent.
+// Unsubscribe is optional one-click unsubscribe URL sent in List-Unsubscribe header.
+type Email struct {
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+	Unsubscribe string
 }
 
 type sender struct{}
@@ -18,10 +28,17 @@ func New() *sender {
 	return &sender{}
 }
 
-func (s *sender) SendEmail(ctx context.Context, recipient string, message string) error {
+func (s *sender) SendEmail(ctx context.Context, email Email) error {
 	// Имитация отправки сообщения
 	duration := time.Duration(rand.Int63n(3000)) * time.Millisecond
-	time.Sleep(duration)
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
 
 	// Имитация неуспешной отправки сообщения
 	errorProbability := 0.1
@@ -29,7 +46,7 @@ func (s *sender) SendEmail(ctx context.Context, recipient string, message string
 		return errors.New("internal error")
 	}
 
-	fmt.Printf("send message '%s' to '%s'\n", message, recipient)
+	fmt.Printf("send message '%s' to '%s'\n", email.Text, email.To)
 
 	return nil
 }
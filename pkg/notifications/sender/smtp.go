@@ -0,0 +1,341 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type TLSMode string
+
+const (
+	TLSNone     TLSMode = "none"
+	TLSStartTLS TLSMode = "starttls"
+	TLSImplicit TLSMode = "tls"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      TLSMode
+	// TLSConfig is used for STARTTLS and implicit TLS, ServerName defaults to Host.
+	TLSConfig *tls.Config
+	// Timeout limits each send, connecting included, when context has no earlier deadline.
+	Timeout time.Duration
+}
+
+// smtpSender sends emails over a single SMTP connection reused between sends.
+type smtpSender struct {
+	cfg  SMTPConfig
+	from string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	client *smtp.Client
+}
+
+func NewSMTP(cfg SMTPConfig) (*smtpSender, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+
+	switch cfg.TLS {
+	case "":
+		cfg.TLS = TLSStartTLS
+	case TLSNone, TLSStartTLS, TLSImplicit:
+	default:
+		return nil, fmt.Errorf("unknown tls mode '%s'", cfg.TLS)
+	}
+
+	return &smtpSender{
+		cfg:  cfg,
+		from: from.Address,
+	}, nil
+}
+
+func (s *smtpSender) SendEmail(ctx context.Context, email Email) error {
+	msg, err := buildMessage(s.cfg.From, email)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deadline := sendDeadline(ctx, s.cfg.Timeout)
+
+	// Reused connection may have been closed by server while idle.
+	if s.client != nil {
+		_ = s.conn.SetDeadline(deadline)
+
+		if err := s.client.Noop(); err != nil {
+			s.close()
+		}
+	}
+
+	if s.client == nil {
+		if err := s.connect(ctx, deadline); err != nil {
+			return fmt.Errorf("smtp connect: %w", err)
+		}
+	}
+
+	// Unblock connection when context is done, it's not used after send anyway.
+	stop := context.AfterFunc(ctx, func() {
+		_ = s.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := s.send(email.To, msg); err != nil {
+		s.close()
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("smtp send: %w", ctx.Err())
+		}
+
+		return fmt.Errorf("smtp send: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes connection to the server.
+func (s *smtpSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return nil
+	}
+
+	err := s.client.Quit()
+	s.close()
+
+	return err
+}
+
+func (s *smtpSender) connect(ctx context.Context, deadline time.Time) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tlsConfig()}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+
+		return err
+	}
+
+	if err = s.handshake(client); err != nil {
+		client.Close()
+
+		return err
+	}
+
+	s.conn = conn
+	s.client = client
+
+	return nil
+}
+
+func (s *smtpSender) handshake(client *smtp.Client) error {
+	if s.cfg.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server doesn't support STARTTLS")
+		}
+
+		if err := client.StartTLS(s.tlsConfig()); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *smtpSender) send(to string, msg []byte) error {
+	if err := s.client.Mail(s.from); err != nil {
+		return err
+	}
+
+	if err := s.client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := s.client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// sendDeadline returns the earliest of context deadline and timeout from now, zero if there is none.
+func sendDeadline(ctx context.Context, timeout time.Duration) time.Time {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+
+	return deadline
+}
+
+func (s *smtpSender) close() {
+	if s.client != nil {
+		s.client.Close()
+	}
+
+	s.client = nil
+	s.conn = nil
+}
+
+func (s *smtpSender) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if s.cfg.TLSConfig != nil {
+		cfg = s.cfg.TLSConfig.Clone()
+	}
+
+	if cfg.ServerName == "" {
+		cfg.ServerName = s.cfg.Host
+	}
+
+	return cfg
+}
+
+// buildMessage returns MIME message with plain text body, or with alternative plain text and HTML bodies.
+func buildMessage(from string, email Email) ([]byte, error) {
+	var body bytes.Buffer
+	var contentType string
+
+	if email.HTML == "" {
+		contentType = "text/plain; charset=utf-8"
+
+		if err := writeQuotedPrintable(&body, email.Text); err != nil {
+			return nil, err
+		}
+	} else {
+		mw := multipart.NewWriter(&body)
+		contentType = "multipart/alternative; boundary=" + mw.Boundary()
+
+		parts := []struct {
+			contentType string
+			content     string
+		}{
+			{"text/plain; charset=utf-8", email.Text},
+			{"text/html; charset=utf-8", email.HTML},
+		}
+
+		for _, p := range parts {
+			w, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {p.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if err = writeQuotedPrintable(w, p.content); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", email.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", email.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageId(from)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	}
+	if email.HTML == "" {
+		headers = append(headers, [2]string{"Content-Transfer-Encoding", "quoted-printable"})
+	}
+	if email.Unsubscribe != "" {
+		headers = append(headers,
+			[2]string{"List-Unsubscribe", "<" + email.Unsubscribe + ">"},
+			[2]string{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"},
+		)
+	}
+
+	for _, h := range headers {
+		msg.WriteString(h[0] + ": " + h[1] + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	return qw.Close()
+}
+
+func messageId(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
@@ -0,0 +1,463 @@
+package sender
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeMessage struct {
+	From string
+	To   []string
+	Data string
+	TLS  bool
+}
+
+// fakeSMTPServer is a minimal in-process SMTP server accepting PLAIN auth of a single user.
+type fakeSMTPServer struct {
+	ln        net.Listener
+	tlsConfig *tls.Config // STARTTLS is advertised if set.
+	username  string
+	password  string
+	// closeAfterMessage makes server drop connection after each accepted message.
+	closeAfterMessage bool
+
+	mu          sync.Mutex
+	messages    []fakeMessage
+	connections int
+}
+
+func newFakeSMTPServer(t *testing.T, implicitTLS *tls.Config) *fakeSMTPServer {
+	var ln net.Listener
+	var err error
+	if implicitTLS != nil {
+		ln, err = tls.Listen("tcp", "127.0.0.1:0", implicitTLS)
+	} else {
+		ln, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	require.NoError(t, err)
+
+	s := &fakeSMTPServer{ln: ln, username: "user", password: "secret"}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.connections++
+			s.mu.Unlock()
+
+			go s.serve(conn, implicitTLS != nil)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) received() ([]fakeMessage, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]fakeMessage(nil), s.messages...), s.connections
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn, isTLS bool) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 fake ESMTP")
+
+	var msg fakeMessage
+	authorized := s.username == ""
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			lines := []string{"fake"}
+			if s.tlsConfig != nil && !isTLS {
+				lines = append(lines, "STARTTLS")
+			}
+			lines = append(lines, "AUTH PLAIN", "8BITMIME")
+
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				_ = tp.PrintfLine("250%s%s", sep, l)
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready")
+
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+
+			conn, isTLS = tlsConn, true
+			tp = textproto.NewConn(conn)
+		case "AUTH":
+			_, resp, _ := strings.Cut(arg, " ")
+			creds, _ := base64.StdEncoding.DecodeString(resp)
+
+			if string(creds) == "\x00"+s.username+"\x00"+s.password {
+				authorized = true
+				_ = tp.PrintfLine("235 authenticated")
+			} else {
+				_ = tp.PrintfLine("535 authentication failed")
+			}
+		case "MAIL":
+			if !authorized {
+				_ = tp.PrintfLine("530 authentication required")
+				continue
+			}
+
+			msg = fakeMessage{From: pathArg(arg, "FROM:"), TLS: isTLS}
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			msg.To = append(msg.To, pathArg(arg, "TO:"))
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			_ = tp.PrintfLine("250 queued")
+
+			if s.closeAfterMessage {
+				return
+			}
+		case "RSET", "NOOP":
+			_ = tp.PrintfLine("250 ok")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+
+			return
+		default:
+			_ = tp.PrintfLine("502 unknown command")
+		}
+	}
+}
+
+// pathArg returns address of MAIL or RCPT command argument, ignoring parameters after it.
+func pathArg(arg, prefix string) string {
+	path, _, _ := strings.Cut(strings.TrimPrefix(arg, prefix), " ")
+
+	return strings.Trim(path, "<>")
+}
+
+// testCertificate returns self-signed certificate for 127.0.0.1 and pool trusting it.
+func testCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake smtp"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func testSMTPConfig(port int) SMTPConfig {
+	return SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "user",
+		Password: "secret",
+		From:     "Avito <noreply@avito.test>",
+		TLS:      TLSNone,
+		Timeout:  5 * time.Second,
+	}
+}
+
+func TestSMTP_SendEmail_Multipart(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	s, err := NewSMTP(testSMTPConfig(server.port()))
+	require.NoError(t, err)
+	defer s.Close()
+
+	err = s.SendEmail(context.Background(), Email{
+		To:      "client@mail.ru",
+		Subject: "Новая квартира",
+		Text:    "Квартира №1 доступна",
+		HTML:    "<p>Квартира №1 доступна</p>",
+	})
+	require.NoError(t, err)
+
+	messages, _ := server.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "noreply@avito.test", messages[0].From)
+	assert.Equal(t, []string{"client@mail.ru"}, messages[0].To)
+
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Новая квартира", subject)
+	assert.Equal(t, "client@mail.ru", msg.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+
+	expected := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", "Квартира №1 доступна"},
+		{"text/html; charset=utf-8", "<p>Квартира №1 доступна</p>"},
+	}
+	for _, e := range expected {
+		part, err := mr.NextPart()
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		assert.Equal(t, e.contentType, part.Header.Get("Content-Type"))
+		assert.Equal(t, e.body, string(body))
+	}
+
+	_, err = mr.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestSMTP_SendEmail_PlainText(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	s, err := NewSMTP(testSMTPConfig(server.port()))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.SendEmail(context.Background(), Email{To: "client@mail.ru", Subject: "subject", Text: "line 1\nline 2"}))
+
+	messages, _ := server.received()
+	require.Len(t, messages, 1)
+
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+	assert.Equal(t, "quoted-printable", msg.Header.Get("Content-Transfer-Encoding"))
+	assert.Empty(t, msg.Header.Get("List-Unsubscribe"))
+}
+
+func TestSMTP_SendEmail_ListUnsubscribe(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	s, err := NewSMTP(testSMTPConfig(server.port()))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.SendEmail(context.Background(), Email{
+		To:          "client@mail.ru",
+		Subject:     "subject",
+		Text:        "text",
+		Unsubscribe: "http://localhost/api/unsubscribe?token=abc",
+	}))
+
+	messages, _ := server.received()
+	require.Len(t, messages, 1)
+
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, "<http://localhost/api/unsubscribe?token=abc>", msg.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+}
+
+func TestSMTP_SendEmail_ReusesConnection(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	s, err := NewSMTP(testSMTPConfig(server.port()))
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.SendEmail(context.Background(), Email{To: "client" + strconv.Itoa(i) + "@mail.ru", Text: "text"}))
+	}
+
+	messages, connections := server.received()
+	assert.Len(t, messages, 3)
+	assert.Equal(t, 1, connections)
+}
+
+func TestSMTP_SendEmail_ReconnectsAfterServerClose(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+	server.closeAfterMessage = true
+
+	s, err := NewSMTP(testSMTPConfig(server.port()))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.SendEmail(context.Background(), Email{To: "client@mail.ru", Text: "first"}))
+	require.NoError(t, s.SendEmail(context.Background(), Email{To: "client@mail.ru", Text: "second"}))
+
+	messages, connections := server.received()
+	assert.Len(t, messages, 2)
+	assert.Equal(t, 2, connections)
+}
+
+func TestSMTP_SendEmail_StartTLS(t *testing.T) {
+	cert, pool := testCertificate(t)
+
+	server := newFakeSMTPServer(t, nil)
+	server.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	cfg := testSMTPConfig(server.port())
+	cfg.TLS = TLSStartTLS
+	cfg.TLSConfig = &tls.Config{RootCAs: pool}
+
+	s, err := NewSMTP(cfg)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.SendEmail(context.Background(), Email{To: "client@mail.ru", Text: "text"}))
+
+	messages, _ := server.received()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].TLS)
+}
+
+func TestSMTP_SendEmail_StartTLSNotSupported(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	cfg := testSMTPConfig(server.port())
+	cfg.TLS = TLSStartTLS
+
+	s, err := NewSMTP(cfg)
+	require.NoError(t, err)
+	defer s.Close()
+
+	err = s.SendEmail(context.Background(), Email{To: "client@mail.ru", Text: "text"})
+	assert.ErrorContains(t, err, "STARTTLS")
+}
+
+func TestSMTP_SendEmail_ImplicitTLS(t *testing.T) {
+	cert, pool := testCertificate(t)
+
+	server := newFakeSMTPServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+	cfg := testSMTPConfig(server.port())
+	cfg.TLS = TLSImplicit
+	cfg.TLSConfig = &tls.Config{RootCAs: pool}
+
+	s, err := NewSMTP(cfg)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.SendEmail(context.Background(), Email{To: "client@mail.ru", Text: "text"}))
+
+	messages, _ := server.received()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].TLS)
+}
+
+func TestSMTP_SendEmail_AuthFailed(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	cfg := testSMTPConfig(server.port())
+	cfg.Password = "wrong"
+
+	s, err := NewSMTP(cfg)
+	require.NoError(t, err)
+	defer s.Close()
+
+	err = s.SendEmail(context.Background(), Email{To: "client@mail.ru", Text: "text"})
+	assert.Error(t, err)
+
+	messages, _ := server.received()
+	assert.Empty(t, messages)
+}
+
+func TestSMTP_SendEmail_ContextCancelled(t *testing.T) {
+	// Server accepting connections but never greeting.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			_, _ = io.Copy(io.Discard, bufio.NewReader(conn))
+		}
+	}()
+
+	s, err := NewSMTP(testSMTPConfig(ln.Addr().(*net.TCPAddr).Port))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = s.SendEmail(ctx, Email{To: "client@mail.ru", Text: "text"})
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestNewSMTP_InvalidConfig(t *testing.T) {
+	_, err := NewSMTP(SMTPConfig{From: "not an address"})
+	assert.Error(t, err)
+
+	_, err = NewSMTP(SMTPConfig{From: "noreply@avito.test", TLS: "ssl"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed files/*.tmpl
+var files embed.FS
+
+// DefaultLocale is used when template has no variant for requested locale.
+const DefaultLocale = "ru"
+
+var ErrNotFound = errors.New("template not found")
+
+// Message is rendered notification. HTML is empty if template doesn't define it.
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type key struct {
+	event  string
+	locale string
+}
+
+// Renderer renders notifications from templates named "<event>.<locale>.tmpl".
+// Each template defines "subject" and "text" blocks executed as text/template
+// and optional "html" block executed as html/template.
+type Renderer struct {
+	text map[key]*texttemplate.Template
+	html map[key]*htmltemplate.Template
+}
+
+// New returns renderer of embedded templates.
+func New() (*Renderer, error) {
+	return Parse(files, "files/*.tmpl")
+}
+
+// Parse returns renderer of templates from fsys matching pattern.
+func Parse(fsys fs.FS, pattern string) (*Renderer, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{
+		text: make(map[key]*texttemplate.Template),
+		html: make(map[key]*htmltemplate.Template),
+	}
+
+	for _, name := range names {
+		event, locale, ok := strings.Cut(strings.TrimSuffix(path.Base(name), ".tmpl"), ".")
+		if !ok {
+			return nil, fmt.Errorf("template %s: name must be <event>.<locale>.tmpl", name)
+		}
+
+		k := key{event: event, locale: locale}
+
+		text, err := texttemplate.New(name).Option("missingkey=error").ParseFS(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if text.Lookup("subject") == nil || text.Lookup("text") == nil {
+			return nil, fmt.Errorf("template %s: subject and text must be defined", name)
+		}
+
+		r.text[k] = text
+
+		if html, err := htmltemplate.New(name).Option("missingkey=error").ParseFS(fsys, name); err != nil {
+			return nil, err
+		} else if html.Lookup("html") != nil {
+			r.html[k] = html
+		}
+	}
+
+	return r, nil
+}
+
+// Render renders event template for locale, falling back to DefaultLocale.
+func (r *Renderer) Render(event, locale string, data any) (Message, error) {
+	k := key{event: event, locale: locale}
+	if _, ok := r.text[k]; !ok {
+		k.locale = DefaultLocale
+	}
+
+	text, ok := r.text[k]
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrNotFound, event)
+	}
+
+	var msg Message
+	var buf bytes.Buffer
+
+	if err := text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return Message{}, err
+	}
+	msg.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := text.ExecuteTemplate(&buf, "text", data); err != nil {
+		return Message{}, err
+	}
+	msg.Text = strings.TrimSpace(buf.String())
+
+	if html, ok := r.html[k]; ok {
+		buf.Reset()
+		if err := html.ExecuteTemplate(&buf, "html", data); err != nil {
+			return Message{}, err
+		}
+		msg.HTML = strings.TrimSpace(buf.String())
+	}
+
+	return msg, nil
+}
+
+// Locales returns sorted locales event template has variants for.
+func (r *Renderer) Locales(event string) []string {
+	var locales []string
+	for k := range r.text {
+		if k.event == event {
+			locales = append(locales, k.locale)
+		}
+	}
+	sort.Strings(locales)
+
+	return locales
+}
@@ -0,0 +1,102 @@
+package templates
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"testing/fstest"
+)
+
+type flatApproved struct {
+	HouseID         int
+	FlatNumber      int
+	Rooms           int
+	Price           int
+	HouseLink       string
+	UnsubscribeLink string
+}
+
+func TestRenderer_Render(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+
+	data := flatApproved{HouseID: 1, FlatNumber: 12, Rooms: 2, Price: 5000, HouseLink: "http://localhost/api/house/1?a=1&b=2"}
+
+	msg, err := r.Render("flat_approved", "en", data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "New flat in house 1", msg.Subject)
+	assert.Equal(t, "New flat №12 is available: 2 rooms, price 5000. See all house flats at http://localhost/api/house/1?a=1&b=2", msg.Text)
+	assert.Contains(t, msg.HTML, `<a href="http://localhost/api/house/1?a=1&amp;b=2">`)
+	assert.NotContains(t, msg.HTML, "Unsubscribe")
+
+	data.UnsubscribeLink = "http://localhost/api/unsubscribe?token=abc"
+
+	msg, err = r.Render("flat_approved", "en", data)
+	require.NoError(t, err)
+	assert.Equal(t, "New flat №12 is available: 2 rooms, price 5000. See all house flats at http://localhost/api/house/1?a=1&b=2\n\n"+
+		"Unsubscribe: http://localhost/api/unsubscribe?token=abc", msg.Text)
+	assert.Contains(t, msg.HTML, `<p><a href="http://localhost/api/unsubscribe?token=abc">Unsubscribe</a></p>`)
+
+	msg, err = r.Render("flat_approved", "ru", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Новая квартира в доме 1", msg.Subject)
+
+	// Unknown locale falls back to default one.
+	fallback, err := r.Render("flat_approved", "de", data)
+	require.NoError(t, err)
+	assert.Equal(t, msg, fallback)
+
+	_, err = r.Render("unknown", "en", data)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, []string{"en", "ru"}, r.Locales("flat_approved"))
+}
+
+func TestRenderer_EscapesHTML(t *testing.T) {
+	r, err := Parse(fstest.MapFS{
+		"event.en.tmpl": {Data: []byte(`{{define "subject"}}{{.}}{{end}}{{define "text"}}{{.}}{{end}}{{define "html"}}<b>{{.}}</b>{{end}}`)},
+	}, "*.tmpl")
+	require.NoError(t, err)
+
+	msg, err := r.Render("event", "en", "<script>")
+	require.NoError(t, err)
+
+	assert.Equal(t, "<script>", msg.Text)
+	assert.Equal(t, "<b>&lt;script&gt;</b>", msg.HTML)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(fstest.MapFS{
+		"event.tmpl": {Data: []byte(`{{define "subject"}}{{end}}{{define "text"}}{{end}}`)},
+	}, "*.tmpl")
+	assert.Error(t, err)
+
+	_, err = Parse(fstest.MapFS{
+		"event.en.tmpl": {Data: []byte(`{{define "subject"}}{{end}}`)},
+	}, "*.tmpl")
+	assert.Error(t, err)
+}
+
+func TestRenderDigest(t *testing.T) {
+	r, err := New()
+	require.NoError(t, err)
+
+	data := map[string]any{
+		"Delivery": "weekly",
+		"Count":    2,
+		"Flats": []map[string]any{
+			{"HouseID": 1, "FlatNumber": 42, "Rooms": 2, "Price": 100, "HouseLink": "http://localhost/api/house/1"},
+			{"HouseID": 2, "FlatNumber": 7, "Rooms": 1, "Price": 50, "HouseLink": "http://localhost/api/house/2"},
+		},
+		"UnsubscribeLink": "",
+	}
+
+	msg, err := r.Render("flats_digest", "en", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly digest: 2 new flats", msg.Subject)
+	assert.Equal(t, "New flats in houses you are subscribed to:\n"+
+		"- house 1, flat №42: 2 rooms, price 100. http://localhost/api/house/1\n"+
+		"- house 2, flat №7: 1 rooms, price 50. http://localhost/api/house/2", msg.Text)
+	assert.Contains(t, msg.HTML, `<li><a href="http://localhost/api/house/2">House 2</a>, flat №7: 1 rooms, price 50.</li>`)
+}
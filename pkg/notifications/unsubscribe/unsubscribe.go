@@ -0,0 +1,91 @@
+package unsubscribe
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid unsubscribe token")
+	ErrTokenExpired = errors.New("unsubscribe token expired")
+)
+
+// Claims identify subscriptions of recipient to houses token unsubscribes from.
+type Claims struct {
+	Email     string `json:"email"`
+	HouseIDs  []int  `json:"house_ids"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer issues and verifies unsubscribe tokens of form "<payload>.<signature>", where payload
+// is base64url encoded JSON claims and signature is base64url encoded HMAC-SHA256 of payload.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{
+		secret: []byte(secret),
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Sign returns token unsubscribing email from houses, valid for signer ttl.
+func (s *Signer) Sign(email string, houseIds []int) (string, error) {
+	payload, err := json.Marshal(Claims{
+		Email:     email,
+		HouseIDs:  houseIds,
+		ExpiresAt: s.now().Add(s.ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// Verify returns claims of token if its signature is valid and it hasn't expired.
+func (s *Signer) Verify(token string) (Claims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Claims{}, ErrInvalidToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return Claims{}, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Email == "" || len(claims.HouseIDs) == 0 {
+		return Claims{}, ErrInvalidToken
+	}
+
+	if s.now().Unix() >= claims.ExpiresAt {
+		return Claims{}, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+func (s *Signer) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,32 @@
+package unsubscribe
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	s := NewSigner("secret", time.Hour)
+
+	token, err := s.Sign("test@mail.ru", []int{1, 2})
+	require.NoError(t, err)
+
+	claims, err := s.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "test@mail.ru", claims.Email)
+	assert.Equal(t, []int{1, 2}, claims.HouseIDs)
+
+	_, err = NewSigner("other", time.Hour).Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	for _, invalid := range []string{"", "token", token + "x", "x" + token} {
+		_, err = s.Verify(invalid)
+		assert.ErrorIs(t, err, ErrInvalidToken, invalid)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = s.Verify(token)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+}
@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// ErrDeliveryFailed is returned when endpoint didn't accept request.
+var ErrDeliveryFailed = errors.New("webhook delivery failed")
+
+// ErrRejected is returned along with ErrDeliveryFailed when endpoint rejected request, so retrying it is pointless.
+var ErrRejected = errors.New("webhook rejected")
+
+// ErrForbiddenAddress is returned for endpoint host resolving to address that isn't public, e.g. loopback,
+// private or link-local one, so that webhooks can't be used to reach internal services.
+var ErrForbiddenAddress = errors.New("webhook address isn't public")
+
+// nonPublic are globally unicast prefixes that aren't reachable from the internet either.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// isPublic reports whether ip is public unicast address.
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublic {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkDial rejects connections to addresses that aren't public. It's called with the address being dialed,
+// so that host resolving to other address at dispatch than at webhook creation isn't reached either.
+func checkDial(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// Sign returns signature of body sent at timestamp (unix seconds): hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with secret, prefixed with "sha256=".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature of body sent at timestamp is valid.
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+type Config struct {
+	// Timeout limits each delivery.
+	Timeout time.Duration
+	// AllowPrivate lets endpoints have addresses that aren't public, e.g. for local development.
+	AllowPrivate bool
+}
+
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// Result describes delivery, StatusCode is zero if endpoint never responded.
+type Result struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+type Client struct {
+	http         *http.Client
+	allowPrivate bool
+}
+
+func NewClient(cfg Config) *Client {
+	dialer := &net.Dialer{}
+	if !cfg.AllowPrivate {
+		dialer.Control = checkDial
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Requests are sent to endpoints directly, so that dialed address is the endpoint one.
+	transport.Proxy = nil
+
+	return &Client{
+		http:         &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		allowPrivate: cfg.AllowPrivate,
+	}
+}
+
+// CheckURL resolves host of endpoint URL and returns ErrForbiddenAddress if any of its addresses isn't public.
+// Addresses are checked again on each delivery, since host may resolve to other ones by then.
+func (c *Client) CheckURL(ctx context.Context, rawURL string) error {
+	if c.allowPrivate {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range ips {
+		if !isPublic(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+		}
+	}
+
+	return nil
+}
+
+// Deliver makes a single attempt to post signed request body to URL, retrying is left to the caller.
+// Network errors, 408, 429 and 5xx responses are worth retrying, any other non-2xx response
+// and endpoint address that isn't public are reported as ErrRejected.
+func (c *Client) Deliver(ctx context.Context, req Request) (Result, error) {
+	start := time.Now()
+
+	statusCode, retry, err := c.post(ctx, req)
+	res := Result{
+		StatusCode: statusCode,
+		Duration:   time.Since(start),
+	}
+	if err == nil {
+		return res, nil
+	}
+
+	if ctx.Err() != nil {
+		return res, fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+
+	if !retry {
+		return res, fmt.Errorf("%w: %w: %w", ErrDeliveryFailed, ErrRejected, err)
+	}
+
+	return res, fmt.Errorf("%w: %w", ErrDeliveryFailed, err)
+}
+
+// post makes single attempt and reports whether it's worth retrying if it failed.
+func (c *Client) post(ctx context.Context, req Request) (int, bool, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, false, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return 0, !errors.Is(err, ErrForbiddenAddress), err
+	}
+	defer resp.Body.Close()
+
+	// Drain body so connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= 500
+
+	return resp.StatusCode, retry, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+}
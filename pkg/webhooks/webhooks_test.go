@@ -0,0 +1,173 @@
+package webhooks
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"flat_approved"}`)
+
+	signature := Sign("secret", 1724155200, body)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.True(t, Verify("secret", signature, 1724155200, body))
+	assert.False(t, Verify("other", signature, 1724155200, body))
+	assert.False(t, Verify("secret", signature, 1724155201, body))
+	assert.False(t, Verify("secret", signature, 1724155200, []byte(`{}`)))
+}
+
+func TestClient_Deliver(t *testing.T) {
+	// Test servers listen on loopback.
+	cfg := Config{Timeout: time.Second, AllowPrivate: true}
+
+	t.Run("Signed request", func(t *testing.T) {
+		var got *http.Request
+		var gotBody []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			gotBody, _ = io.ReadAll(r.Body)
+		}))
+		defer srv.Close()
+
+		body := []byte(`{"event":"flat_approved"}`)
+		res, err := NewClient(cfg).Deliver(context.Background(), Request{
+			URL:        srv.URL,
+			Secret:     "secret",
+			Event:      "flat_approved",
+			DeliveryID: "7",
+			Body:       body,
+		})
+		require.NoError(t, err)
+
+		assert.Positive(t, res.Duration)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, body, gotBody)
+		assert.Equal(t, "flat_approved", got.Header.Get(HeaderEvent))
+		assert.Equal(t, "7", got.Header.Get(HeaderDelivery))
+
+		timestamp, err := strconv.ParseInt(got.Header.Get(HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.True(t, Verify("secret", got.Header.Get(HeaderSignature), timestamp, body))
+	})
+
+	t.Run("Server errors are worth retrying", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		res, err := NewClient(cfg).Deliver(context.Background(), Request{URL: srv.URL})
+
+		assert.ErrorIs(t, err, ErrDeliveryFailed)
+		assert.NotErrorIs(t, err, ErrRejected)
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	})
+
+	t.Run("Client errors are rejected", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer srv.Close()
+
+		res, err := NewClient(cfg).Deliver(context.Background(), Request{URL: srv.URL})
+
+		assert.ErrorIs(t, err, ErrDeliveryFailed)
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.Equal(t, http.StatusGone, res.StatusCode)
+	})
+
+	t.Run("Network errors are worth retrying", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		srv.Close()
+
+		res, err := NewClient(cfg).Deliver(context.Background(), Request{URL: srv.URL})
+
+		assert.ErrorIs(t, err, ErrDeliveryFailed)
+		assert.NotErrorIs(t, err, ErrRejected)
+		assert.Zero(t, res.StatusCode)
+	})
+
+	t.Run("Context cancelled", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewClient(cfg).Deliver(ctx, Request{URL: srv.URL})
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("Address isn't public", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+		}))
+		defer srv.Close()
+
+		res, err := NewClient(Config{Timeout: time.Second}).Deliver(context.Background(), Request{URL: srv.URL})
+
+		assert.ErrorIs(t, err, ErrDeliveryFailed)
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.ErrorIs(t, err, ErrForbiddenAddress)
+		assert.Zero(t, res.StatusCode)
+		assert.Zero(t, calls.Load())
+	})
+}
+
+func TestClient_CheckURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		forbidden bool
+	}{
+		{name: "Public", url: "https://93.184.216.34/hooks"},
+		{name: "Public IPv6", url: "https://[2606:2800:220:1:248:1893:25c8:1946]/hooks"},
+		{name: "Loopback", url: "http://127.0.0.1:8080/hooks", forbidden: true},
+		{name: "Loopback host", url: "http://localhost/hooks", forbidden: true},
+		{name: "Loopback IPv6", url: "http://[::1]/hooks", forbidden: true},
+		{name: "IPv4-mapped loopback", url: "http://[::ffff:127.0.0.1]/hooks", forbidden: true},
+		{name: "Unspecified", url: "http://0.0.0.0/hooks", forbidden: true},
+		{name: "Private", url: "http://10.1.2.3/hooks", forbidden: true},
+		{name: "Private 192.168", url: "http://192.168.0.10/hooks", forbidden: true},
+		{name: "Private IPv6", url: "http://[fd00::1]/hooks", forbidden: true},
+		{name: "Link-local metadata", url: "http://169.254.169.254/latest/meta-data", forbidden: true},
+		{name: "Link-local IPv6", url: "http://[fe80::1]/hooks", forbidden: true},
+		{name: "Shared address space", url: "http://100.64.0.1/hooks", forbidden: true},
+		{name: "Multicast", url: "http://224.0.0.1/hooks", forbidden: true},
+	}
+
+	client := NewClient(Config{Timeout: time.Second})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.CheckURL(context.Background(), tt.url)
+
+			if tt.forbidden {
+				assert.ErrorIs(t, err, ErrForbiddenAddress)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("Private allowed", func(t *testing.T) {
+		err := NewClient(Config{AllowPrivate: true}).CheckURL(context.Background(), "http://127.0.0.1/hooks")
+
+		assert.NoError(t, err)
+	})
+}
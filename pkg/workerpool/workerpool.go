@@ -0,0 +1,227 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrQueueFull = errors.New("worker pool queue is full")
+	ErrClosed    = errors.New("worker pool is closed")
+)
+
+type Job func()
+
+// Pool runs jobs on a fixed number of workers. Jobs wait for a free worker in a bounded queue,
+// submitting to a full queue either fails or waits, so that callers feel backpressure.
+type Pool struct {
+	workers int
+	queue   chan Job
+
+	// mu guards closed, submitters hold it for reading while putting job to queue.
+	mu     sync.RWMutex
+	closed bool
+
+	closing   chan struct{} // closed on shutdown to release waiting submitters
+	drain     chan struct{} // closed once no more jobs are queued, workers exit on empty queue
+	abort     chan struct{} // closed when drain deadline is exceeded, workers exit after current job
+	closeOnce sync.Once
+	abortOnce sync.Once
+	wg        sync.WaitGroup
+
+	running   atomic.Int64
+	submitted atomic.Uint64
+	completed atomic.Uint64
+	rejected  atomic.Uint64
+	dropped   atomic.Uint64
+	panicked  atomic.Uint64
+}
+
+type Stats struct {
+	Workers   int    `json:"workers"`
+	QueueSize int    `json:"queue_size"`
+	Queued    int    `json:"queued"`
+	Running   int64  `json:"running"`
+	Submitted uint64 `json:"submitted"`
+	Completed uint64 `json:"completed"`
+	Rejected  uint64 `json:"rejected"`
+	Dropped   uint64 `json:"dropped"`
+	Panicked  uint64 `json:"panicked"`
+}
+
+// New starts pool of workers taking jobs from queue of queueSize.
+func New(workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &Pool{
+		workers: workers,
+		queue:   make(chan Job, queueSize),
+		closing: make(chan struct{}),
+		drain:   make(chan struct{}),
+		abort:   make(chan struct{}),
+	}
+
+	p.wg.Add(workers)
+	for range workers {
+		go p.work()
+	}
+
+	return p
+}
+
+// Submit puts job to queue without waiting, ErrQueueFull is returned if there is no room for it.
+func (p *Pool) Submit(job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.rejected.Add(1)
+
+		return ErrClosed
+	}
+
+	select {
+	case p.queue <- job:
+		p.submitted.Add(1)
+
+		return nil
+	default:
+		p.rejected.Add(1)
+
+		return ErrQueueFull
+	}
+}
+
+// SubmitWait puts job to queue, waiting for room in it until ctx is done or pool is shut down.
+func (p *Pool) SubmitWait(ctx context.Context, job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.rejected.Add(1)
+
+		return ErrClosed
+	}
+
+	select {
+	case p.queue <- job:
+		p.submitted.Add(1)
+
+		return nil
+	case <-p.closing:
+		p.rejected.Add(1)
+
+		return ErrClosed
+	case <-ctx.Done():
+		p.rejected.Add(1)
+
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting jobs and waits for queued and running ones to finish.
+// If ctx is done first, jobs that are still queued are dropped and their number is returned
+// together with ctx error. Running jobs aren't interrupted.
+func (p *Pool) Shutdown(ctx context.Context) (int, error) {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+
+		close(p.drain)
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return 0, nil
+	case <-ctx.Done():
+	}
+
+	p.abortOnce.Do(func() {
+		close(p.abort)
+	})
+
+	var dropped int
+	for {
+		select {
+		case <-p.queue:
+			dropped++
+		default:
+			p.dropped.Add(uint64(dropped))
+
+			return dropped, ctx.Err()
+		}
+	}
+}
+
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Workers:   p.workers,
+		QueueSize: cap(p.queue),
+		Queued:    len(p.queue),
+		Running:   p.running.Load(),
+		Submitted: p.submitted.Load(),
+		Completed: p.completed.Load(),
+		Rejected:  p.rejected.Load(),
+		Dropped:   p.dropped.Load(),
+		Panicked:  p.panicked.Load(),
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.abort:
+			return
+		default:
+		}
+
+		select {
+		case job := <-p.queue:
+			p.run(job)
+		case <-p.drain:
+			select {
+			case job := <-p.queue:
+				p.run(job)
+			default:
+				return
+			}
+		}
+	}
+}
+
+// run runs job, recovering from its panic so that worker survives it.
+func (p *Pool) run(job Job) {
+	p.running.Add(1)
+	defer p.running.Add(-1)
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.panicked.Add(1)
+
+			return
+		}
+
+		p.completed.Add(1)
+	}()
+
+	job()
+}
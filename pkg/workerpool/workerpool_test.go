@@ -0,0 +1,104 @@
+package workerpool
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_RunsAndDrainsJobs(t *testing.T) {
+	p := New(2, 10)
+
+	var done atomic.Int64
+	for range 10 {
+		require.NoError(t, p.Submit(func() {
+			time.Sleep(time.Millisecond)
+			done.Add(1)
+		}))
+	}
+
+	dropped, err := p.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, dropped)
+	assert.EqualValues(t, 10, done.Load())
+
+	stats := p.Stats()
+	assert.EqualValues(t, 10, stats.Submitted)
+	assert.EqualValues(t, 10, stats.Completed)
+	assert.Zero(t, stats.Queued)
+
+	assert.ErrorIs(t, p.Submit(func() {}), ErrClosed)
+}
+
+func TestPool_Backpressure(t *testing.T) {
+	p := New(1, 1)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.NoError(t, p.Submit(func() {
+		close(started)
+		<-release
+	}))
+	<-started
+
+	require.NoError(t, p.Submit(func() {}))
+	assert.ErrorIs(t, p.Submit(func() {}), ErrQueueFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.SubmitWait(ctx, func() {}), context.DeadlineExceeded)
+
+	close(release)
+
+	require.NoError(t, p.SubmitWait(context.Background(), func() {}))
+
+	_, err := p.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, p.Stats().Rejected)
+	assert.EqualValues(t, 3, p.Stats().Completed)
+}
+
+func TestPool_ShutdownDeadlineDropsQueuedJobs(t *testing.T) {
+	p := New(1, 5)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	started := make(chan struct{})
+	require.NoError(t, p.Submit(func() {
+		close(started)
+		<-release
+	}))
+	<-started
+
+	var ran atomic.Bool
+	for range 3 {
+		require.NoError(t, p.Submit(func() { ran.Store(true) }))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	dropped, err := p.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 3, dropped)
+	assert.EqualValues(t, 3, p.Stats().Dropped)
+	assert.False(t, ran.Load())
+}
+
+func TestPool_SurvivesPanic(t *testing.T) {
+	p := New(1, 2)
+
+	require.NoError(t, p.Submit(func() { panic("boom") }))
+
+	var ran atomic.Bool
+	require.NoError(t, p.Submit(func() { ran.Store(true) }))
+
+	_, err := p.Shutdown(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ran.Load())
+	assert.EqualValues(t, 1, p.Stats().Panicked)
+}
@@ -39,5 +39,6 @@ var (
 		Email:    "initTester@mail.ru",
 		Password: "qwerty",
 		UserType: domain.UserTypeModerator,
+		Language: domain.LanguageRu,
 	}
 )
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+func (s *APITestSuite) TestSubscriberDigest() {
+	r := s.Require()
+	ctx := context.Background()
+
+	user := domain.User{
+		ID:       uuid.New(),
+		Email:    "digest@mail.ru",
+		Password: "qwerty",
+		UserType: domain.UserTypeClient,
+		Language: domain.LanguageEn,
+	}
+	r.NoError(s.repos.Users.Create(ctx, user))
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "digest test address",
+		Year:      2021,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	r.NoError(s.repos.Houses.SubscribeUser(ctx, domain.Subscription{HouseID: created.ID, Email: user.Email, Delivery: domain.DeliveryImmediate}))
+	r.NoError(s.services.Users.SetSubscriptionDelivery(ctx, user.ID, created.ID, domain.DeliveryDaily))
+
+	subs, err := s.services.Users.Subscriptions(ctx, user.ID)
+	r.NoError(err)
+	r.Len(subs, 1)
+	r.Equal(domain.DeliveryDaily, subs[0].Delivery)
+
+	var flatIds []int
+	for _, number := range []int{413, 414} {
+		flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: number, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+		r.NoError(err)
+
+		_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+		r.NoError(err)
+
+		flatIds = append(flatIds, flat.ID)
+	}
+
+	// Flat edited and approved again within the same day is listed once.
+	_, err = s.repos.Flats.Edit(ctx, flatIds[0], uuid.New(), domain.FlatChanges{})
+	r.NoError(err)
+
+	_, err = s.moderate(ctx, flatIds[0], domain.StatusApproved)
+	r.NoError(err)
+
+	for _, id := range flatIds {
+		r.Zero(s.countOutbox(user.Email, id))
+	}
+
+	// Digest isn't sent before it's due.
+	n, err := s.services.Digests.Flush(ctx)
+	r.NoError(err)
+	r.Zero(n)
+
+	_, err = s.db.Exec(ctx, "UPDATE digest_events SET due_at = now() WHERE user_email = $1", user.Email)
+	r.NoError(err)
+
+	n, err = s.services.Digests.Flush(ctx)
+	r.NoError(err)
+	r.EqualValues(1, n)
+
+	recorder := newRecordingSender()
+	outbox := service.NewOutboxService(s.repos.Outbox, s.repos.Users, s.repos.Notifications, s.senders(recorder), s.templates, s.services.Webhooks, s.pool, s.unsubscribe, "http://test", outboxConfig, logger.NewLogger("debug"))
+
+	for {
+		n, err := outbox.Dispatch(ctx, 100)
+		r.NoError(err)
+
+		if n == 0 {
+			break
+		}
+	}
+
+	sent := recorder.texts(user.Email)
+	r.Len(sent, 1)
+	r.Equal(1, strings.Count(sent[0], "flat №413"))
+	r.Equal(1, strings.Count(sent[0], "flat №414"))
+	r.Contains(sent[0], fmt.Sprintf("http://test/api/house/%d", created.ID))
+}
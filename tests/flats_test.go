@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
 	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
 	"net/http/httptest"
+	"time"
 )
 
 func (s *APITestSuite) TestFlatsCreateSuccess() {
@@ -28,7 +32,7 @@ func (s *APITestSuite) TestFlatsCreateSuccess() {
 
 	b, _ := json.Marshal(input)
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeClient.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeClient.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("POST", "/api/flat/create", bytes.NewBuffer(b))
@@ -75,7 +79,7 @@ func (s *APITestSuite) TestFlatsCreateFlatAlreadyExists() {
 
 	b, _ := json.Marshal(input)
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeClient.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeClient.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("POST", "/api/flat/create", bytes.NewBuffer(b))
@@ -88,6 +92,33 @@ func (s *APITestSuite) TestFlatsCreateFlatAlreadyExists() {
 	r.Equal(http.StatusInternalServerError, resp.Result().StatusCode)
 }
 
+func (s *APITestSuite) TestFlatsNumberUniquePerHouse() {
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "flat numbers test address",
+		Year:      2023,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	// Seeded house has flat with the same number.
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: flatApproved.FlatNumber, Price: 1000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	houseId, err := s.repos.Flats.GetHouseId(ctx, flat.ID)
+	r.NoError(err)
+	r.Equal(created.ID, houseId)
+
+	_, err = s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: flatApproved.FlatNumber, Price: 2000, Rooms: 2, Status: domain.StatusCreated})
+	r.ErrorIs(err, repository.ErrFlatAlreadyExists)
+
+	_, err = s.repos.Flats.Create(ctx, 100500, domain.Flat{FlatNumber: 1, Price: 1000, Rooms: 1, Status: domain.StatusCreated})
+	r.ErrorIs(err, repository.ErrHouseNotFound)
+}
+
 func (s *APITestSuite) TestFlatsUpdateSuccess() {
 	gin.SetMode(gin.TestMode)
 
@@ -102,7 +133,7 @@ func (s *APITestSuite) TestFlatsUpdateSuccess() {
 
 	b, _ := json.Marshal(input)
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeModerator.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeModerator.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("POST", "/api/flat/update", bytes.NewBuffer(b))
@@ -144,7 +175,7 @@ func (s *APITestSuite) TestFlatsUpdateUnauthorized() {
 
 	b, _ := json.Marshal(input)
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeClient.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeClient.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("POST", "/api/flat/update", bytes.NewBuffer(b))
@@ -157,6 +188,365 @@ func (s *APITestSuite) TestFlatsUpdateUnauthorized() {
 	r.Equal(http.StatusUnauthorized, resp.Result().StatusCode)
 }
 
+func (s *APITestSuite) TestFlatsGetById() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "flat details test address",
+		Year:      2024,
+		Developer: "flat details developer",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	token := func(userId uuid.UUID, userType domain.UserType) string {
+		token, err := s.tokensManager.GenerateJWT(userId.String(), userType.String())
+		r.NoError(err)
+
+		return token
+	}
+
+	creator := token(uuid.New(), domain.UserTypeClient)
+	client := token(uuid.New(), domain.UserTypeClient)
+	moderator := token(uuid.New(), domain.UserTypeModerator)
+
+	b, _ := json.Marshal(dtos.FlatCreateInput{FlatNumber: 416, HouseId: created.ID, Price: 1000, Rooms: 2})
+
+	req, _ := http.NewRequest("POST", "/api/flat/create", bytes.NewBuffer(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+creator)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	r.Equal(http.StatusCreated, resp.Code)
+
+	var createdFlat struct {
+		Data domain.Flat `json:"data"`
+	}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &createdFlat))
+
+	get := func(token string) (int, domain.FlatDetails) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/flat/%d", createdFlat.Data.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var body struct {
+			Data domain.FlatDetails `json:"data"`
+		}
+		_ = json.Unmarshal(resp.Body.Bytes(), &body)
+
+		return resp.Code, body.Data
+	}
+
+	// Flat that isn't approved yet is seen by its creator and moderators only.
+	code, flat := get(creator)
+	r.Equal(http.StatusOK, code)
+	r.Equal(416, flat.FlatNumber)
+	r.Equal(domain.StatusCreated, flat.Status)
+	r.Equal(domain.HouseSummary{ID: created.ID, Address: created.Address, Year: created.Year, Developer: created.Developer}, flat.House)
+
+	code, _ = get(moderator)
+	r.Equal(http.StatusOK, code)
+
+	code, _ = get(client)
+	r.Equal(http.StatusNotFound, code)
+
+	_, err = s.moderate(ctx, createdFlat.Data.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	code, flat = get(client)
+	r.Equal(http.StatusOK, code)
+	r.Equal(domain.StatusApproved, flat.Status)
+}
+
+func (s *APITestSuite) TestFlatsEdit() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "flat edit test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	token := func(userType domain.UserType) string {
+		token, err := s.tokensManager.GenerateJWT(uuid.NewString(), userType.String())
+		r.NoError(err)
+
+		return token
+	}
+
+	creator := token(domain.UserTypeClient)
+	client := token(domain.UserTypeClient)
+
+	do := func(method, url, token string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	resp := do("POST", "/api/flat/create", creator, dtos.FlatCreateInput{FlatNumber: 417, HouseId: created.ID, Price: 1000, Rooms: 2})
+	r.Equal(http.StatusCreated, resp.Code)
+
+	var createdFlat struct {
+		Data domain.Flat `json:"data"`
+	}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &createdFlat))
+
+	flatUrl := fmt.Sprintf("/api/flat/%d", createdFlat.Data.ID)
+
+	_, err = s.moderate(ctx, createdFlat.Data.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	price := 2000
+
+	// Flat is edited by its creator only.
+	resp = do("PATCH", flatUrl, client, dtos.FlatEditInput{Price: &price})
+	r.Equal(http.StatusForbidden, resp.Code)
+
+	resp = do("PATCH", flatUrl, creator, dtos.FlatEditInput{Price: &price})
+	r.Equal(http.StatusOK, resp.Code)
+
+	clientView := func() (domain.FlatDetails, []domain.Flat) {
+		var flat struct {
+			Data domain.FlatDetails `json:"data"`
+		}
+		resp := do("GET", flatUrl, client, nil)
+		r.Equal(http.StatusOK, resp.Code)
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &flat))
+
+		var house struct {
+			Data []domain.Flat `json:"data"`
+		}
+		resp = do("GET", fmt.Sprintf("/api/house/%d", created.ID), client, nil)
+		r.Equal(http.StatusOK, resp.Code)
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &house))
+
+		return flat.Data, house.Data
+	}
+
+	// Clients see approved version until edit is approved, while creator sees the edit.
+	flat, houseFlats := clientView()
+	r.Equal(1000, flat.Price)
+	r.Equal(domain.StatusApproved, flat.Status)
+	r.Nil(flat.Approved)
+	r.Len(houseFlats, 1)
+	r.Equal(1000, houseFlats[0].Price)
+
+	var edited struct {
+		Data domain.FlatDetails `json:"data"`
+	}
+	resp = do("GET", flatUrl, creator, nil)
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &edited))
+	r.Equal(2000, edited.Data.Price)
+	r.Equal(domain.StatusCreated, edited.Data.Status)
+	r.Equal(&domain.FlatVersion{FlatNumber: 417, Price: 1000, Rooms: 2}, edited.Data.Approved)
+
+	// Declined edit leaves approved version visible, creator sees why it was declined.
+	declinedBy := uuid.New()
+	_, err = s.services.Moderation.Claim(ctx, createdFlat.Data.ID, declinedBy)
+	r.NoError(err)
+
+	_, err = s.services.Flats.Update(ctx, declinedBy, dtos.FlatUpdateInput{
+		FlatId:  createdFlat.Data.ID,
+		Status:  domain.StatusDeclined,
+		Reason:  "wrong_price",
+		Comment: "price doesn't match the listing",
+	})
+	r.NoError(err)
+
+	flat, _ = clientView()
+	r.Equal(1000, flat.Price)
+
+	resp = do("GET", flatUrl, creator, nil)
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &edited))
+	r.Equal(domain.StatusDeclined, edited.Data.Status)
+	r.NotNil(edited.Data.Decline)
+	r.Equal("wrong_price", edited.Data.Decline.Reason.Code)
+	r.NotEmpty(edited.Data.Decline.Reason.Checklist)
+	r.Equal("price doesn't match the listing", edited.Data.Decline.Comment)
+
+	// Declined flat is resubmitted by editing it.
+	resp = do("PATCH", flatUrl, creator, dtos.FlatEditInput{Price: &price})
+	r.Equal(http.StatusOK, resp.Code)
+
+	_, err = s.moderate(ctx, createdFlat.Data.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	flat, houseFlats = clientView()
+	r.Equal(2000, flat.Price)
+	r.Equal(2000, houseFlats[0].Price)
+}
+
+func (s *APITestSuite) TestFlatsEditClaimed() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "claimed flat edit test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	creatorId := uuid.New()
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 425, Price: 1000, Rooms: 2, Status: domain.StatusCreated, CreatedBy: &creatorId})
+	r.NoError(err)
+
+	creator, err := s.tokensManager.GenerateJWT(creatorId.String(), domain.UserTypeClient.String())
+	r.NoError(err)
+
+	edit := func(price int) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(dtos.FlatEditInput{Price: &price})
+
+		req, _ := http.NewRequest("PATCH", fmt.Sprintf("/api/flat/%d", flat.ID), bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+creator)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	moderatorId := uuid.New()
+	_, err = s.services.Moderation.Claim(ctx, flat.ID, moderatorId)
+	r.NoError(err)
+
+	// Flat isn't changed under moderator deciding on it.
+	resp := edit(2000)
+	r.Equal(http.StatusConflict, resp.Code)
+	r.JSONEq(`{"message":"flat is being moderated"}`, resp.Body.String())
+
+	details, err := s.repos.Flats.GetById(ctx, flat.ID)
+	r.NoError(err)
+	r.Equal(1000, details.Price)
+
+	history, err := s.repos.Flats.GetHistory(ctx, flat.ID)
+	r.NoError(err)
+	r.Len(history, 1)
+
+	// Moderator holding the claim still decides on the flat as it was claimed.
+	_, err = s.services.Flats.Update(ctx, moderatorId, dtos.FlatUpdateInput{FlatId: flat.ID, Status: domain.StatusApproved})
+	r.NoError(err)
+
+	// Decided flat is edited again.
+	resp = edit(2000)
+	r.Equal(http.StatusOK, resp.Code)
+}
+
+func (s *APITestSuite) TestUserFlats() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "user flats test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	token := func(userType domain.UserType) string {
+		token, err := s.tokensManager.GenerateJWT(uuid.NewString(), userType.String())
+		r.NoError(err)
+
+		return token
+	}
+
+	creator := token(domain.UserTypeClient)
+	client := token(domain.UserTypeClient)
+
+	get := func(url, token string, data any) {
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(http.StatusOK, resp.Code)
+
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &struct {
+			Data any `json:"data"`
+		}{Data: data}))
+	}
+
+	var flatIds []int
+	for _, number := range []int{418, 419} {
+		b, _ := json.Marshal(dtos.FlatCreateInput{FlatNumber: number, HouseId: created.ID, Price: 1000, Rooms: 2})
+
+		req, _ := http.NewRequest("POST", "/api/flat/create", bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+creator)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		r.Equal(http.StatusCreated, resp.Code)
+
+		var flat struct {
+			Data domain.Flat `json:"data"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &flat))
+
+		flatIds = append(flatIds, flat.Data.ID)
+	}
+
+	_, err = s.moderate(ctx, flatIds[0], domain.StatusApproved)
+	r.NoError(err)
+
+	// Creator lists own flats in any status.
+	var own []domain.FlatDetails
+	get("/api/user/flats", creator, &own)
+	r.Len(own, 2)
+	r.Equal(419, own[0].FlatNumber)
+	r.Equal(domain.StatusCreated, own[0].Status)
+	r.Equal(created.ID, own[0].House.ID)
+	r.Equal(domain.StatusApproved, own[1].Status)
+
+	var none []domain.FlatDetails
+	get("/api/user/flats", client, &none)
+	r.Empty(none)
+
+	// House shows creator its pending flat, while other clients see approved one only.
+	var houseFlats []domain.Flat
+	get(fmt.Sprintf("/api/house/%d", created.ID), creator, &houseFlats)
+	r.Len(houseFlats, 2)
+
+	houseFlats = nil
+	get(fmt.Sprintf("/api/house/%d", created.ID), client, &houseFlats)
+	r.Len(houseFlats, 1)
+	r.Equal(418, houseFlats[0].FlatNumber)
+}
+
 //func (s *APITestSuite) TestFlatsUpdate() {
 //	gin.SetMode(gin.TestMode)
 //
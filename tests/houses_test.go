@@ -9,9 +9,12 @@ import (
 	v1 "github.com/dzhordano/avito-bootcamp2024/internal/delivery/http/v1"
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
 	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
 	"net/http/httptest"
+	"time"
 )
 
 func (s *APITestSuite) TestHousesCreateSuccess() {
@@ -29,7 +32,7 @@ func (s *APITestSuite) TestHousesCreateSuccess() {
 
 	b, _ := json.Marshal(input)
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeModerator.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeModerator.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("POST", "/api/house/create", bytes.NewBuffer(b))
@@ -67,7 +70,7 @@ func (s *APITestSuite) TestHousesCreateUnauthorized() {
 
 	b, _ := json.Marshal(input)
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeClient.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeClient.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("POST", "/api/house/create", bytes.NewBuffer(b))
@@ -88,7 +91,7 @@ func (s *APITestSuite) TestHousesGetFlatsById() {
 	s.handler.Init(router.Group("/api"))
 	r := s.Require()
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeClient.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeClient.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/api/house/%d", 1), nil)
@@ -126,7 +129,7 @@ func (s *APITestSuite) TestHousesSubscribeSuccess() {
 
 	b, _ := json.Marshal(input)
 
-	token, err := s.tokensManager.GenerateJWT(domain.UserTypeClient.String())
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeClient.String())
 	s.NoError(err)
 
 	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/house/%d/subscribe", 1), bytes.NewBuffer(b))
@@ -152,3 +155,163 @@ func (s *APITestSuite) TestHousesSubscribeSuccess() {
 	r.Equal(http.StatusOK, resp.Result().StatusCode)
 	r.Equal(input.Email, email)
 }
+
+func (s *APITestSuite) TestHousesGetFlatsByIdConditional() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+
+	token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeModerator.String())
+	s.NoError(err)
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/house/%d", 1), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	resp := get("")
+	r.Equal(http.StatusOK, resp.Code)
+
+	etag := resp.Header().Get("ETag")
+	r.NotEmpty(etag)
+	r.NotEmpty(resp.Header().Get("Last-Modified"))
+
+	resp = get(etag)
+	r.Equal(http.StatusNotModified, resp.Code)
+	r.Empty(resp.Body.Bytes())
+
+	// Flat status change must bump house modification time.
+	moderatorId := uuid.New()
+	_, err = s.repos.Moderation.Claim(context.Background(), 2, moderatorId, time.Minute)
+	s.NoError(err)
+
+	_, err = s.repos.Flats.Update(context.Background(), 2, moderatorId, domain.StatusDeclined.String(), nil)
+	s.NoError(err)
+
+	resp = get(etag)
+	r.Equal(http.StatusOK, resp.Code)
+	r.NotEqual(etag, resp.Header().Get("ETag"))
+}
+
+func (s *APITestSuite) TestHousesSubscriptionManagement() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+
+	created, err := s.repos.Houses.Create(context.Background(), domain.House{
+		Address:   "subscriptions test address",
+		Year:      2010,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	s.NoError(err)
+
+	token, err := s.tokensManager.GenerateJWT(userModerator.ID.String(), domain.UserTypeModerator.String())
+	s.NoError(err)
+
+	do := func(method, url string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			s.NoError(json.NewEncoder(&buf).Encode(body))
+		}
+
+		req, _ := http.NewRequest(method, url, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	minRooms, maxPrice := 4, 30000
+	url := fmt.Sprintf("/api/house/%d/subscribe", created.ID)
+
+	resp := do("POST", url, dtos.HouseSubscribeInput{Email: userModerator.Email, MinRooms: &minRooms, MaxPrice: &maxPrice})
+	r.Equal(http.StatusOK, resp.Result().StatusCode)
+
+	// Only flats matching filters are notified about.
+	emails, err := s.repos.Houses.GetHouseSubscribers(context.Background(), created.ID, domain.Flat{Rooms: 4, Price: 20000})
+	s.NoError(err)
+	r.Equal([]string{userModerator.Email}, emails)
+
+	emails, err = s.repos.Houses.GetHouseSubscribers(context.Background(), created.ID, domain.Flat{Rooms: 3, Price: 20000})
+	s.NoError(err)
+	r.Empty(emails)
+
+	emails, err = s.repos.Houses.GetHouseSubscribers(context.Background(), created.ID, domain.Flat{Rooms: 5, Price: 40000})
+	s.NoError(err)
+	r.Empty(emails)
+
+	resp = do("GET", "/api/user/subscriptions", nil)
+	r.Equal(http.StatusOK, resp.Result().StatusCode)
+
+	var respBody v1.DataResponse[[]domain.Subscription]
+	s.NoError(json.Unmarshal(resp.Body.Bytes(), &respBody))
+
+	var found *domain.Subscription
+	for i := range respBody.Data {
+		if respBody.Data[i].HouseID == created.ID {
+			found = &respBody.Data[i]
+		}
+	}
+	r.NotNil(found)
+	r.Equal(&minRooms, found.MinRooms)
+	r.Nil(found.MaxRooms)
+	r.Equal(&maxPrice, found.MaxPrice)
+
+	// Subscriptions of other users can't be cancelled.
+	resp = do("DELETE", url, dtos.HouseUnsubscribeInput{Email: "someone.else@mail.ru"})
+	r.Equal(http.StatusForbidden, resp.Result().StatusCode)
+
+	resp = do("DELETE", url, dtos.HouseUnsubscribeInput{Email: userModerator.Email})
+	r.Equal(http.StatusOK, resp.Result().StatusCode)
+
+	resp = do("DELETE", url, dtos.HouseUnsubscribeInput{Email: userModerator.Email})
+	r.Equal(http.StatusNotFound, resp.Result().StatusCode)
+}
+
+func (s *APITestSuite) TestHousesCacheFollowsModification() {
+	r := s.Require()
+	ctx := context.Background()
+
+	cached := s.repos.WithHousesCache(repository.NewHousesCache(10, time.Hour))
+
+	created, err := cached.Houses.Create(ctx, domain.House{
+		Address:   "cache test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	_, err = s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 426, Price: 5000, Rooms: 1, Status: domain.StatusApproved})
+	r.NoError(err)
+
+	for range 2 {
+		flats, err := cached.Houses.GetById(ctx, created.ID)
+		r.NoError(err)
+		r.Len(flats, 1)
+	}
+
+	// Write bypassing the cache, as import CLI does, bumps house modification time and cached list isn't served.
+	_, err = s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 427, Price: 5000, Rooms: 1, Status: domain.StatusApproved})
+	r.NoError(err)
+
+	flats, err := cached.Houses.GetById(ctx, created.ID)
+	r.NoError(err)
+	r.Len(flats, 2)
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/workerpool"
+	"strings"
+	"time"
+)
+
+func (s *APITestSuite) TestImportFlatsTransactionRollsBackOnInvalidRow() {
+	r := s.Require()
+
+	file := "flat_number,house_id,price,rooms\n" +
+		"301,1,1000,2\n" +
+		"302,1,,2\n" // price is required
+
+	job, err := s.services.Imports.Run(context.Background(), dtos.ImportInput{
+		Entity: domain.ImportEntityFlats,
+		Format: domain.ImportFormatCSV,
+	}, strings.NewReader(file))
+	r.NoError(err)
+
+	r.Equal(domain.ImportStatusCompleted, job.Status)
+	r.Equal(2, job.Total)
+	r.Equal(0, job.Succeeded)
+	r.Equal(1, job.Failed)
+	r.Len(job.Errors, 1)
+	r.Equal(2, job.Errors[0].Row)
+
+	r.Zero(s.countFlats(301, 302))
+}
+
+func (s *APITestSuite) TestImportFlatsChunksSkipsFailedRows() {
+	r := s.Require()
+
+	file := `[
+		{"flat_number": 311, "house_id": 1, "price": 1000, "rooms": 2},
+		{"flat_number": 312, "house_id": 100500, "price": 1000, "rooms": 2},
+		{"flat_number": 313, "house_id": 1, "price": 1000, "rooms": 2}
+	]`
+
+	job, err := s.services.Imports.Run(context.Background(), dtos.ImportInput{
+		Entity:    domain.ImportEntityFlats,
+		Format:    domain.ImportFormatJSON,
+		Mode:      domain.ImportModeChunks,
+		ChunkSize: 2,
+	}, strings.NewReader(file))
+	r.NoError(err)
+
+	r.Equal(2, job.Succeeded)
+	r.Equal(1, job.Failed)
+	r.Equal(domain.ImportRowError{Row: 2, Error: domain.ErrHouseNotFound.Error()}, job.Errors[0])
+
+	r.Equal(2, s.countFlats(311, 312, 313))
+}
+
+func (s *APITestSuite) TestImportFlatsDryRun() {
+	r := s.Require()
+
+	file := "flat_number,house_id,price,rooms\n321,1,1000,2\n"
+
+	job, err := s.services.Imports.Run(context.Background(), dtos.ImportInput{
+		Entity: domain.ImportEntityFlats,
+		Format: domain.ImportFormatCSV,
+		DryRun: true,
+	}, strings.NewReader(file))
+	r.NoError(err)
+
+	r.Equal(1, job.Succeeded)
+	r.Zero(s.countFlats(321))
+}
+
+func (s *APITestSuite) TestImportJobsExpire() {
+	r := s.Require()
+	ctx := context.Background()
+
+	imports := service.NewImportsService(s.repos.Flats, s.repos.Houses, workerpool.New(1, 0), 50*time.Millisecond, logger.NewLogger("debug"))
+
+	file := "flat_number,house_id,price,rooms\n331,1,1000,2\n"
+	inp := dtos.ImportInput{Entity: domain.ImportEntityFlats, Format: domain.ImportFormatCSV, DryRun: true}
+
+	first, err := imports.Run(ctx, inp, strings.NewReader(file))
+	r.NoError(err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Finished job is evicted once its TTL passes and next job starts.
+	_, err = imports.GetJob(first.ID)
+	r.ErrorIs(err, domain.ErrImportJobNotFound)
+
+	second, err := imports.Run(ctx, inp, strings.NewReader(file))
+	r.NoError(err)
+	r.Equal(domain.ImportStatusCompleted, second.Status)
+
+	// Background job options are validated as well.
+	_, err = imports.Start(ctx, dtos.ImportInput{Entity: domain.ImportEntityFlats, Format: "xml"}, strings.NewReader(file))
+	r.Error(err)
+}
+
+func (s *APITestSuite) countFlats(numbers ...int) int {
+	query, args, err := squirrel.
+		Select("count(*)").
+		From("flats").
+		Where(squirrel.Eq{"flat_number": numbers}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+	s.Require().NoError(err)
+
+	var count int
+	s.Require().NoError(s.db.QueryRow(context.Background(), query, args...).Scan(&count))
+
+	return count
+}
@@ -0,0 +1,366 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+func (s *APITestSuite) TestModerationClaims() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "moderation test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	var flatIds []int
+	for _, number := range []int{420, 421} {
+		flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: number, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+		r.NoError(err)
+
+		flatIds = append(flatIds, flat.ID)
+	}
+
+	token := func() string {
+		token, err := s.tokensManager.GenerateJWT(uuid.NewString(), domain.UserTypeModerator.String())
+		r.NoError(err)
+
+		return token
+	}
+
+	first, second := token(), token()
+
+	do := func(method, url, token string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	queue := func(token string) []domain.QueuedFlat {
+		resp := do("GET", fmt.Sprintf("/api/moderation/queue?house_id=%d", created.ID), token, nil)
+		r.Equal(http.StatusOK, resp.Code)
+
+		var body struct {
+			Data []domain.QueuedFlat `json:"data"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &body))
+
+		return body.Data
+	}
+
+	// Queue lists flats oldest first.
+	flats := queue(first)
+	r.Len(flats, 2)
+	r.Equal(flatIds[0], flats[0].ID)
+	r.Equal(flatIds[1], flats[1].ID)
+
+	claimUrl := fmt.Sprintf("/api/moderation/%d/claim", flatIds[0])
+
+	resp := do("POST", claimUrl, first, nil)
+	r.Equal(http.StatusOK, resp.Code)
+
+	// Claimed flat is hidden from other moderators, who can neither claim nor update it.
+	flats = queue(second)
+	r.Len(flats, 1)
+	r.Equal(flatIds[1], flats[0].ID)
+
+	flats = queue(first)
+	r.Len(flats, 2)
+	r.NotNil(flats[0].ClaimedBy)
+
+	resp = do("POST", claimUrl, second, nil)
+	r.Equal(http.StatusConflict, resp.Code)
+
+	update := dtos.FlatUpdateInput{FlatId: flatIds[0], Status: domain.StatusApproved}
+
+	resp = do("POST", "/api/flat/update", second, update)
+	r.Equal(http.StatusConflict, resp.Code)
+
+	resp = do("POST", "/api/flat/update", first, update)
+	r.Equal(http.StatusOK, resp.Code)
+
+	resp = do("POST", claimUrl, first, nil)
+	r.Equal(http.StatusConflict, resp.Code)
+
+	// Expired claim is released and flat returns to the queue.
+	resp = do("POST", fmt.Sprintf("/api/moderation/%d/claim", flatIds[1]), first, nil)
+	r.Equal(http.StatusOK, resp.Code)
+
+	_, err = s.db.Exec(ctx, "UPDATE flats SET claim_expires_at = now() - interval '1 second' WHERE id = $1", flatIds[1])
+	r.NoError(err)
+
+	n, err := s.services.Moderation.ReleaseExpired(ctx)
+	r.NoError(err)
+	r.GreaterOrEqual(n, int64(1))
+
+	flats = queue(second)
+	r.Len(flats, 1)
+	r.Nil(flats[0].ClaimedBy)
+
+	resp = do("POST", fmt.Sprintf("/api/moderation/%d/claim", flatIds[1]), second, nil)
+	r.Equal(http.StatusOK, resp.Code)
+}
+
+func (s *APITestSuite) TestFlatTransitions() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "transitions test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 422, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	moderatorId := uuid.New()
+	moderator, err := s.tokensManager.GenerateJWT(moderatorId.String(), domain.UserTypeModerator.String())
+	r.NoError(err)
+
+	do := func(method, url string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+moderator)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	transitions := func() domain.FlatTransitions {
+		resp := do("GET", fmt.Sprintf("/api/flat/%d/transitions", flat.ID), nil)
+		r.Equal(http.StatusOK, resp.Code)
+
+		var body struct {
+			Data domain.FlatTransitions `json:"data"`
+		}
+		r.NoError(json.Unmarshal(resp.Body.Bytes(), &body))
+
+		return body.Data
+	}
+
+	r.Equal([]domain.Status{domain.StatusApproved, domain.StatusDeclined, domain.StatusCreated}, transitions().Next)
+
+	_, err = s.services.Moderation.Claim(ctx, flat.ID, moderatorId)
+	r.NoError(err)
+
+	// Moderator decides on flat, it can't be sent back to created.
+	resp := do("POST", "/api/flat/update", dtos.FlatUpdateInput{FlatId: flat.ID, Status: domain.StatusCreated})
+	r.Equal(http.StatusConflict, resp.Code)
+	r.JSONEq(`{"message":"invalid transition"}`, resp.Body.String())
+
+	resp = do("POST", "/api/flat/update", dtos.FlatUpdateInput{FlatId: flat.ID, Status: domain.StatusApproved})
+	r.Equal(http.StatusOK, resp.Code)
+
+	r.Equal([]domain.Status{domain.StatusCreated}, transitions().Next)
+
+	// Approved flat isn't moderated again until it's edited.
+	resp = do("POST", "/api/flat/update", dtos.FlatUpdateInput{FlatId: flat.ID, Status: domain.StatusDeclined, Reason: "other", Comment: "declined"})
+	r.Equal(http.StatusConflict, resp.Code)
+	r.JSONEq(`{"message":"invalid transition"}`, resp.Body.String())
+}
+
+func (s *APITestSuite) TestFlatHistory() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "history test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	creatorId := uuid.New()
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{
+		FlatNumber: 423,
+		Price:      5000,
+		Rooms:      1,
+		Status:     domain.StatusCreated,
+		CreatedBy:  &creatorId,
+	})
+	r.NoError(err)
+
+	token := func(userId uuid.UUID, userType domain.UserType) string {
+		token, err := s.tokensManager.GenerateJWT(userId.String(), userType.String())
+		r.NoError(err)
+
+		return token
+	}
+
+	moderatorId := uuid.New()
+	moderator := token(moderatorId, domain.UserTypeModerator)
+
+	do := func(method, url, token string, body any) *httptest.ResponseRecorder {
+		b, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest(method, url, bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	historyUrl := fmt.Sprintf("/api/flat/%d/history", flat.ID)
+
+	_, err = s.services.Moderation.Claim(ctx, flat.ID, moderatorId)
+	r.NoError(err)
+
+	resp := do("POST", "/api/flat/update", moderator, dtos.FlatUpdateInput{
+		FlatId:  flat.ID,
+		Status:  domain.StatusDeclined,
+		Reason:  "wrong_rooms",
+		Comment: "kitchen is counted as a room",
+	})
+	r.Equal(http.StatusOK, resp.Code)
+
+	resp = do("GET", historyUrl, moderator, nil)
+	r.Equal(http.StatusOK, resp.Code)
+
+	var history struct {
+		Data []domain.FlatStatusChange `json:"data"`
+	}
+	r.NoError(json.Unmarshal(resp.Body.Bytes(), &history))
+	r.Len(history.Data, 2)
+
+	r.Equal(domain.Status(""), history.Data[0].OldStatus)
+	r.Equal(domain.StatusCreated, history.Data[0].NewStatus)
+	r.Equal(&creatorId, history.Data[0].Actor)
+
+	r.Equal(domain.StatusCreated, history.Data[1].OldStatus)
+	r.Equal(domain.StatusDeclined, history.Data[1].NewStatus)
+	r.Equal(&moderatorId, history.Data[1].Actor)
+	r.Equal("wrong_rooms", history.Data[1].Reason)
+
+	// History is append-only.
+	_, err = s.db.Exec(ctx, "DELETE FROM flat_status_history WHERE flat_id = $1", flat.ID)
+	r.Error(err)
+
+	// Clients, including flat creator, don't see history.
+	resp = do("GET", historyUrl, token(creatorId, domain.UserTypeClient), nil)
+	r.Equal(http.StatusUnauthorized, resp.Code)
+
+	resp = do("GET", "/api/flat/100000/history", moderator, nil)
+	r.Equal(http.StatusNotFound, resp.Code)
+
+	// History goes away along with the flat when its house is deleted.
+	_, err = s.db.Exec(ctx, "DELETE FROM houses WHERE id = $1", created.ID)
+	r.NoError(err)
+
+	_, err = s.repos.Flats.GetHistory(ctx, flat.ID)
+	r.ErrorIs(err, repository.ErrFlatNotFound)
+}
+
+func (s *APITestSuite) TestModerationLock() {
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "moderation lock test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 424, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	moderatorId := uuid.New()
+	_, err = s.services.Moderation.Claim(ctx, flat.ID, moderatorId)
+	r.NoError(err)
+
+	// concurrently runs fn n times at once and returns errors of the calls.
+	concurrently := func(n int, fn func() error) []error {
+		var (
+			wg    sync.WaitGroup
+			start = make(chan struct{})
+			errs  = make([]error, n)
+		)
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				errs[i] = fn()
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		return errs
+	}
+
+	const n = 8
+
+	// Of moderator's concurrent updates only one is applied, the others find flat moderated.
+	errs := concurrently(n, func() error {
+		_, err := s.services.Flats.Update(ctx, moderatorId, dtos.FlatUpdateInput{FlatId: flat.ID, Status: domain.StatusApproved})
+		return err
+	})
+
+	var updated int
+	for _, err := range errs {
+		if err == nil {
+			updated++
+			continue
+		}
+
+		r.ErrorIs(err, domain.ErrInvalidTransition)
+	}
+	r.Equal(1, updated)
+
+	details, err := s.repos.Flats.GetById(ctx, flat.ID)
+	r.NoError(err)
+	r.Equal(domain.StatusApproved, details.Status)
+
+	history, err := s.repos.Flats.GetHistory(ctx, flat.ID)
+	r.NoError(err)
+	r.Len(history, 2)
+	r.Equal(domain.StatusCreated, history[1].OldStatus)
+	r.Equal(domain.StatusApproved, history[1].NewStatus)
+}
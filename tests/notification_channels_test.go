@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/google/uuid"
+	"time"
+)
+
+func (s *APITestSuite) TestNotificationChannelsRouting() {
+	r := s.Require()
+	ctx := context.Background()
+
+	user := domain.User{
+		ID:       uuid.New(),
+		Email:    "channels@mail.ru",
+		Password: "qwerty",
+		UserType: domain.UserTypeClient,
+		Language: domain.LanguageEn,
+	}
+	r.NoError(s.repos.Users.Create(ctx, user))
+
+	phone, token := "+79990000000", "device-token"
+	now := time.Now().UTC()
+	r.NoError(s.services.Users.SetNotificationSettings(ctx, user.ID, dtos.NotificationSettingsInput{
+		Phone:     &phone,
+		PushToken: &token,
+		Timezone:  "UTC",
+		QuietHours: &dtos.QuietHoursInput{
+			Start: now.Add(-time.Hour).Format(domain.QuietHoursLayout),
+			End:   now.Add(time.Hour).Format(domain.QuietHoursLayout),
+		},
+		Channels: map[domain.EventType][]domain.Channel{
+			domain.EventFlatApproved: {domain.ChannelSMS, domain.ChannelPush, domain.ChannelInApp},
+		},
+	}))
+
+	settings, err := s.services.Users.NotificationSettings(ctx, user.ID)
+	r.NoError(err)
+	r.Equal([]domain.Channel{domain.ChannelSMS, domain.ChannelPush, domain.ChannelInApp}, settings.Channels[domain.EventFlatApproved])
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "channels test address",
+		Year:      2019,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	r.NoError(s.repos.Houses.SubscribeUser(ctx, domain.Subscription{HouseID: created.ID, Email: user.Email}))
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 410, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	s.dispatchOutbox()
+
+	// In-app notification isn't intrusive and is stored right away, in user language.
+	inbox, err := s.services.Notifications.GetAll(ctx, user.ID, dtos.NotificationsInput{PageInput: dtos.PageInput{Limit: 10}})
+	r.NoError(err)
+	r.Len(inbox, 1)
+	r.Equal(fmt.Sprintf("New flat in house %d", created.ID), inbox[0].Title)
+
+	// SMS and push wait for the end of quiet hours.
+	for _, sms := range s.channels.SMS() {
+		r.NotEqual(phone, sms.To)
+	}
+
+	var delayed int
+	err = s.db.QueryRow(ctx,
+		"SELECT count(*) FROM outbox WHERE processed_at IS NULL AND user_id = $1 AND channel IN ('sms', 'push') AND available_at > now()",
+		user.ID,
+	).Scan(&delayed)
+	r.NoError(err)
+	r.Equal(2, delayed)
+
+	// Email wasn't chosen.
+	var emails int
+	err = s.db.QueryRow(ctx, "SELECT count(*) FROM outbox WHERE recipient = $1 AND channel = 'email'", user.Email).Scan(&emails)
+	r.NoError(err)
+	r.Zero(emails)
+}
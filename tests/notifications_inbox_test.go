@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/google/uuid"
+	"time"
+)
+
+func (s *APITestSuite) TestNotificationsInbox() {
+	r := s.Require()
+	ctx := context.Background()
+
+	user := domain.User{
+		ID:       uuid.New(),
+		Email:    "inbox@mail.ru",
+		Password: "qwerty",
+		UserType: domain.UserTypeClient,
+		Language: domain.LanguageRu,
+	}
+	r.NoError(s.repos.Users.Create(ctx, user))
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "inbox test address",
+		Year:      2020,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	r.NoError(s.repos.Houses.SubscribeUser(ctx, domain.Subscription{HouseID: created.ID, Email: user.Email}))
+
+	for _, number := range []int{411, 412} {
+		flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: number, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+		r.NoError(err)
+
+		_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+		r.NoError(err)
+	}
+
+	s.dispatchOutbox()
+
+	unread := dtos.NotificationsInput{PageInput: dtos.PageInput{Limit: 10}, Unread: true}
+
+	// Inbox keeps notifications even though user chose only email, most recent first.
+	inbox, err := s.services.Notifications.GetAll(ctx, user.ID, unread)
+	r.NoError(err)
+	r.Len(inbox, 2)
+	r.Contains(inbox[0].Body, "№412")
+	r.Contains(inbox[1].Body, "№411")
+	r.Nil(inbox[0].ReadAt)
+
+	r.NoError(s.services.Notifications.MarkRead(ctx, user.ID, inbox[1].ID))
+
+	inbox, err = s.services.Notifications.GetAll(ctx, user.ID, unread)
+	r.NoError(err)
+	r.Len(inbox, 1)
+	r.Contains(inbox[0].Body, "№412")
+
+	// Notification of another user can't be read.
+	r.ErrorIs(s.services.Notifications.MarkRead(ctx, userModerator.ID, inbox[0].ID), domain.ErrNotificationNotFound)
+
+	r.NoError(s.services.Notifications.MarkAllRead(ctx, user.ID))
+
+	inbox, err = s.services.Notifications.GetAll(ctx, user.ID, unread)
+	r.NoError(err)
+	r.Empty(inbox)
+
+	inbox, err = s.services.Notifications.GetAll(ctx, user.ID, dtos.NotificationsInput{PageInput: dtos.PageInput{Limit: 10}})
+	r.NoError(err)
+	r.Len(inbox, 2)
+	r.NotNil(inbox[0].ReadAt)
+}
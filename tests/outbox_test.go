@@ -0,0 +1,200 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/sender"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordingSender remembers sent messages instead of sending them.
+type recordingSender struct {
+	mu   sync.Mutex
+	sent map[string][]sender.Email
+}
+
+func newRecordingSender() *recordingSender {
+	return &recordingSender{sent: make(map[string][]sender.Email)}
+}
+
+func (s *recordingSender) SendEmail(ctx context.Context, email sender.Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent[email.To] = append(s.sent[email.To], email)
+
+	return nil
+}
+
+// texts returns texts of messages sent to recipient.
+func (s *recordingSender) texts(to string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var texts []string
+	for _, email := range s.sent[to] {
+		texts = append(texts, email.Text)
+	}
+
+	return texts
+}
+
+// errSendFailed is returned by failingSender.
+var errSendFailed = errors.New("internal error")
+
+// failingSender never sends messages.
+type failingSender struct{}
+
+func (failingSender) SendEmail(ctx context.Context, email sender.Email) error {
+	return errSendFailed
+}
+
+func (s *APITestSuite) TestOutboxNotifiesOnApprovalOnly() {
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "outbox test address",
+		Year:      2015,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	r.NoError(s.repos.Houses.SubscribeUser(ctx, domain.Subscription{HouseID: created.ID, Email: userModerator.Email}))
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 401, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+	r.Zero(s.countOutbox(userModerator.Email, flat.ID))
+
+	_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+	r.NoError(err)
+	r.Equal(1, s.countOutbox(userModerator.Email, flat.ID))
+
+	recorder := newRecordingSender()
+	outbox := service.NewOutboxService(s.repos.Outbox, s.repos.Users, s.repos.Notifications, s.senders(recorder), s.templates, s.services.Webhooks, s.pool, s.unsubscribe, "http://test", outboxConfig, logger.NewLogger("debug"))
+
+	dispatchAll := func() {
+		for {
+			n, err := outbox.Dispatch(ctx, 100)
+			r.NoError(err)
+
+			if n == 0 {
+				break
+			}
+		}
+	}
+
+	dispatchAll()
+
+	texts := recorder.texts(userModerator.Email)
+	r.Len(texts, 1)
+	r.True(strings.HasPrefix(texts[0],
+		fmt.Sprintf("Доступна новая квартира №401: комнат 1, цена 5000. Все квартиры дома: http://test/api/house/%d\n\nОтписаться: http://test/api/unsubscribe?token=", created.ID)))
+	r.Zero(s.countOutbox(userModerator.Email, flat.ID))
+
+	// Notifications are rendered in language chosen by recipient.
+	r.NoError(s.services.Users.SetLanguage(ctx, userModerator.ID, domain.LanguageEn))
+	defer func() {
+		r.NoError(s.services.Users.SetLanguage(ctx, userModerator.ID, domain.LanguageRu))
+	}()
+
+	flat, err = s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 403, Price: 7000, Rooms: 2, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	dispatchAll()
+
+	texts = recorder.texts(userModerator.Email)
+	r.Len(texts, 2)
+	r.True(strings.HasPrefix(texts[1],
+		fmt.Sprintf("New flat №403 is available: 2 rooms, price 7000. See all house flats at http://test/api/house/%d\n\nUnsubscribe: ", created.ID)))
+}
+
+// senders returns senders delivering emails through email and other channels through stub.
+func (s *APITestSuite) senders(email sender.Sender) service.Senders {
+	return service.Senders{
+		Email: email,
+		SMS:   s.channels,
+		Push:  s.channels,
+	}
+}
+
+// countOutbox returns number of pending outbox messages to recipient about flat.
+func (s *APITestSuite) countOutbox(recipient string, flatId int) int {
+	var n int
+	err := s.db.QueryRow(context.Background(),
+		"SELECT count(*) FROM outbox WHERE processed_at IS NULL AND recipient = $1 AND (payload->>'flat_id')::int = $2",
+		recipient, flatId,
+	).Scan(&n)
+	s.Require().NoError(err)
+
+	return n
+}
+
+func (s *APITestSuite) TestOutboxDeadLetterRedrive() {
+	r := s.Require()
+	ctx := context.Background()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "dead letters test address",
+		Year:      2016,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	r.NoError(s.repos.Houses.SubscribeUser(ctx, domain.Subscription{HouseID: created.ID, Email: userModerator.Email}))
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 402, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	log := logger.NewLogger("debug")
+
+	failing := service.NewOutboxService(s.repos.Outbox, s.repos.Users, s.repos.Notifications, s.senders(failingSender{}), s.templates, s.services.Webhooks, s.pool, s.unsubscribe, "http://test", outboxConfig, log)
+	// Message to subscriber is routed to email on the first dispatch, failed email is retried
+	// on the next ones until its attempts are exhausted.
+	for range outboxConfig.Retry.MaxAttempts + 1 {
+		_, err := failing.Dispatch(ctx, 100)
+		r.NoError(err)
+	}
+
+	// Message exhausted its retries and left outbox for dead letters.
+	r.Zero(s.countOutbox(userModerator.Email, flat.ID))
+
+	letters, err := failing.DeadLetters(ctx, dtos.PageInput{Limit: dtos.MaxPageLimit})
+	r.NoError(err)
+
+	var letter *domain.DeadLetter
+	for i := range letters {
+		var payload domain.FlatApprovedPayload
+		r.NoError(json.Unmarshal(letters[i].Payload, &payload))
+
+		if letters[i].Recipient == userModerator.Email && payload.FlatID == flat.ID {
+			letter = &letters[i]
+		}
+	}
+	r.NotNil(letter)
+	r.Equal(errSendFailed.Error(), letter.LastError)
+	r.Equal(outboxConfig.Retry.MaxAttempts, letter.Attempts)
+
+	_, err = failing.Redrive(ctx, letter.ID)
+	r.NoError(err)
+	r.Equal(1, s.countOutbox(userModerator.Email, flat.ID))
+
+	_, err = failing.Redrive(ctx, letter.ID)
+	r.ErrorIs(err, domain.ErrDeadLetterNotFound)
+}
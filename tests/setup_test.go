@@ -5,6 +5,8 @@ import (
 	"crypto/sha1"
 	"fmt"
 	v1 "github.com/dzhordano/avito-bootcamp2024/internal/delivery/http/v1"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
 	"github.com/dzhordano/avito-bootcamp2024/internal/repository"
 	"github.com/dzhordano/avito-bootcamp2024/internal/service"
 	"github.com/dzhordano/avito-bootcamp2024/pkg/auth"
@@ -12,15 +14,20 @@ import (
 	"github.com/dzhordano/avito-bootcamp2024/pkg/emails/validation"
 	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
 	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/sender"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/templates"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/notifications/unsubscribe"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/webhooks"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/workerpool"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/suite"
+	"io"
 	"log"
 	"os"
-	"sync"
 	"testing"
 	"time"
 )
@@ -28,6 +35,19 @@ import (
 var (
 	dbDSN    string
 	tokenTTL = 6 * time.Hour
+
+	webhookDisableAfter = 2
+
+	unsubscribeTTL = time.Hour
+
+	moderationLease = time.Minute
+
+	// Failed messages to users are retried on the next dispatch, ones to webhooks aren't retried during tests.
+	outboxConfig = service.OutboxConfig{
+		Lease:    time.Minute,
+		Retry:    service.RetryPolicy{MaxAttempts: 3},
+		Webhooks: service.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour},
+	}
 )
 
 func init() {
@@ -49,6 +69,10 @@ type APITestSuite struct {
 	emailValidations validation.EmailValidator
 	tokensManager    auth.TokensManager
 	notifications    sender.Sender
+	channels         *sender.Stub
+	templates        *templates.Renderer
+	unsubscribe      *unsubscribe.Signer
+	pool             *workerpool.Pool
 }
 
 func TestAPISuite(t *testing.T) {
@@ -81,16 +105,40 @@ func (s *APITestSuite) initDeps() {
 	repos := repository.New(s.db)
 	tokensManager := auth.NewJWTManager("secret", tokenTTL)
 	notifications := sender.New()
-	longTasks := &sync.WaitGroup{}
+	channels := sender.NewStub(io.Discard)
+	pool := workerpool.New(4, 16)
+	unsubscribeSigner := unsubscribe.NewSigner("secret", unsubscribeTTL)
 	emailsValidator := validation.NewEmailValidator()
 	inpLogger := logger.NewLogger("debug")
+	// Test endpoints listen on loopback.
+	webhooksClient := webhooks.NewClient(webhooks.Config{
+		Timeout:      time.Second,
+		AllowPrivate: true,
+	})
+
+	renderer, err := templates.New()
+	if err != nil {
+		panic(err)
+	}
 
 	services := service.New(service.Deps{
 		Repos:         repos,
 		TokensManager: tokensManager,
-		Notifications: notifications,
-		WaitGroup:     longTasks,
-		Logger:        inpLogger,
+		Notifications: service.Senders{
+			Email: notifications,
+			SMS:   channels,
+			Push:  channels,
+		},
+		Templates:           renderer,
+		Unsubscribe:         unsubscribeSigner,
+		Webhooks:            webhooksClient,
+		WebhookDisableAfter: webhookDisableAfter,
+		Outbox:              outboxConfig,
+		NotificationsPool:   pool,
+		ImportsPool:         pool,
+		ImportJobTTL:        time.Hour,
+		ModerationLease:     moderationLease,
+		Logger:              inpLogger,
 	})
 
 	m, err := migrate.New("file://../migrations", dbDSN)
@@ -106,6 +154,10 @@ func (s *APITestSuite) initDeps() {
 	s.emailValidations = emailsValidator
 	s.tokensManager = tokensManager
 	s.notifications = notifications
+	s.channels = channels
+	s.templates = renderer
+	s.unsubscribe = unsubscribeSigner
+	s.pool = pool
 	s.services = services
 	s.handler = v1.NewHandler(services, tokensManager)
 }
@@ -116,6 +168,17 @@ func (s *APITestSuite) TearDownSuite() {
 	}
 }
 
+// moderate claims flat for a new moderator and sets its status, as moderators do through API.
+func (s *APITestSuite) moderate(ctx context.Context, flatId int, status domain.Status) (domain.Flat, error) {
+	moderatorId := uuid.New()
+
+	if _, err := s.services.Moderation.Claim(ctx, flatId, moderatorId); err != nil {
+		return domain.Flat{}, err
+	}
+
+	return s.services.Flats.Update(ctx, moderatorId, dtos.FlatUpdateInput{FlatId: flatId, Status: status})
+}
+
 func (s *APITestSuite) seedDB() error {
 	if _, err := s.repos.Houses.Create(context.Background(), house); err != nil {
 		return err
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func (s *APITestSuite) TestUnsubscribeLink() {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	s.handler.Init(router.Group("/api"))
+	r := s.Require()
+	ctx := context.Background()
+
+	user := domain.User{
+		ID:       uuid.New(),
+		Email:    "unsubscribe@mail.ru",
+		Password: "qwerty",
+		UserType: domain.UserTypeClient,
+		Language: domain.LanguageEn,
+	}
+	r.NoError(s.repos.Users.Create(ctx, user))
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "unsubscribe test address",
+		Year:      2022,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	r.NoError(s.repos.Houses.SubscribeUser(ctx, domain.Subscription{HouseID: created.ID, Email: user.Email, Delivery: domain.DeliveryImmediate}))
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 415, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	recorder := newRecordingSender()
+	outbox := service.NewOutboxService(s.repos.Outbox, s.repos.Users, s.repos.Notifications, s.senders(recorder), s.templates, s.services.Webhooks, s.pool, s.unsubscribe, "http://test", outboxConfig, logger.NewLogger("debug"))
+
+	for {
+		n, err := outbox.Dispatch(ctx, 100)
+		r.NoError(err)
+
+		if n == 0 {
+			break
+		}
+	}
+
+	sent := recorder.sent[user.Email]
+	r.Len(sent, 1)
+	r.True(strings.HasPrefix(sent[0].Unsubscribe, "http://test/api/unsubscribe?token="))
+	r.Contains(sent[0].Text, "Unsubscribe: "+sent[0].Unsubscribe)
+
+	link := strings.TrimPrefix(sent[0].Unsubscribe, "http://test")
+
+	// Forged link doesn't unsubscribe.
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", link+"x", nil)
+	router.ServeHTTP(resp, req)
+
+	r.Equal(http.StatusBadRequest, resp.Code)
+
+	subs, err := s.services.Users.Subscriptions(ctx, user.ID)
+	r.NoError(err)
+	r.Len(subs, 1)
+
+	// Following the link only asks to confirm, so that mail scanners fetching it don't unsubscribe.
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", link, nil)
+	router.ServeHTTP(resp, req)
+
+	r.Equal(http.StatusOK, resp.Code)
+	r.Contains(resp.Body.String(), "Unsubscribe unsubscribe@mail.ru from notifications about house")
+	r.Contains(resp.Body.String(), `<form method="post"`)
+
+	subs, err = s.services.Users.Subscriptions(ctx, user.ID)
+	r.NoError(err)
+	r.Len(subs, 1)
+
+	// Mail client one-click unsubscribe and confirming later both succeed.
+	for range 2 {
+		resp = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", link, strings.NewReader("List-Unsubscribe=One-Click"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(resp, req)
+
+		r.Equal(http.StatusOK, resp.Code)
+		r.Contains(resp.Body.String(), "unsubscribe@mail.ru is unsubscribed")
+	}
+
+	subs, err = s.services.Users.Subscriptions(ctx, user.ID)
+	r.NoError(err)
+	r.Empty(subs)
+}
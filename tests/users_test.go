@@ -11,8 +11,10 @@ import (
 	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
 	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
 	"net/http/httptest"
+	"time"
 )
 
 func (s *APITestSuite) TestUsersDummyLoginClient() {
@@ -41,7 +43,7 @@ func (s *APITestSuite) TestUsersDummyLoginClient() {
 	utTokenResp, err := s.tokensManager.Parse(authToken["auth_token"])
 	s.NoError(err)
 
-	r.Equal(userType, utTokenResp)
+	r.Equal(userType, utTokenResp.UserType)
 
 }
 
@@ -167,3 +169,57 @@ func (s *APITestSuite) TestUsersLoginNotFound() {
 
 	r.Equal(http.StatusNotFound, resp.Result().StatusCode)
 }
+
+func (s *APITestSuite) TestUsersExport() {
+	r := s.Require()
+	ctx := context.Background()
+
+	user := domain.User{
+		ID:       uuid.New(),
+		Email:    "export@mail.ru",
+		Password: "qwerty",
+		UserType: domain.UserTypeClient,
+		Language: domain.LanguageEn,
+	}
+	r.NoError(s.repos.Users.Create(ctx, user))
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "export test address",
+		Year:      2024,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{
+		FlatNumber: 425,
+		Price:      5000,
+		Rooms:      1,
+		Status:     domain.StatusCreated,
+		CreatedBy:  &user.ID,
+	})
+	r.NoError(err)
+
+	_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	export, err := s.services.Users.Export(ctx, user.ID)
+	r.NoError(err)
+	r.Equal(user.Email, export.Email)
+	r.Equal(domain.LanguageEn, export.Language)
+	r.Empty(export.Subscriptions)
+
+	r.Len(export.Flats, 1)
+	r.Equal(flat.ID, export.Flats[0].ID)
+
+	// Export includes status history of user's flats, moderator's changes as well.
+	r.Len(export.StatusHistory, 3)
+	for _, rec := range export.StatusHistory {
+		r.Equal(flat.ID, rec.FlatID)
+	}
+	r.Equal(&user.ID, export.StatusHistory[0].Actor)
+	r.Equal(domain.StatusApproved, export.StatusHistory[2].NewStatus)
+
+	_, err = s.services.Users.Export(ctx, uuid.New())
+	r.ErrorIs(err, domain.ErrUserNotFound)
+}
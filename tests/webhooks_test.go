@@ -0,0 +1,210 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dzhordano/avito-bootcamp2024/internal/domain"
+	"github.com/dzhordano/avito-bootcamp2024/internal/dtos"
+	"github.com/dzhordano/avito-bootcamp2024/internal/service"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/logger"
+	"github.com/dzhordano/avito-bootcamp2024/pkg/webhooks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func (s *APITestSuite) TestWebhooksDelivery() {
+	r := s.Require()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		received = append(received, req)
+		bodies = append(bodies, body)
+	}))
+	defer srv.Close()
+
+	webhook, err := s.services.Webhooks.Create(ctx, dtos.WebhookCreateInput{
+		URL:        srv.URL,
+		EventTypes: []domain.EventType{domain.EventFlatApproved},
+	})
+	r.NoError(err)
+	defer func() {
+		r.NoError(s.services.Webhooks.Delete(ctx, webhook.ID))
+	}()
+	r.NotEmpty(webhook.Secret)
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "webhooks test address",
+		Year:      2017,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 404, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+	r.NoError(err)
+
+	_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+	r.NoError(err)
+
+	s.dispatchOutbox()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	r.Len(received, 1)
+	r.Equal(string(domain.EventFlatApproved), received[0].Header.Get(webhooks.HeaderEvent))
+
+	timestamp, err := strconv.ParseInt(received[0].Header.Get(webhooks.HeaderTimestamp), 10, 64)
+	r.NoError(err)
+	r.True(webhooks.Verify(webhook.Secret, received[0].Header.Get(webhooks.HeaderSignature), timestamp, bodies[0]))
+
+	var event domain.WebhookEvent
+	r.NoError(json.Unmarshal(bodies[0], &event))
+	r.Equal(domain.EventFlatApproved, event.Event)
+
+	var payload domain.FlatApprovedPayload
+	r.NoError(json.Unmarshal(event.Data, &payload))
+	r.Equal(flat.ID, payload.FlatID)
+	r.Equal(created.ID, payload.HouseID)
+
+	deliveries, err := s.services.Webhooks.Deliveries(ctx, webhook.ID, dtos.PageInput{Limit: dtos.DefaultPageLimit})
+	r.NoError(err)
+	r.Len(deliveries, 1)
+	r.True(deliveries[0].Success)
+	r.Equal(event.ID, deliveries[0].OutboxID)
+}
+
+func (s *APITestSuite) TestWebhooksDisabledAfterFailures() {
+	r := s.Require()
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	webhook, err := s.services.Webhooks.Create(ctx, dtos.WebhookCreateInput{
+		URL:        srv.URL,
+		EventTypes: []domain.EventType{domain.EventFlatApproved},
+	})
+	r.NoError(err)
+	defer func() {
+		r.NoError(s.services.Webhooks.Delete(ctx, webhook.ID))
+	}()
+
+	created, err := s.repos.Houses.Create(ctx, domain.House{
+		Address:   "failing webhooks test address",
+		Year:      2018,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	r.NoError(err)
+
+	// Each message is tried once and stays in outbox to be retried later.
+	for i := 0; i < webhookDisableAfter+1; i++ {
+		flat, err := s.repos.Flats.Create(ctx, created.ID, domain.Flat{FlatNumber: 405 + i, Price: 5000, Rooms: 1, Status: domain.StatusCreated})
+		r.NoError(err)
+
+		_, err = s.moderate(ctx, flat.ID, domain.StatusApproved)
+		r.NoError(err)
+
+		s.dispatchOutbox()
+	}
+
+	var pending int
+	err = s.db.QueryRow(ctx, "SELECT count(*) FROM outbox WHERE processed_at IS NULL AND channel = $1 AND recipient = $2",
+		domain.ChannelWebhook, strconv.FormatInt(webhook.ID, 10)).Scan(&pending)
+	r.NoError(err)
+	r.Equal(webhookDisableAfter, pending)
+
+	// The last message is dropped since webhook was already disabled.
+	deliveries, err := s.services.Webhooks.Deliveries(ctx, webhook.ID, dtos.PageInput{Limit: dtos.DefaultPageLimit})
+	r.NoError(err)
+	r.Len(deliveries, webhookDisableAfter)
+	for _, d := range deliveries {
+		r.False(d.Success)
+		r.Equal(1, d.Attempts)
+		r.NotNil(d.StatusCode)
+		r.Equal(http.StatusServiceUnavailable, *d.StatusCode)
+	}
+
+	all, err := s.services.Webhooks.GetAll(ctx)
+	r.NoError(err)
+	for _, w := range all {
+		if w.ID == webhook.ID {
+			r.False(w.Active)
+			r.NotNil(w.DisabledAt)
+		}
+	}
+
+	enabled, err := s.services.Webhooks.Enable(ctx, webhook.ID)
+	r.NoError(err)
+	r.True(enabled.Active)
+	r.Zero(enabled.ConsecutiveFailures)
+}
+
+func (s *APITestSuite) TestWebhooksPrivateAddress() {
+	r := s.Require()
+	ctx := context.Background()
+
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	hooks := service.NewWebhooksService(s.repos.Webhooks, webhooks.NewClient(webhooks.Config{Timeout: time.Second}), 10, logger.NewLogger("debug"))
+
+	// Webhooks can't be created for internal addresses.
+	for _, url := range []string{srv.URL, "http://localhost/hooks", "http://169.254.169.254/latest/meta-data", "http://10.0.0.1/hooks"} {
+		_, err := hooks.Create(ctx, dtos.WebhookCreateInput{URL: url, EventTypes: []domain.EventType{domain.EventFlatApproved}})
+		r.ErrorIs(err, domain.ErrWebhookURLForbidden, url)
+	}
+
+	// Host resolving to internal address by the time of delivery isn't posted to either.
+	webhook, err := s.repos.Webhooks.Create(ctx, domain.Webhook{
+		URL:        srv.URL,
+		EventTypes: []domain.EventType{domain.EventFlatApproved},
+		Secret:     "0123456789abcdef",
+	})
+	r.NoError(err)
+	defer func() {
+		r.NoError(s.repos.Webhooks.Delete(ctx, webhook.ID))
+	}()
+
+	err = hooks.Deliver(ctx, domain.OutboxMessage{
+		ID:        1,
+		EventType: domain.EventFlatApproved,
+		Channel:   domain.ChannelWebhook,
+		Recipient: strconv.FormatInt(webhook.ID, 10),
+		Payload:   json.RawMessage(`{}`),
+		Attempts:  1,
+	})
+	r.ErrorIs(err, domain.ErrUndeliverable)
+	r.ErrorIs(err, webhooks.ErrForbiddenAddress)
+	r.Zero(calls)
+}
+
+// dispatchOutbox sends all pending outbox messages.
+func (s *APITestSuite) dispatchOutbox() {
+	for {
+		n, err := s.services.Outbox.Dispatch(context.Background(), 100)
+		s.Require().NoError(err)
+
+		if n == 0 {
+			return
+		}
+	}
+}